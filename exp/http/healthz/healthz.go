@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/heatxsink/x/exp/http/responses"
+	"github.com/heatxsink/x/times"
 )
 
 // Healthz is a small JSON probe describing what is currently running.
@@ -15,6 +16,9 @@ type Healthz struct {
 	BuildDate string `json:"build_date"`
 	TimeSince string `json:"time_since"`
 	Hash      string `json:"commit_hash"`
+
+	// Clock measures TimeSince. Nil means the system clock.
+	Clock times.Clock `json:"-"`
 }
 
 // buildDateFormats lists the timestamp layouts the handler will accept
@@ -45,7 +49,11 @@ func (h *Healthz) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
 		Hash:      h.Hash,
 	}
 	if t, err := parseBuildDate(h.BuildDate); err == nil {
-		resp.TimeSince = time.Since(t).String()
+		clock := h.Clock
+		if clock == nil {
+			clock = times.RealClock()
+		}
+		resp.TimeSince = clock.Since(t).String()
 	}
 	responses.OK(w, resp)
 }
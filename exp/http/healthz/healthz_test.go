@@ -8,6 +8,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/heatxsink/x/times"
 )
 
 // envelope mirrors the shape responses.OK wraps around the payload.
@@ -51,6 +53,18 @@ func TestServeHTTP_PopulatesFields(t *testing.T) {
 	}
 }
 
+func TestServeHTTP_UsesClock(t *testing.T) {
+	clock := times.NewFakeClock(time.Date(2026, 5, 1, 13, 14, 33, 0, time.UTC))
+	h := &Healthz{BuildDate: "2026-05-01T11:14:33Z", Clock: clock}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	got := decodeBody(t, w)
+	if got.Data.TimeSince != "2h0m0s" {
+		t.Errorf("TimeSince = %q, want 2h0m0s", got.Data.TimeSince)
+	}
+}
+
 func TestServeHTTP_AcceptsRFC3339(t *testing.T) {
 	h := &Healthz{BuildDate: "2026-05-01T11:14:33Z"}
 	w := httptest.NewRecorder()
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/heatxsink/x/dotenv"
@@ -168,6 +170,31 @@ func (l *Loom) ServiceFile(execStart string) (string, error) {
 	return filename, nil
 }
 
+// ServiceFileChanges reports how the local serviceFile differs from the
+// unit installed on the remote, so a deploy can show what it would
+// change before Setup overwrites it. A missing remote unit counts as
+// empty, making every directive an addition.
+func (l *Loom) ServiceFileChanges(ctx context.Context, serviceFile string) ([]systemd.Change, error) {
+	local, err := systemd.ParseFile(serviceFile)
+	if err != nil {
+		return nil, err
+	}
+	client, err := l.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	installed, err := client.Capture(fmt.Sprintf("cat %s/%s 2>/dev/null || true",
+		l.systemdUnitDir(), filepath.Base(serviceFile)))
+	if err != nil {
+		return nil, err
+	}
+	remote, err := systemd.Parse(strings.NewReader(installed))
+	if err != nil {
+		return nil, fmt.Errorf("installed %s: %w", filepath.Base(serviceFile), err)
+	}
+	return systemd.Diff(remote, local), nil
+}
+
 func (l *Loom) UploadToDestination(ctx context.Context, filename string) error {
 	source := fmt.Sprintf("./%s", filename)
 	destination := fmt.Sprintf("%s/%s", l.destination, filename)
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/heatxsink/x/exp/storage"
+	"github.com/heatxsink/x/times"
 )
 
 var (
@@ -23,6 +24,18 @@ var (
 type Manifest struct {
 	start   time.Time
 	baseURI string
+	clock   times.Clock
+}
+
+// Option configures a Manifest.
+type Option func(*Manifest)
+
+// WithClock sets the clock used for Minor version numbers and Published
+// times. The default is the system clock.
+func WithClock(c times.Clock) Option {
+	return func(m *Manifest) {
+		m.clock = c
+	}
 }
 
 type Item struct {
@@ -53,19 +66,23 @@ func createHash() string {
 }
 
 func (m *Manifest) daysSince() int {
-	return int(time.Since(m.start).Hours()) / 24
+	return int(m.clock.Since(m.start).Hours()) / 24
 }
 
 // New returns a Manifest rooted at baseURI. baseURI must be a storage URI
 // that exp/storage understands (gs://bucket, file:///path, or mem://ns).
 // startDate must be a YYYY-MM-DD string; a parse failure returns an error
 // instead of silently producing garbage Minor version numbers.
-func New(baseURI string, startDate string) (*Manifest, error) {
+func New(baseURI string, startDate string, opts ...Option) (*Manifest, error) {
 	t, err := time.Parse("2006-01-02", startDate)
 	if err != nil {
 		return nil, fmt.Errorf("manifest: parse startDate %q: %w", startDate, err)
 	}
-	return &Manifest{start: t, baseURI: baseURI}, nil
+	m := &Manifest{start: t, baseURI: baseURI, clock: times.RealClock()}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m, nil
 }
 
 func joinURI(base, key string) string {
@@ -87,7 +104,7 @@ func (m *Manifest) Init(ctx context.Context) (*Item, []*Item, error) {
 		point = 1
 	}
 	manifest := &Item{
-		Published: time.Now(),
+		Published: m.clock.Now(),
 		Prefix:    createHash(),
 		Version: Version{
 			Major: 1,
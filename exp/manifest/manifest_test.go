@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/heatxsink/x/exp/storage"
+	"github.com/heatxsink/x/times"
 )
 
 func newTestManifest(t *testing.T) *Manifest {
@@ -167,3 +168,29 @@ func TestCreateHashUnique(t *testing.T) {
 		seen[h] = struct{}{}
 	}
 }
+
+func TestInitVersionFollowsClock(t *testing.T) {
+	clock := times.NewFakeClock(time.Date(2024, time.January, 11, 8, 0, 0, 0, time.UTC))
+	m, err := New("mem://"+t.Name(), "2024-01-01", WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := m.Save(ctx, []*Item{{Version: Version{1, 10, 1}, Prefix: "A"}}); err != nil {
+		t.Fatal(err)
+	}
+	item, _, err := m.Init(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Version != (Version{1, 10, 2}) || !item.Published.Equal(clock.Now()) {
+		t.Errorf("same day: Init = %v at %v", item.Version, item.Published)
+	}
+	clock.Advance(24 * time.Hour)
+	if item, _, err = m.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if item.Version != (Version{1, 11, 1}) {
+		t.Errorf("next day: Init = %v, want 1.11.1", item.Version)
+	}
+}
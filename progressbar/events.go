@@ -0,0 +1,75 @@
+package progressbar
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Event is a machine-readable progress update, emitted at most every
+// 100ms while progress changes and once more, with Done set, on Close.
+type Event struct {
+	Description string    `json:"description"`
+	Current     int64     `json:"current"`
+	Total       int64     `json:"total"`
+	Percent     float64   `json:"percent"`
+	Rate        float64   `json:"rate"`
+	ETA         float64   `json:"eta_seconds"`
+	Elapsed     float64   `json:"elapsed_seconds"`
+	Done        bool      `json:"done"`
+	Time        time.Time `json:"time"`
+}
+
+// WithEvents writes every Event to w as a line of JSON, for a wrapper
+// process to relay to a GUI or dashboard.
+func WithEvents(w io.Writer) Option {
+	return func(b *Bar) { b.events = w }
+}
+
+// WithEventFunc calls fn with every Event. fn runs on the goroutine that
+// updated the bar and must not call back into it.
+func WithEventFunc(fn func(Event)) Option {
+	return func(b *Bar) { b.eventFunc = fn }
+}
+
+// WithoutRender turns off terminal output, for bars that only emit events.
+func WithoutRender() Option {
+	return func(b *Bar) { b.noRender = true }
+}
+
+func (b *Bar) hasEvents() bool {
+	return b.events != nil || b.eventFunc != nil
+}
+
+func (b *Bar) emit(done bool) {
+	now := b.clock.Now()
+	st := b.snapshot(now)
+	e := Event{
+		Description: b.desc,
+		Current:     st.current,
+		Total:       st.total,
+		Percent:     st.pct * 100,
+		Elapsed:     st.elapsed.Seconds(),
+		Done:        done,
+		Time:        now.UTC(),
+	}
+	if st.hasRate {
+		e.Rate = st.rate
+	}
+	if st.hasETA && !done {
+		e.ETA = st.remaining.Seconds()
+	}
+
+	b.emitMu.Lock()
+	defer b.emitMu.Unlock()
+	if b.eventFunc != nil {
+		b.eventFunc(e)
+	}
+	if b.events != nil {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return
+		}
+		_, _ = b.events.Write(append(line, '\n'))
+	}
+}
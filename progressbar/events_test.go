@@ -0,0 +1,63 @@
+package progressbar
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestEventsJSONLines(t *testing.T) {
+	var events, screen bytes.Buffer
+	bar := DefaultBytes(100, "Upload", WithOutput(&screen), WithEvents(&events))
+	bar.startTime = time.Now().Add(-time.Second)
+	_, _ = bar.Write(make([]byte, 40))
+	_ = bar.Close()
+
+	var got []Event
+	scanner := bufio.NewScanner(&events)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("bad line %q: %v", scanner.Text(), err)
+		}
+		got = append(got, e)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d events, want 2: %s", len(got), events.String())
+	}
+	first, last := got[0], got[1]
+	if first.Current != 40 || first.Total != 100 || first.Percent != 40 || first.Done {
+		t.Errorf("first event = %+v", first)
+	}
+	if first.Rate <= 0 || first.ETA <= 0 {
+		t.Errorf("first event missing rate/ETA: %+v", first)
+	}
+	if !last.Done || last.Description != "Upload" {
+		t.Errorf("last event = %+v", last)
+	}
+	if screen.Len() == 0 {
+		t.Error("events should not suppress rendering by default")
+	}
+}
+
+func TestEventFuncWithoutRender(t *testing.T) {
+	var screen bytes.Buffer
+	var got []Event
+	bar := DefaultCount(-1, "Scan", WithOutput(&screen), WithoutRender(),
+		WithEventFunc(func(e Event) { got = append(got, e) }))
+	bar.Add(3)
+	bar.Add(1) // within 100ms of the previous event: not emitted
+	_ = bar.Close()
+
+	if screen.Len() != 0 {
+		t.Errorf("WithoutRender still drew: %q", screen.String())
+	}
+	if len(got) != 2 || got[0].Current != 3 || got[1].Current != 4 || !got[1].Done {
+		t.Errorf("events = %+v", got)
+	}
+	if got[0].Total != -1 || got[0].Percent != 0 {
+		t.Errorf("indeterminate event = %+v", got[0])
+	}
+}
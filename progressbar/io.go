@@ -0,0 +1,108 @@
+package progressbar
+
+import (
+	"context"
+	"io"
+)
+
+// Reader reports every byte read through it to a Bar. It implements
+// io.WriterTo, delegating to the underlying reader's WriteTo when it has
+// one, so io.Copy keeps its fast path.
+type Reader struct {
+	ctx context.Context
+	r   io.Reader
+	bar *Bar
+}
+
+// NewReader wraps r so reads advance bar.
+func NewReader(r io.Reader, bar *Bar) *Reader {
+	return NewReaderContext(context.Background(), r, bar)
+}
+
+// NewReaderContext is NewReader with reads failing with ctx.Err() once
+// ctx is done. A read that is already blocked is not interrupted.
+func NewReaderContext(ctx context.Context, r io.Reader, bar *Bar) *Reader {
+	return &Reader{ctx: ctx, r: r, bar: bar}
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.r.Read(p)
+	r.bar.Add(int64(n))
+	return n, err
+}
+
+// WriteTo implements io.WriterTo.
+func (r *Reader) WriteTo(w io.Writer) (int64, error) {
+	if wt, ok := r.r.(io.WriterTo); ok {
+		return wt.WriteTo(&Writer{ctx: r.ctx, w: w, bar: r.bar})
+	}
+	// Hide WriteTo so io.Copy does not call back into this method.
+	return io.Copy(w, struct{ io.Reader }{r})
+}
+
+// Close closes the underlying reader if it is an io.Closer and then
+// finalizes the bar.
+func (r *Reader) Close() error {
+	var err error
+	if c, ok := r.r.(io.Closer); ok {
+		err = c.Close()
+	}
+	if cerr := r.bar.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Writer reports every byte written through it to a Bar. It implements
+// io.ReaderFrom, delegating to the underlying writer's ReadFrom when it
+// has one, so io.Copy keeps its fast path.
+type Writer struct {
+	ctx context.Context
+	w   io.Writer
+	bar *Bar
+}
+
+// NewWriter wraps w so writes advance bar.
+func NewWriter(w io.Writer, bar *Bar) *Writer {
+	return NewWriterContext(context.Background(), w, bar)
+}
+
+// NewWriterContext is NewWriter with writes failing with ctx.Err() once
+// ctx is done. A write that is already blocked is not interrupted.
+func NewWriterContext(ctx context.Context, w io.Writer, bar *Bar) *Writer {
+	return &Writer{ctx: ctx, w: w, bar: bar}
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := w.w.Write(p)
+	w.bar.Add(int64(n))
+	return n, err
+}
+
+// ReadFrom implements io.ReaderFrom.
+func (w *Writer) ReadFrom(src io.Reader) (int64, error) {
+	if rf, ok := w.w.(io.ReaderFrom); ok {
+		return rf.ReadFrom(&Reader{ctx: w.ctx, r: src, bar: w.bar})
+	}
+	// Hide ReadFrom so io.Copy does not call back into this method.
+	return io.Copy(struct{ io.Writer }{w}, src)
+}
+
+// Close closes the underlying writer if it is an io.Closer and then
+// finalizes the bar.
+func (w *Writer) Close() error {
+	var err error
+	if c, ok := w.w.(io.Closer); ok {
+		err = c.Close()
+	}
+	if cerr := w.bar.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,110 @@
+package progressbar
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// onlyReader and onlyWriter hide any fast-path methods of what they wrap.
+type onlyReader struct{ io.Reader }
+
+type onlyWriter struct{ io.Writer }
+
+// recordingWriter notes whether ReadFrom was used.
+type recordingWriter struct {
+	bytes.Buffer
+	readFrom bool
+}
+
+func (w *recordingWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.readFrom = true
+	return w.Buffer.ReadFrom(r)
+}
+
+func TestReaderCountsBytes(t *testing.T) {
+	bar := DefaultBytes(11, "r", WithOutput(io.Discard))
+	var dst bytes.Buffer
+	n, err := io.Copy(onlyWriter{&dst}, NewReader(onlyReader{strings.NewReader("hello world")}, bar))
+	if err != nil || n != 11 {
+		t.Fatalf("copy = %d, %v", n, err)
+	}
+	if bar.current != 11 || dst.String() != "hello world" {
+		t.Errorf("current = %d, dst = %q", bar.current, dst.String())
+	}
+}
+
+func TestReaderWriteToPassthrough(t *testing.T) {
+	bar := DefaultBytes(5, "r", WithOutput(io.Discard))
+	// strings.Reader implements io.WriterTo.
+	r := NewReader(strings.NewReader("hello"), bar)
+	var dst bytes.Buffer
+	if _, err := r.WriteTo(onlyWriter{&dst}); err != nil {
+		t.Fatal(err)
+	}
+	if bar.current != 5 || dst.String() != "hello" {
+		t.Errorf("current = %d, dst = %q", bar.current, dst.String())
+	}
+
+	bar = DefaultBytes(5, "r", WithOutput(io.Discard))
+	if _, err := NewReader(onlyReader{strings.NewReader("hello")}, bar).WriteTo(&dst); err != nil {
+		t.Fatal(err)
+	}
+	if bar.current != 5 {
+		t.Errorf("fallback WriteTo current = %d", bar.current)
+	}
+}
+
+func TestWriterReadFromPassthrough(t *testing.T) {
+	bar := DefaultBytes(6, "w", WithOutput(io.Discard))
+	dst := &recordingWriter{}
+	n, err := io.Copy(NewWriter(dst, bar), onlyReader{strings.NewReader("abcdef")})
+	if err != nil || n != 6 {
+		t.Fatalf("copy = %d, %v", n, err)
+	}
+	if !dst.readFrom {
+		t.Error("underlying ReadFrom was not used")
+	}
+	if bar.current != 6 || dst.String() != "abcdef" {
+		t.Errorf("current = %d, dst = %q", bar.current, dst.String())
+	}
+
+	bar = DefaultBytes(6, "w", WithOutput(io.Discard))
+	var plain bytes.Buffer
+	if _, err := NewWriter(onlyWriter{&plain}, bar).ReadFrom(strings.NewReader("abcdef")); err != nil {
+		t.Fatal(err)
+	}
+	if bar.current != 6 {
+		t.Errorf("fallback ReadFrom current = %d", bar.current)
+	}
+}
+
+func TestReaderContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	bar := DefaultBytes(-1, "r", WithOutput(io.Discard))
+	r := NewReaderContext(ctx, strings.NewReader("data"), bar)
+	cancel()
+	if _, err := io.ReadAll(r); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	w := NewWriterContext(ctx, io.Discard, bar)
+	if _, err := w.Write([]byte("x")); !errors.Is(err, context.Canceled) {
+		t.Errorf("write err = %v, want context.Canceled", err)
+	}
+}
+
+func TestReaderCloseFinalizesBar(t *testing.T) {
+	var out bytes.Buffer
+	bar := DefaultBytes(4, "r", WithOutput(&out))
+	r := NewReader(io.NopCloser(strings.NewReader("data")), bar)
+	_, _ = io.ReadAll(r)
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(out.String(), "\n") || !strings.Contains(out.String(), "100%") {
+		t.Errorf("bar not finalized: %q", out.String())
+	}
+}
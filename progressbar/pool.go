@@ -0,0 +1,222 @@
+package progressbar
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PoolOption configures a Pool.
+type PoolOption func(*Pool)
+
+// WithPoolOutput sets the writer the pool draws on. Defaults to stderr.
+func WithPoolOutput(w io.Writer) PoolOption {
+	return func(p *Pool) { p.output = w }
+}
+
+// WithRefreshInterval sets how often the pool redraws. Defaults to 100ms.
+func WithRefreshInterval(d time.Duration) PoolOption {
+	return func(p *Pool) { p.interval = d }
+}
+
+// WithPoolInteractive forces in-place ANSI redraws or plain output instead
+// of detecting whether the output is a terminal. In plain mode the pool
+// prints every bar as a plain line at the plain interval and on Stop.
+func WithPoolInteractive(interactive bool) PoolOption {
+	return func(p *Pool) {
+		p.mode = modePlain
+		if interactive {
+			p.mode = modeInteractive
+		}
+	}
+}
+
+// WithPoolPlainInterval sets how often a pool in plain mode prints.
+// Defaults to 10s.
+func WithPoolPlainInterval(d time.Duration) PoolOption {
+	return func(p *Pool) { p.plainEvery = d }
+}
+
+// WithPoolClock sets the time source used to rate limit plain output.
+// Defaults to the system clock; the redraw ticker always runs in real time.
+func WithPoolClock(c Clock) PoolOption {
+	return func(p *Pool) { p.clock = c }
+}
+
+// Pool draws several bars as a block of lines, one per bar, and redraws
+// the whole block in place with ANSI cursor movement. Bars in a pool do
+// not draw themselves; a single ticker owned by the pool does.
+type Pool struct {
+	output     io.Writer
+	interval   time.Duration
+	plainEvery time.Duration
+	clock      Clock
+
+	mu        sync.Mutex
+	bars      []*Bar
+	lines     int
+	stop      chan struct{}
+	done      chan struct{}
+	detected  bool
+	mode      int
+	cols      int
+	resize    chan os.Signal
+	lastFrame time.Time
+}
+
+// NewPool returns an empty pool. The redraw ticker starts with the first
+// Add and runs until Stop.
+func NewPool(opts ...PoolOption) *Pool {
+	p := &Pool{
+		output:     os.Stderr,
+		interval:   redrawInterval,
+		plainEvery: defaultPlainEvery,
+		clock:      systemClock{},
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Add attaches bars to the bottom of the pool.
+func (p *Pool) Add(bars ...*Bar) {
+	for _, b := range bars {
+		b.mu.Lock()
+		b.pool = p
+		b.mu.Unlock()
+	}
+	p.mu.Lock()
+	p.bars = append(p.bars, bars...)
+	if p.stop == nil {
+		p.stop = make(chan struct{})
+		p.done = make(chan struct{})
+		go p.run(p.stop, p.done)
+	}
+	p.mu.Unlock()
+}
+
+// Remove detaches b and erases its line. The bar draws itself again
+// afterwards.
+func (p *Pool) Remove(b *Bar) {
+	p.mu.Lock()
+	for i, pb := range p.bars {
+		if pb == b {
+			p.bars = append(p.bars[:i], p.bars[i+1:]...)
+			break
+		}
+	}
+	p.mu.Unlock()
+	b.mu.Lock()
+	if b.pool == p {
+		b.pool = nil
+	}
+	b.mu.Unlock()
+	p.draw(true)
+}
+
+// Stop halts the ticker and draws the final state of every bar. The pool
+// may be reused by adding bars again.
+func (p *Pool) Stop() {
+	p.mu.Lock()
+	stop, done := p.stop, p.done
+	p.stop, p.done = nil, nil
+	p.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	p.redraw()
+	p.mu.Lock()
+	if p.resize != nil {
+		signal.Stop(p.resize)
+		close(p.resize)
+		p.resize, p.detected = nil, false
+	}
+	p.mu.Unlock()
+}
+
+func (p *Pool) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			p.draw(true)
+		}
+	}
+}
+
+// redraw draws the current frame unconditionally.
+func (p *Pool) redraw() { p.draw(false) }
+
+// draw moves the cursor back over the previous frame and writes one line
+// per bar, clearing whatever the previous frame left below. In plain mode
+// it writes the lines as they are, and periodic draws are rate limited.
+func (p *Pool) draw(periodic bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.detect()
+	now := p.clock.Now()
+	if p.mode == modePlain {
+		if periodic && now.Sub(p.lastFrame) < p.plainEvery {
+			return
+		}
+		p.lastFrame = now
+		var frame strings.Builder
+		for _, b := range p.bars {
+			frame.WriteString(b.line(0))
+			frame.WriteString("\n")
+		}
+		_, _ = io.WriteString(p.output, frame.String())
+		return
+	}
+	var frame strings.Builder
+	if p.lines > 0 {
+		fmt.Fprintf(&frame, "\x1b[%dA", p.lines)
+	}
+	for _, b := range p.bars {
+		frame.WriteString("\r")
+		frame.WriteString(b.line(p.cols))
+		frame.WriteString("\x1b[K\n")
+	}
+	frame.WriteString("\x1b[J")
+	p.lines = len(p.bars)
+	_, _ = io.WriteString(p.output, frame.String())
+}
+
+// detect mirrors Bar.detect for the pool's output. The caller holds p.mu.
+func (p *Pool) detect() {
+	if p.detected {
+		return
+	}
+	p.detected = true
+	f, ok := p.output.(*os.File)
+	tty := ok && isTerminal(f)
+	if p.mode == modeUnknown {
+		p.mode = modeInteractive
+		if ok && !tty {
+			p.mode = modePlain
+		}
+	}
+	if p.mode == modeInteractive && tty {
+		p.cols = terminalWidth(f)
+		p.resize = make(chan os.Signal, 1)
+		notifyResize(p.resize)
+		go func(c <-chan os.Signal) {
+			for range c {
+				cols := terminalWidth(f)
+				p.mu.Lock()
+				p.cols = cols
+				p.mu.Unlock()
+			}
+		}(p.resize)
+	}
+}
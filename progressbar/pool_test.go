@@ -0,0 +1,102 @@
+package progressbar
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPoolDrawsEveryBar(t *testing.T) {
+	var buf bytes.Buffer
+	pool := NewPool(WithPoolOutput(&buf), WithRefreshInterval(time.Hour))
+	a := DefaultBytes(100, "alpha")
+	b := DefaultCount(10, "beta")
+	var aOut, bOut bytes.Buffer
+	a.output = &aOut
+	b.output = &bOut
+	pool.Add(a, b)
+
+	_, _ = a.Write(make([]byte, 50))
+	b.Set(10)
+	pool.Stop()
+
+	out := buf.String()
+	if !strings.Contains(out, "alpha  50%") || !strings.Contains(out, "beta 100%") {
+		t.Errorf("pool frame missing bars: %q", out)
+	}
+	if strings.Index(out, "alpha") > strings.Index(out, "beta") {
+		t.Errorf("bars out of order: %q", out)
+	}
+	if aOut.Len() != 0 || bOut.Len() != 0 {
+		t.Error("pooled bars drew themselves")
+	}
+}
+
+func TestPoolMovesCursorOverPreviousFrame(t *testing.T) {
+	var buf bytes.Buffer
+	pool := NewPool(WithPoolOutput(&buf), WithRefreshInterval(time.Hour))
+	pool.Add(DefaultCount(1, "one"), DefaultCount(1, "two"))
+	pool.redraw()
+	buf.Reset()
+	pool.redraw()
+	if !strings.HasPrefix(buf.String(), "\x1b[2A") {
+		t.Errorf("second frame does not move up two lines: %q", buf.String())
+	}
+	pool.Stop()
+}
+
+func TestPoolRemove(t *testing.T) {
+	var buf bytes.Buffer
+	pool := NewPool(WithPoolOutput(&buf), WithRefreshInterval(time.Hour))
+	keep := DefaultCount(1, "keep")
+	gone := DefaultCount(1, "gone")
+	pool.Add(keep, gone)
+	pool.redraw()
+
+	pool.Remove(gone)
+	buf.Reset()
+	pool.Stop()
+	if strings.Contains(buf.String(), "gone") {
+		t.Errorf("removed bar still drawn: %q", buf.String())
+	}
+	if gone.pool != nil {
+		t.Error("removed bar still attached to pool")
+	}
+}
+
+func TestPoolTickerRedraws(t *testing.T) {
+	var buf safeBuffer
+	pool := NewPool(WithPoolOutput(&buf), WithRefreshInterval(5*time.Millisecond))
+	bar := DefaultCount(4, "tick")
+	pool.Add(bar)
+	bar.Set(2)
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(buf.String(), "50%") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	pool.Stop()
+	if !strings.Contains(buf.String(), "50%") {
+		t.Errorf("ticker never redrew: %q", buf.String())
+	}
+}
+
+// safeBuffer is a bytes.Buffer that may be written by the pool's ticker
+// while the test reads it.
+type safeBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *safeBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -3,16 +3,30 @@ package progressbar
 import (
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
+	"unicode/utf8"
 )
 
 const (
-	defaultWidth   = 40
-	redrawInterval = 100 * time.Millisecond
+	defaultWidth      = 40
+	minWidth          = 10
+	redrawInterval    = 100 * time.Millisecond
+	defaultPlainStep  = 0.10
+	defaultPlainEvery = 10 * time.Second
+)
+
+// Output modes, chosen on first draw unless forced with WithInteractive.
+const (
+	modeUnknown = iota
+	modeInteractive
+	modePlain
 )
 
 // Option configures a Bar.
@@ -37,9 +51,42 @@ func WithETA() Option {
 	return func(b *Bar) { b.showETA = true }
 }
 
-// WithWidth sets the bar width in characters.
+// WithWidth sets the bar width in characters. Without it, a bar drawing
+// to a terminal sizes itself to the terminal's columns.
 func WithWidth(n int) Option {
-	return func(b *Bar) { b.width = n }
+	return func(b *Bar) {
+		b.width = n
+		b.fixedWidth = true
+	}
+}
+
+// WithInteractive forces interactive (\r redraw) or plain (one line per
+// update) output instead of detecting whether the output is a terminal.
+func WithInteractive(interactive bool) Option {
+	return func(b *Bar) {
+		b.mode = modePlain
+		if interactive {
+			b.mode = modeInteractive
+		}
+	}
+}
+
+// WithPlainUpdates sets how often a bar in plain mode prints a line: each
+// time progress crosses another step (a fraction such as 0.1 for every
+// 10%), or after every has passed since the last line, whichever comes
+// first. Either may be zero to disable it. Defaults to 10% and 10s.
+func WithPlainUpdates(step float64, every time.Duration) Option {
+	return func(b *Bar) {
+		b.plainStep = step
+		b.plainEvery = every
+	}
+}
+
+// WithSmoothing sets the time constant of the moving average behind the
+// speed and ETA. Larger values react more slowly to changes in speed.
+// Defaults to 3s; zero shows the speed of the latest sample.
+func WithSmoothing(d time.Duration) Option {
+	return func(b *Bar) { b.rate.tau = d }
 }
 
 // WithOutput sets the output writer.
@@ -47,12 +94,33 @@ func WithOutput(w io.Writer) Option {
 	return func(b *Bar) { b.output = w }
 }
 
+// Clock is the time source of a Bar or Pool. It is satisfied by
+// times.Clock and times.FakeClock, which lets tests control elapsed time
+// without this package depending on them.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// WithClock sets the time source for elapsed time, speed, ETA and redraw
+// throttling. Defaults to the system clock.
+func WithClock(c Clock) Option {
+	return func(b *Bar) { b.clock = c }
+}
+
+// Bar is a single progress bar. A total of zero or less, such as the -1
+// ContentLength of a response without one, makes it indeterminate: it
+// shows a spinner and the running count instead of a percentage.
 type Bar struct {
 	total       int64
 	current     int64
 	desc        string
 	width       int
 	output      io.Writer
+	clock       Clock
 	startTime   time.Time
 	lastDraw    time.Time
 	formatValue func(int64) string
@@ -62,6 +130,23 @@ type Bar struct {
 	empty       string
 	showSpeed   bool
 	showETA     bool
+	pool        *Pool
+	fixedWidth  bool
+	detected    bool
+	mode        int
+	cols        int
+	plainStep   float64
+	plainEvery  time.Duration
+	plainLast   float64
+	plainLine   string
+	resize      chan os.Signal
+	rate        rateEstimator
+	template    *template.Template
+	events      io.Writer
+	eventFunc   func(Event)
+	lastEvent   time.Time
+	noRender    bool
+	emitMu      sync.Mutex
 	mu          sync.Mutex
 }
 
@@ -73,16 +158,20 @@ func DefaultBytes(total int64, description string, opts ...Option) *Bar {
 		desc:        description,
 		width:       defaultWidth,
 		output:      os.Stderr,
-		startTime:   time.Now(),
+		clock:       systemClock{},
 		formatValue: formatBytes,
 		formatSpeed: func(s float64) string { return formatBytes(int64(s)) + "/s" },
 		fill:        "=",
 		head:        ">",
 		empty:       " ",
+		plainStep:   defaultPlainStep,
+		plainEvery:  defaultPlainEvery,
+		rate:        rateEstimator{tau: defaultSmoothing},
 	}
 	for _, opt := range opts {
 		opt(b)
 	}
+	b.startTime = b.clock.Now()
 	return b
 }
 
@@ -94,16 +183,20 @@ func DefaultCount(total int64, description string, opts ...Option) *Bar {
 		desc:        description,
 		width:       defaultWidth,
 		output:      os.Stderr,
-		startTime:   time.Now(),
+		clock:       systemClock{},
 		formatValue: formatCount,
 		formatSpeed: func(s float64) string { return fmt.Sprintf("%.0f/s", s) },
 		fill:        "=",
 		head:        ">",
 		empty:       " ",
+		plainStep:   defaultPlainStep,
+		plainEvery:  defaultPlainEvery,
+		rate:        rateEstimator{tau: defaultSmoothing},
 	}
 	for _, opt := range opts {
 		opt(b)
 	}
+	b.startTime = b.clock.Now()
 	return b
 }
 
@@ -111,12 +204,9 @@ func DefaultCount(total int64, description string, opts ...Option) *Bar {
 func (b *Bar) Set(n int64) {
 	b.mu.Lock()
 	b.current = n
-	now := time.Now()
-	shouldDraw := now.Sub(b.lastDraw) >= redrawInterval
+	draw, emit := b.updated(b.clock.Now())
 	b.mu.Unlock()
-	if shouldDraw {
-		b.render()
-	}
+	b.publish(draw, emit)
 }
 
 // Add increments the current progress by n and returns the new value.
@@ -124,12 +214,9 @@ func (b *Bar) Add(n int64) int64 {
 	b.mu.Lock()
 	b.current += n
 	current := b.current
-	now := time.Now()
-	shouldDraw := now.Sub(b.lastDraw) >= redrawInterval
+	draw, emit := b.updated(b.clock.Now())
 	b.mu.Unlock()
-	if shouldDraw {
-		b.render()
-	}
+	b.publish(draw, emit)
 	return current
 }
 
@@ -138,87 +225,308 @@ func (b *Bar) Write(p []byte) (int, error) {
 	n := len(p)
 	b.mu.Lock()
 	b.current += int64(n)
-	now := time.Now()
-	shouldDraw := now.Sub(b.lastDraw) >= redrawInterval
+	draw, emit := b.updated(b.clock.Now())
 	b.mu.Unlock()
-	if shouldDraw {
-		b.render()
-	}
+	b.publish(draw, emit)
 	return n, nil
 }
 
-// Close finalizes the progress bar with a final render and newline.
+// Close finalizes the progress bar with a final render and newline. A bar
+// in a Pool is redrawn in place and stays on screen until removed.
 func (b *Bar) Close() error {
+	b.mu.Lock()
+	pool := b.pool
+	noRender := b.noRender
+	emit := b.hasEvents()
+	b.mu.Unlock()
+	if emit {
+		b.emit(true)
+	}
+	if noRender {
+		return nil
+	}
+	if pool != nil {
+		pool.redraw()
+		return nil
+	}
 	b.render()
-	fmt.Fprintln(b.output)
+	b.mu.Lock()
+	plain := b.mode == modePlain
+	if b.resize != nil {
+		signal.Stop(b.resize)
+		close(b.resize)
+		b.resize = nil
+	}
+	b.mu.Unlock()
+	if !plain {
+		fmt.Fprintln(b.output)
+	}
 	return nil
 }
 
+// updated records a progress change at now and reports whether it should
+// be drawn and emitted as an Event. The caller holds b.mu.
+func (b *Bar) updated(now time.Time) (draw, emit bool) {
+	b.rate.observe(now, b.current, b.startTime)
+	if b.hasEvents() && now.Sub(b.lastEvent) >= redrawInterval {
+		b.lastEvent = now
+		emit = true
+	}
+	return b.due(now), emit
+}
+
+func (b *Bar) publish(draw, emit bool) {
+	if draw {
+		b.render()
+	}
+	if emit {
+		b.emit(false)
+	}
+}
+
+// due reports whether an update at now should draw. The caller holds b.mu.
+func (b *Bar) due(now time.Time) bool {
+	if b.pool != nil || b.noRender {
+		return false
+	}
+	b.detect()
+	if b.mode != modePlain {
+		return now.Sub(b.lastDraw) >= redrawInterval
+	}
+	if b.plainEvery > 0 && now.Sub(b.lastDraw) >= b.plainEvery {
+		return true
+	}
+	if b.plainStep > 0 && b.total > 0 {
+		pct := float64(b.current) / float64(b.total)
+		return math.Floor(pct/b.plainStep) > math.Floor(b.plainLast/b.plainStep)
+	}
+	return false
+}
+
+// detect picks the output mode on first use: plain for files that are
+// not terminals, interactive otherwise. Interactive bars on a terminal
+// track its width unless WithWidth was given. The caller holds b.mu.
+func (b *Bar) detect() {
+	if b.detected {
+		return
+	}
+	b.detected = true
+	f, ok := b.output.(*os.File)
+	tty := ok && isTerminal(f)
+	if b.mode == modeUnknown {
+		b.mode = modeInteractive
+		if ok && !tty {
+			b.mode = modePlain
+		}
+	}
+	if b.mode == modeInteractive && tty && !b.fixedWidth {
+		b.cols = terminalWidth(f)
+		b.resize = make(chan os.Signal, 1)
+		notifyResize(b.resize)
+		go b.watchResize(f, b.resize)
+	}
+}
+
+// watchResize refreshes the cached terminal width on every SIGWINCH until
+// the channel is closed.
+func (b *Bar) watchResize(f *os.File, c <-chan os.Signal) {
+	for range c {
+		cols := terminalWidth(f)
+		b.mu.Lock()
+		b.cols = cols
+		b.mu.Unlock()
+	}
+}
+
 func (b *Bar) render() {
 	b.mu.Lock()
-	current := b.current
-	total := b.total
-	start := b.startTime
-	b.lastDraw = time.Now()
+	b.detect()
+	b.lastDraw = b.clock.Now()
+	plain := b.mode == modePlain
+	cols := b.cols
+	if b.total > 0 {
+		b.plainLast = float64(b.current) / float64(b.total)
+	}
+	b.mu.Unlock()
+	if !plain {
+		fmt.Fprint(b.output, "\r"+b.line(cols))
+		return
+	}
+	line := b.line(0)
+	b.mu.Lock()
+	repeat := line == b.plainLine
+	b.plainLine = line
 	b.mu.Unlock()
+	if !repeat {
+		fmt.Fprintln(b.output, line)
+	}
+}
 
-	pct := float64(0)
-	if total > 0 {
-		pct = float64(current) / float64(total)
-		if pct > 1.0 {
-			pct = 1.0
-		}
+// line formats the bar without any cursor control. When cols is positive
+// the bar is sized, and the description truncated, so the line fits in
+// cols-1 columns and never wraps. Custom templates are not fitted.
+func (b *Bar) line(cols int) string {
+	st := b.snapshot(b.clock.Now())
+	current, total, elapsed, pct := st.current, st.total, st.elapsed, st.pct
+	indeterminate := total <= 0
+
+	data := TemplateData{
+		Description:   b.desc,
+		Percent:       pct * 100,
+		Values:        b.formatValue(current) + " / " + b.formatValue(total),
+		Elapsed:       formatDuration(elapsed),
+		Indeterminate: indeterminate,
+		Current:       current,
+		Total:         total,
+	}
+	if indeterminate {
+		data.Values = b.formatValue(current)
+	}
+	if st.hasRate {
+		data.Rate = b.formatSpeed(st.rate)
+	}
+	if st.hasETA {
+		data.ETA = formatDuration(st.remaining)
 	}
 
-	filled := int(pct * float64(b.width))
-	var bar strings.Builder
-	bar.Grow(b.width)
-	for i := range b.width {
-		switch {
-		case i < filled:
-			bar.WriteString(b.fill)
-		case i == filled && filled < b.width:
-			bar.WriteString(b.head)
-		default:
-			bar.WriteString(b.empty)
+	if b.template != nil {
+		if indeterminate {
+			data.Bar = spinnerFrame(elapsed)
+		} else {
+			data.Bar = b.bar(pct, b.width)
+		}
+		var out strings.Builder
+		if err := b.template.Execute(&out, data); err != nil {
+			return err.Error()
 		}
+		return out.String()
 	}
 
-	values := b.formatValue(current) + " / " + b.formatValue(total)
-
+	values := data.Values
 	if b.showSpeed || b.showETA {
-		elapsed := time.Since(start)
 		var extra strings.Builder
 		extra.WriteString("(")
 		extra.WriteString(values)
-		if b.showSpeed {
-			secs := elapsed.Seconds()
-			if secs >= 0.001 {
-				speed := float64(current) / secs
-				extra.WriteString(", ")
-				extra.WriteString(b.formatSpeed(speed))
-			}
+		if b.showSpeed && data.Rate != "" {
+			extra.WriteString(", ")
+			extra.WriteString(data.Rate)
 		}
 		extra.WriteString(")")
-		if b.showETA && current > 0 && current < total {
-			remaining := time.Duration(float64(elapsed) * float64(total-current) / float64(current))
+		switch {
+		case b.showETA && data.ETA != "":
 			extra.WriteString(" [")
-			extra.WriteString(formatDuration(elapsed))
+			extra.WriteString(data.Elapsed)
 			extra.WriteString(":")
-			extra.WriteString(formatDuration(remaining))
+			extra.WriteString(data.ETA)
+			extra.WriteString("]")
+		case b.showETA && indeterminate:
+			extra.WriteString(" [")
+			extra.WriteString(data.Elapsed)
 			extra.WriteString("]")
 		}
 		values = extra.String()
 	}
 
-	fmt.Fprintf(b.output, "\r%s %3.0f%% |%s| %s",
-		b.desc,
+	desc := b.desc
+	if indeterminate {
+		data.Bar = spinnerFrame(elapsed)
+		if cols > 0 {
+			// Two spaces around the spinner, plus one spare column.
+			desc = truncate(desc, cols-1-3-utf8.RuneCountInString(values))
+		}
+		return desc + " " + data.Bar + " " + values
+	}
+
+	width := b.width
+	if cols > 0 {
+		// " 100% |" + "| " around the bar, plus one spare column.
+		avail := cols - 1 - 9 - utf8.RuneCountInString(values)
+		width = avail - utf8.RuneCountInString(desc)
+		if width < minWidth {
+			width = minWidth
+			desc = truncate(desc, avail-minWidth)
+		}
+	}
+
+	return fmt.Sprintf("%s %3.0f%% |%s| %s",
+		desc,
 		pct*100,
-		bar.String(),
+		b.bar(pct, width),
 		values,
 	)
 }
 
+// snapshot is the numeric state a line or an Event is built from.
+type snapshot struct {
+	current   int64
+	total     int64
+	elapsed   time.Duration
+	pct       float64
+	rate      float64
+	hasRate   bool
+	remaining time.Duration
+	hasETA    bool
+}
+
+func (b *Bar) snapshot(now time.Time) snapshot {
+	b.mu.Lock()
+	st := snapshot{current: b.current, total: b.total, elapsed: now.Sub(b.startTime)}
+	st.rate, st.hasRate = b.rate.at(now, b.current, b.startTime)
+	b.mu.Unlock()
+
+	if st.total > 0 {
+		st.pct = min(float64(st.current)/float64(st.total), 1.0)
+	}
+	if st.current > 0 && st.current < st.total {
+		if st.hasRate && st.rate > 0 {
+			st.remaining = time.Duration(float64(st.total-st.current) / st.rate * float64(time.Second))
+		} else {
+			st.remaining = time.Duration(float64(st.elapsed) * float64(st.total-st.current) / float64(st.current))
+		}
+		st.hasETA = true
+	}
+	return st
+}
+
+// bar draws width cells filled to pct.
+func (b *Bar) bar(pct float64, width int) string {
+	filled := int(pct * float64(width))
+	var bar strings.Builder
+	bar.Grow(width)
+	for i := range width {
+		switch {
+		case i < filled:
+			bar.WriteString(b.fill)
+		case i == filled && filled < width:
+			bar.WriteString(b.head)
+		default:
+			bar.WriteString(b.empty)
+		}
+	}
+	return bar.String()
+}
+
+var spinnerFrames = [...]string{"|", "/", "-", "\\"}
+
+// spinnerFrame picks the frame for elapsed, advancing once per redraw
+// interval.
+func spinnerFrame(elapsed time.Duration) string {
+	return spinnerFrames[int(elapsed/redrawInterval)%len(spinnerFrames)]
+}
+
+// truncate shortens s to at most n runes, marking the cut with an
+// ellipsis.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	if n <= 0 {
+		return ""
+	}
+	r := []rune(s)
+	return string(r[:n-1]) + "\u2026"
+}
+
 func formatBytes(b int64) string {
 	const (
 		kb = 1024
@@ -2,9 +2,12 @@ package progressbar
 
 import (
 	"bytes"
+	"io"
+	"os"
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 func TestWriteAccumulatesBytes(t *testing.T) {
@@ -87,9 +90,10 @@ func TestZeroTotal(t *testing.T) {
 	_, _ = bar.Write([]byte("data"))
 	_ = bar.Close()
 
+	// A zero total is unknown: the bar counts instead of sitting at 0%.
 	output := buf.String()
-	if !strings.Contains(output, "0%") {
-		t.Errorf("expected 0%% for zero total, got: %s", output)
+	if strings.Contains(output, "%") || !strings.Contains(output, "4 B") {
+		t.Errorf("expected a running count for zero total, got: %s", output)
 	}
 }
 
@@ -261,3 +265,172 @@ func TestWithOutputOption(t *testing.T) {
 		t.Fatal("expected output to be written to custom writer")
 	}
 }
+
+func TestPlainModeForNonTerminalFile(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	bar := DefaultCount(100, "Copying", WithOutput(f), WithPlainUpdates(0.25, 0))
+	for i := 0; i < 100; i++ {
+		bar.Add(1)
+	}
+	_ = bar.Close()
+
+	content, _ := os.ReadFile(f.Name())
+	out := string(content)
+	if strings.Contains(out, "\r") {
+		t.Errorf("plain output contains carriage returns: %q", out)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	// One line per 25% step; Close does not repeat the 100% line.
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 4:\n%s", len(lines), out)
+	}
+	if !strings.Contains(lines[len(lines)-1], "100%") {
+		t.Errorf("last line = %q, want 100%%", lines[len(lines)-1])
+	}
+}
+
+func TestPlainModeTimeInterval(t *testing.T) {
+	var buf bytes.Buffer
+	bar := DefaultCount(0, "Waiting", WithOutput(&buf), WithInteractive(false), WithPlainUpdates(0, time.Hour))
+	bar.Add(1)
+	bar.Add(1)
+	bar.mu.Lock()
+	bar.lastDraw = bar.lastDraw.Add(-time.Hour)
+	bar.mu.Unlock()
+	bar.Add(1)
+	if n := strings.Count(buf.String(), "\n"); n != 2 {
+		t.Errorf("got %d lines, want 2: %q", n, buf.String())
+	}
+}
+
+func TestLineFitsColumns(t *testing.T) {
+	bar := DefaultCount(100, strings.Repeat("d", 200), WithOutput(io.Discard))
+	bar.Set(50)
+	for _, cols := range []int{40, 80, 120} {
+		line := bar.line(cols)
+		if n := utf8.RuneCountInString(line); n >= cols {
+			t.Errorf("cols=%d: line is %d runes: %q", cols, n, line)
+		}
+		if !strings.Contains(line, "…") {
+			t.Errorf("cols=%d: description not truncated: %q", cols, line)
+		}
+	}
+
+	short := DefaultCount(100, "ok")
+	line := short.line(80)
+	if n := utf8.RuneCountInString(line); n != 79 {
+		t.Errorf("bar not stretched to terminal: %d runes: %q", n, line)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 5, "hello"},
+		{"hello", 4, "hel…"},
+		{"héllo", 2, "h…"},
+		{"hello", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.in, tt.n); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.in, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestIndeterminateSpinner(t *testing.T) {
+	// Both -1 and 0, as from a missing Content-Length, mean unknown.
+	for _, total := range []int64{-1, 0} {
+		var buf bytes.Buffer
+		bar := DefaultBytes(total, "Streaming", WithOutput(&buf), WithETA())
+		_, _ = bar.Write(make([]byte, 2048))
+		_ = bar.Close()
+
+		out := buf.String()
+		if strings.Contains(out, "%") {
+			t.Errorf("total %d: indeterminate bar shows a percentage: %q", total, out)
+		}
+		if !strings.Contains(out, "2.0 KB") || strings.Contains(out, "/ ") {
+			t.Errorf("total %d: indeterminate bar should show only the count: %q", total, out)
+		}
+		if !strings.ContainsAny(out, `|/-\`) {
+			t.Errorf("total %d: no spinner frame: %q", total, out)
+		}
+	}
+}
+
+func TestSpinnerFrameAdvances(t *testing.T) {
+	if spinnerFrame(0) == spinnerFrame(redrawInterval) {
+		t.Error("spinner did not advance after one redraw interval")
+	}
+	if spinnerFrame(0) != spinnerFrame(4*redrawInterval) {
+		t.Error("spinner should cycle through four frames")
+	}
+}
+
+func TestETAUsesSmoothedRate(t *testing.T) {
+	bar := DefaultBytes(1000, "Upload", WithOutput(io.Discard), WithETA(), WithSpeed())
+	start := time.Now().Add(-10 * time.Second)
+	bar.startTime = start
+	// Fast for the first 5 seconds, then stalled until now: the running
+	// average would still say 50 B/s, the smoothed rate knows better.
+	bar.rate.observe(start.Add(5*time.Second), 500, start)
+	bar.current = 500
+
+	line := bar.line(0)
+	if strings.Contains(line, "[10s:10s]") {
+		t.Errorf("ETA from running average: %q", line)
+	}
+	if !strings.Contains(line, "B/s") {
+		t.Errorf("no speed: %q", line)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	bar := DefaultCount(4, "Jobs", WithOutput(&buf), WithWidth(4),
+		WithTemplate(`{{.Description}}: {{printf "%.0f" .Percent}}% [{{.Bar}}] {{.Values}}`))
+	bar.Set(2)
+	_ = bar.Close()
+	if !strings.Contains(buf.String(), "Jobs: 50% [==> ] 2 / 4") {
+		t.Errorf("template output = %q", buf.String())
+	}
+}
+
+func TestTemplatePanicsOnParseError(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for invalid template")
+		}
+	}()
+	WithTemplate("{{.Description")
+}
+
+// manualClock is a Clock that only moves when advanced.
+type manualClock struct{ now time.Time }
+
+func (c *manualClock) Now() time.Time { return c.now }
+
+func TestWithClock(t *testing.T) {
+	var buf bytes.Buffer
+	clock := &manualClock{now: time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)}
+	bar := DefaultCount(100, "Items", WithClock(clock), WithOutput(&buf),
+		WithInteractive(false), WithETA(), WithSmoothing(0))
+	clock.now = clock.now.Add(20 * time.Second)
+	bar.Set(50)
+	if st := bar.snapshot(clock.Now()); st.elapsed != 20*time.Second || st.remaining != 20*time.Second {
+		t.Errorf("elapsed, remaining = %v, %v, want 20s, 20s", st.elapsed, st.remaining)
+	}
+	_ = bar.Close()
+	if !strings.Contains(buf.String(), "20s") {
+		t.Errorf("expected fake elapsed time in output, got: %q", buf.String())
+	}
+}
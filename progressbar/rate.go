@@ -0,0 +1,62 @@
+package progressbar
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	defaultSmoothing = 3 * time.Second
+	minRateSample    = 100 * time.Millisecond
+)
+
+// rateEstimator is an exponentially weighted moving average of progress
+// per second. Weights come from the time between samples rather than the
+// sample count, so bursts of tiny writes and long pauses are both weighed
+// by how long they lasted.
+type rateEstimator struct {
+	tau       time.Duration
+	rate      float64
+	primed    bool
+	lastTime  time.Time
+	lastValue int64
+}
+
+// observe folds value at now into the average. Samples closer together
+// than minRateSample are accumulated into the next one.
+func (r *rateEstimator) observe(now time.Time, value int64, start time.Time) {
+	if r.lastTime.IsZero() {
+		r.lastTime = start
+	}
+	dt := now.Sub(r.lastTime)
+	if dt < minRateSample {
+		return
+	}
+	r.rate = r.blend(float64(value-r.lastValue)/dt.Seconds(), dt)
+	r.primed = true
+	r.lastTime = now
+	r.lastValue = value
+}
+
+// at returns the rate as of now without recording a sample, so time spent
+// without progress since the last sample pulls the estimate down.
+func (r *rateEstimator) at(now time.Time, value int64, start time.Time) (float64, bool) {
+	last := r.lastTime
+	if last.IsZero() {
+		last = start
+	}
+	dt := now.Sub(last)
+	if dt < minRateSample {
+		return r.rate, r.primed
+	}
+	return r.blend(float64(value-r.lastValue)/dt.Seconds(), dt), true
+}
+
+func (r *rateEstimator) blend(instant float64, dt time.Duration) float64 {
+	instant = math.Max(instant, 0)
+	if !r.primed || r.tau <= 0 {
+		return instant
+	}
+	alpha := 1 - math.Exp(-dt.Seconds()/r.tau.Seconds())
+	return alpha*instant + (1-alpha)*r.rate
+}
@@ -0,0 +1,47 @@
+package progressbar
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRateEstimatorSteady(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := rateEstimator{tau: 3 * time.Second}
+	for i := 1; i <= 20; i++ {
+		r.observe(start.Add(time.Duration(i)*time.Second), int64(i*100), start)
+	}
+	got, ok := r.at(start.Add(20*time.Second), 2000, start)
+	if !ok || math.Abs(got-100) > 0.001 {
+		t.Errorf("steady rate = %v, %v; want 100", got, ok)
+	}
+}
+
+func TestRateEstimatorDecaysDuringPause(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := rateEstimator{tau: 3 * time.Second}
+	for i := 1; i <= 10; i++ {
+		r.observe(start.Add(time.Duration(i)*time.Second), int64(i*100), start)
+	}
+	paused, _ := r.at(start.Add(20*time.Second), 1000, start)
+	if paused > 5 {
+		t.Errorf("rate after 10s pause = %v, want close to 0", paused)
+	}
+	// A long average from the start would still report 50/s.
+	if average := 1000.0 / 20; paused >= average {
+		t.Errorf("EWMA %v not below running average %v", paused, average)
+	}
+}
+
+func TestRateEstimatorIgnoresTinySamples(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := rateEstimator{tau: time.Second}
+	r.observe(start.Add(time.Millisecond), 1, start)
+	if r.primed {
+		t.Error("1ms sample should be accumulated, not recorded")
+	}
+	if _, ok := r.at(start.Add(time.Millisecond), 1, start); ok {
+		t.Error("rate should be unknown before the first full sample")
+	}
+}
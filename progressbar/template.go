@@ -0,0 +1,35 @@
+package progressbar
+
+import "text/template"
+
+// TemplateData is what a WithTemplate layout is executed with.
+type TemplateData struct {
+	Description string
+	// Percent is 0 to 100, and 0 for indeterminate bars.
+	Percent float64
+	// Bar is the filled bar at the configured width, or the current
+	// spinner frame for indeterminate bars.
+	Bar string
+	// Values is "current / total" in the bar's unit, e.g. "1.0 MB / 4.0 MB",
+	// or just the current value for indeterminate bars.
+	Values string
+	// Rate is the smoothed speed, e.g. "1.5 MB/s", empty until known.
+	Rate    string
+	Elapsed string
+	// ETA is the estimated time remaining, empty when unknown or done.
+	ETA           string
+	Indeterminate bool
+	Current       int64
+	Total         int64
+}
+
+// WithTemplate replaces the default layout with a text/template executed
+// with TemplateData, for example
+//
+//	{{.Description}} {{printf "%3.0f" .Percent}}% [{{.Bar}}] {{.Values}} {{.Rate}} ETA {{.ETA}}
+//
+// It panics if text does not parse, like template.Must.
+func WithTemplate(text string) Option {
+	t := template.Must(template.New("progressbar").Parse(text))
+	return func(b *Bar) { b.template = t }
+}
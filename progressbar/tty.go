@@ -0,0 +1,16 @@
+package progressbar
+
+import (
+	"os"
+	"strconv"
+)
+
+// terminalWidth returns the column count of the terminal behind f,
+// falling back to $COLUMNS, or 0 when neither is known.
+func terminalWidth(f *os.File) int {
+	if cols := ioctlWidth(f); cols > 0 {
+		return cols
+	}
+	cols, _ := strconv.Atoi(os.Getenv("COLUMNS"))
+	return cols
+}
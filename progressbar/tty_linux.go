@@ -0,0 +1,5 @@
+package progressbar
+
+import "golang.org/x/sys/unix"
+
+const ioctlReadTermios = unix.TCGETS
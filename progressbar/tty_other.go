@@ -0,0 +1,19 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package progressbar
+
+import "os"
+
+// isTerminal reports whether f is a character device, the best guess
+// available without terminal ioctls. Pipes and regular files are not.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+func ioctlWidth(*os.File) int { return 0 }
+
+func notifyResize(chan<- os.Signal) {}
@@ -6,7 +6,6 @@ import (
 	"os"
 	"os/exec"
 	"sync"
-	"time"
 
 	"github.com/heatxsink/x/term"
 )
@@ -17,6 +16,14 @@ func ExecuteWithContext(ctx context.Context, env map[string]string, cmd string,
 	return execute(ctx, env, cmd, args...)
 }
 
+// ExecuteWithReporter is ExecuteWithContext reporting the command and its
+// output to r instead of stdout. When ctx carries a task (see
+// term.ContextWithTask) the command is reported as a step of it. A nil r
+// uses term.StdoutReporter.
+func ExecuteWithReporter(ctx context.Context, r term.Reporter, env map[string]string, cmd string, args ...string) error {
+	return executeReport(ctx, r, env, cmd, args...)
+}
+
 // ExecuteContext runs cmd under the supplied context.
 func ExecuteContext(ctx context.Context, cmd string, args ...string) error {
 	return execute(ctx, nil, cmd, args...)
@@ -37,7 +44,11 @@ func Execute(cmd string, args ...string) error {
 }
 
 func execute(ctx context.Context, env map[string]string, command string, args ...string) error {
-	start := term.StartlnWithTime(command, args...)
+	return executeReport(ctx, nil, env, command, args...)
+}
+
+func executeReport(ctx context.Context, r term.Reporter, env map[string]string, command string, args ...string) error {
+	task := term.StartTask(ctx, r, command, args...)
 	c := exec.CommandContext(ctx, command, args...) // #nosec G204 -- command is caller-controlled, this is the function's purpose
 	c.Env = os.Environ()
 	for k, v := range env {
@@ -45,28 +56,33 @@ func execute(ctx context.Context, env map[string]string, command string, args ..
 	}
 	stdout, err := c.StdoutPipe()
 	if err != nil {
+		task.End(err)
 		return fmt.Errorf("failed to get stdout. %w", err)
 	}
 	stderr, err := c.StderrPipe()
 	if err != nil {
+		task.End(err)
 		return fmt.Errorf("failed to get stderr: %w", err)
 	}
-	var wg sync.WaitGroup
-	wg.Add(1)
-	go term.DisplayLn(stdout, &wg, func(line string) {
-		term.Infoln(line)
-	})
-	wg.Add(1)
-	go term.DisplayLn(stderr, &wg, func(line string) {
-		term.Warnln(line)
-	})
-	err = c.Run()
-	wg.Wait()
+	// The pipes are read to EOF before Wait, which closes them.
+	if err = c.Start(); err == nil {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go term.DisplayLn(stdout, &wg, func(line string) {
+			task.Line(term.StreamStdout, line)
+		})
+		wg.Add(1)
+		go term.DisplayLn(stderr, &wg, func(line string) {
+			task.Line(term.StreamStderr, line)
+		})
+		wg.Wait()
+		err = c.Wait()
+	}
 	if err != nil {
-		term.Errorln(err)
-		term.EndlnWithTime(time.Since(start), false)
+		task.Line(term.StreamError, err.Error())
+		task.End(err)
 		return err
 	}
-	term.EndlnWithTime(time.Since(start), true)
+	task.End(nil)
 	return nil
 }
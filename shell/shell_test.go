@@ -1,8 +1,12 @@
 package shell
 
 import (
+	"bytes"
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/heatxsink/x/term"
 )
 
 func TestExecute(t *testing.T) {
@@ -119,3 +123,17 @@ func TestExecuteWithSpecialCharacters(t *testing.T) {
 		t.Errorf("Execute should handle special characters: %v", err)
 	}
 }
+
+func TestExecuteWithReporter(t *testing.T) {
+	var buf bytes.Buffer
+	err := ExecuteWithReporter(t.Context(), term.NewJSONReporter(&buf), nil, "sh", "-c", "echo out; echo err >&2")
+	if err != nil {
+		t.Fatalf("ExecuteWithReporter: %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{`"task":"sh -c echo out; echo err \u003e\u00262"`, `"stream":"stdout","text":"out"`, `"stream":"stderr","text":"err"`, `"status":"ok"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %s:\n%s", want, got)
+		}
+	}
+}
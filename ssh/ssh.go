@@ -12,7 +12,6 @@ import (
 	"regexp"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/heatxsink/x/progressbar"
 	"github.com/heatxsink/x/term"
@@ -31,6 +30,7 @@ type Client struct {
 	agentConn    net.Conn
 	agentClient  agent.ExtendedAgent
 	debug        bool
+	reporter     term.Reporter
 }
 
 // NewWithAgentContext dials the SSH agent socket under ctx, so the caller
@@ -129,6 +129,19 @@ func (c *Client) SetProperty(key, value string) {
 	c.properties[key] = value
 }
 
+// SetReporter sets where Execute and ExecuteInteractively report commands
+// and their output. The default, and a nil r, is term.StdoutReporter.
+func (c *Client) SetReporter(r term.Reporter) {
+	c.reporter = r
+}
+
+func (c *Client) startTask(command string) term.Task {
+	if c.reporter == nil {
+		return term.StdoutReporter().Start(command)
+	}
+	return c.reporter.Start(command)
+}
+
 func (c *Client) Connect() error {
 	if c.isConnected {
 		return nil
@@ -211,43 +224,43 @@ func (c *Client) RequestPty(session *ssh.Session) error {
 
 func (c *Client) Execute(command string) error {
 	var wg sync.WaitGroup
-	start := term.StartlnWithTime(command)
+	task := c.startTask(command)
 	session, err := c.NewSession()
 	if err != nil {
-		term.EndlnWithTime(time.Since(start), false)
+		task.End(err)
 		return fmt.Errorf("create session: %w", err)
 	}
 	defer session.Close()
 	stdout, err := session.StdoutPipe()
 	if err != nil {
-		term.EndlnWithTime(time.Since(start), false)
+		task.End(err)
 		return fmt.Errorf("stdout pipe: %w", err)
 	}
 	stderr, err := session.StderrPipe()
 	if err != nil {
-		term.EndlnWithTime(time.Since(start), false)
+		task.End(err)
 		return fmt.Errorf("stderr pipe: %w", err)
 	}
 	wg.Add(1)
 	go term.DisplayLn(stdout, &wg, func(line string) {
-		term.Infoln(line)
+		task.Line(term.StreamStdout, line)
 	})
 	wg.Add(1)
 	go term.DisplayLn(stderr, &wg, func(line string) {
-		term.Warnln(line)
+		task.Line(term.StreamStderr, line)
 	})
 	err = session.Start(command)
 	if err != nil {
-		term.EndlnWithTime(time.Since(start), false)
+		task.End(err)
 		return fmt.Errorf("session start: %w", err)
 	}
 	err = session.Wait()
 	if err != nil {
-		term.EndlnWithTime(time.Since(start), false)
+		task.End(err)
 		return fmt.Errorf("session wait: %w", err)
 	}
 	wg.Wait()
-	term.EndlnWithTime(time.Since(start), true)
+	task.End(nil)
 	return nil
 }
 
@@ -266,40 +279,40 @@ func (c *Client) ExecuteInteractively(command string, inputMap map[string]string
 	}
 
 	var wg sync.WaitGroup
-	start := term.StartlnWithTime(command)
+	task := c.startTask(command)
 	session, err := c.NewSession()
 	if err != nil {
-		term.EndlnWithTime(time.Since(start), false)
+		task.End(err)
 		return fmt.Errorf("create session: %w", err)
 	}
 	defer session.Close()
 	err = c.RequestPty(session)
 	if err != nil {
-		term.EndlnWithTime(time.Since(start), false)
+		task.End(err)
 		return fmt.Errorf("failed to request pty: %w", err)
 	}
 	stdin, err := session.StdinPipe()
 	if err != nil {
-		term.EndlnWithTime(time.Since(start), false)
+		task.End(err)
 		return fmt.Errorf("stdin pipe: %w", err)
 	}
 	stdout, err := session.StdoutPipe()
 	if err != nil {
-		term.EndlnWithTime(time.Since(start), false)
+		task.End(err)
 		return fmt.Errorf("stdout pipe: %w", err)
 	}
 	stderr, err := session.StderrPipe()
 	if err != nil {
-		term.EndlnWithTime(time.Since(start), false)
+		task.End(err)
 		return fmt.Errorf("stderr pipe: %w", err)
 	}
 	wg.Add(1)
 	go term.DisplayLn(stderr, &wg, func(line string) {
-		term.Warnln(line)
+		task.Line(term.StreamStderr, line)
 	})
 	err = session.Start(command)
 	if err != nil {
-		term.EndlnWithTime(time.Since(start), false)
+		task.End(err)
 		return fmt.Errorf("starting the session: %w", err)
 	}
 	scanner := bufio.NewScanner(stdout)
@@ -309,7 +322,7 @@ func (c *Client) ExecuteInteractively(command string, inputMap map[string]string
 	for scanner.Scan() {
 		b := scanner.Text()
 		if b == "\n" {
-			term.Infoln(replacer.Replace(line.String()))
+			task.Line(term.StreamStdout, replacer.Replace(line.String()))
 			line.Reset()
 		}
 		line.WriteString(b)
@@ -321,14 +334,15 @@ func (c *Client) ExecuteInteractively(command string, inputMap map[string]string
 		}
 	}
 	if err = scanner.Err(); err != nil {
-		term.Errorln(err)
+		task.Line(term.StreamError, err.Error())
+		task.End(err)
 		return err
 	}
 	if err = session.Wait(); err != nil {
-		term.EndlnWithTime(time.Since(start), false)
+		task.End(err)
 		return fmt.Errorf("session wait: %w", err)
 	}
-	term.EndlnWithTime(time.Since(start), true)
+	task.End(nil)
 	return nil
 }
 
@@ -352,9 +366,8 @@ func (c *Client) uploadByReader(r io.Reader, remotePath string, size int64, perm
 	}
 	go func() {
 		pb := progressbar.DefaultBytes(size, "Uploading")
-		teeReader := io.TeeReader(r, pb)
 		_, _ = fmt.Fprintln(w, "C"+permission, size, path.Base(remotePath))
-		if _, err := io.Copy(w, teeReader); err != nil {
+		if _, err := io.Copy(w, progressbar.NewReader(r, pb)); err != nil {
 			term.Errorln(fmt.Errorf("failed to copy io: %w", err))
 		}
 		_, _ = fmt.Fprintln(w, "\x00")
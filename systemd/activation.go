@@ -0,0 +1,82 @@
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrNotSupported is returned by Files and Listeners on platforms
+// without socket activation.
+var ErrNotSupported = errors.New("systemd: not supported on this platform")
+
+// listenFDsStart is the first file descriptor passed by systemd,
+// SD_LISTEN_FDS_START.
+const listenFDsStart = 3
+
+// Files returns the file descriptors passed by socket activation, named
+// after $LISTEN_FDNAMES (FileDescriptorName= of the socket unit, or
+// "unknown"). It returns none when $LISTEN_PID is not this process. Like
+// sd_listen_fds(3) with unset_environment set, it clears the LISTEN_*
+// variables so child processes do not take the descriptors as theirs, so
+// only the first call returns them.
+func Files() ([]*os.File, error) {
+	pid, fds, names := os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS"), os.Getenv("LISTEN_FDNAMES")
+	for _, key := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+		_ = os.Unsetenv(key)
+	}
+	if pid != strconv.Itoa(os.Getpid()) || fds == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("systemd: invalid LISTEN_FDS %q", fds)
+	}
+	var fdNames []string
+	if names != "" {
+		fdNames = strings.Split(names, ":")
+	}
+	files := make([]*os.File, 0, n)
+	for i := range n {
+		name := "unknown"
+		if i < len(fdNames) {
+			name = fdNames[i]
+		}
+		f, err := listenFile(listenFDsStart+i, name)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// Listeners returns the sockets passed by socket activation, in the order
+// of the socket unit's Listen directives, as listeners for net/http and
+// the like. Every passed descriptor must be a listening stream socket;
+// use Files for datagram sockets and FIFOs.
+func Listeners() ([]net.Listener, error) {
+	files, err := Files()
+	if err != nil {
+		return nil, err
+	}
+	listeners := make([]net.Listener, 0, len(files))
+	for i, f := range files {
+		l, err := net.FileListener(f)
+		_ = f.Close()
+		if err != nil {
+			for _, f := range files[i+1:] {
+				_ = f.Close()
+			}
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+			return nil, fmt.Errorf("systemd: %s is not a listening socket: %w", f.Name(), err)
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
@@ -0,0 +1,7 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package systemd
+
+import "os"
+
+func listenFile(int, string) (*os.File, error) { return nil, ErrNotSupported }
@@ -0,0 +1,77 @@
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestListenersHelper runs in the child process started by
+// TestListeners, which passes it a socket as fd 3 the way systemd does.
+func TestListenersHelper(t *testing.T) {
+	if os.Getenv("SYSTEMD_TEST_LISTENERS") == "" {
+		t.Skip("helper process for TestListeners")
+	}
+	// systemd sets LISTEN_PID to the pid it forks; the parent cannot.
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	listeners, err := Listeners()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var addrs []string
+	for _, l := range listeners {
+		addrs = append(addrs, l.Addr().String())
+	}
+	fmt.Printf("listeners=%s\n", strings.Join(addrs, ","))
+	if os.Getenv("LISTEN_FDS") != "" {
+		t.Error("LISTEN_FDS was not cleared")
+	}
+}
+
+func TestListeners(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no socket activation on Windows")
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = l.Close() }()
+	tcp, ok := l.(*net.TCPListener)
+	if !ok {
+		t.Fatalf("listener is %T", l)
+	}
+	f, err := tcp.File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestListenersHelper$", "-test.v") // #nosec G204 -- re-runs this test binary
+	cmd.Env = append(os.Environ(), "SYSTEMD_TEST_LISTENERS=1", "LISTEN_FDS=1", "LISTEN_FDNAMES=web")
+	cmd.ExtraFiles = []*os.File{f}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("helper: %v\n%s", err, out)
+	}
+	if want := "listeners=" + l.Addr().String() + "\n"; !strings.Contains(string(out), want) {
+		t.Errorf("helper output lacks %q:\n%s", want, out)
+	}
+}
+
+func TestFilesOtherProcess(t *testing.T) {
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "2")
+	files, err := Files()
+	if err != nil || len(files) != 0 {
+		t.Errorf("Files for another pid = %v, %v", files, err)
+	}
+	if os.Getenv("LISTEN_FDS") != "" {
+		t.Error("LISTEN_FDS was not cleared")
+	}
+}
@@ -0,0 +1,13 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package systemd
+
+import (
+	"os"
+	"syscall"
+)
+
+func listenFile(fd int, name string) (*os.File, error) {
+	syscall.CloseOnExec(fd)
+	return os.NewFile(uintptr(fd), name), nil
+}
@@ -0,0 +1,68 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package systemd
+
+import (
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+// TestListenersNotSocketHelper runs in the child process started by
+// TestListenersNotSocket, which passes it a socket, a pipe and another
+// socket as fds 3 to 5.
+func TestListenersNotSocketHelper(t *testing.T) {
+	if os.Getenv("SYSTEMD_TEST_LISTENERS_NOT_SOCKET") == "" {
+		t.Skip("helper process for TestListenersNotSocket")
+	}
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	if listeners, err := Listeners(); err == nil {
+		t.Fatalf("Listeners with a pipe = %v, want an error", listeners)
+	}
+	for fd := listenFDsStart; fd < listenFDsStart+3; fd++ {
+		var st syscall.Stat_t
+		if err := syscall.Fstat(fd, &st); !errors.Is(err, syscall.EBADF) {
+			t.Errorf("fd %d is still open after the error: %v", fd, err)
+		}
+	}
+}
+
+func TestListenersNotSocket(t *testing.T) {
+	files := make([]*os.File, 0, 3)
+	socket := func() {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = l.Close() }()
+		tcp, ok := l.(*net.TCPListener)
+		if !ok {
+			t.Fatalf("listener is %T", l)
+		}
+		f, err := tcp.File()
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = f.Close() })
+		files = append(files, f)
+	}
+	socket()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = r.Close(); _ = w.Close() })
+	files = append(files, r)
+	socket()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestListenersNotSocketHelper$", "-test.v") // #nosec G204 -- re-runs this test binary
+	cmd.Env = append(os.Environ(), "SYSTEMD_TEST_LISTENERS_NOT_SOCKET=1", "LISTEN_FDS=3")
+	cmd.ExtraFiles = files
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("helper: %v\n%s", err, out)
+	}
+}
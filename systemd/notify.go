@@ -0,0 +1,96 @@
+package systemd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// States for Notify, as defined by sd_notify(3).
+const (
+	// Ready tells systemd that startup is finished; a Type=notify
+	// service stays "activating" until it is sent.
+	Ready = "READY=1"
+	// Reloading tells systemd the service is reloading its
+	// configuration. Send Ready when it is done.
+	Reloading = "RELOADING=1"
+	// Stopping tells systemd the service is shutting down.
+	Stopping = "STOPPING=1"
+	// WatchdogPing resets the watchdog timer of a unit with WatchdogSec=.
+	WatchdogPing = "WATCHDOG=1"
+)
+
+// Status returns the STATUS= state for Notify, a one-line message that
+// systemctl status shows for the service.
+func Status(format string, args ...any) string {
+	return "STATUS=" + strings.ReplaceAll(fmt.Sprintf(format, args...), "\n", " ")
+}
+
+// Notify sends states, such as Ready or Status("serving"), to the
+// service manager over $NOTIFY_SOCKET, which may name an abstract socket
+// with a leading @. It reports false with no error when the socket is
+// unset, as it is when the process was not started by systemd, so
+// daemons can call it unconditionally.
+func Notify(states ...string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	// The net package maps a leading @ to the abstract namespace.
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("systemd: notify: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+	if _, err := conn.Write([]byte(strings.Join(states, "\n"))); err != nil {
+		return false, fmt.Errorf("systemd: notify: %w", err)
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns the WatchdogSec= of the service, read from
+// $WATCHDOG_USEC. It returns 0 when the watchdog is off or
+// $WATCHDOG_PID names another process.
+func WatchdogInterval() (time.Duration, error) {
+	usec := os.Getenv("WATCHDOG_USEC")
+	if usec == "" {
+		return 0, nil
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(usec, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("systemd: invalid WATCHDOG_USEC %q", usec)
+	}
+	return time.Duration(n) * time.Microsecond, nil
+}
+
+// Watchdog pings the service manager at half the watchdog interval until
+// ctx is done, so systemd restarts the service if it hangs. It returns
+// nil at once when the watchdog is off, nil when ctx is done, and the
+// error when a ping fails. Run it in its own goroutine:
+//
+//	go func() { _ = systemd.Watchdog(ctx) }()
+func Watchdog(ctx context.Context) error {
+	interval, err := WatchdogInterval()
+	if err != nil || interval == 0 {
+		return err
+	}
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	for {
+		if _, err := Notify(WatchdogPing); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
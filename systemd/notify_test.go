@@ -0,0 +1,110 @@
+package systemd
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// notifySocket listens on a datagram socket at name and points
+// $NOTIFY_SOCKET at it.
+func notifySocket(t *testing.T, name string) *net.UnixConn {
+	t.Helper()
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: name, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", name)
+	return conn
+}
+
+func readDatagram(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf[:n])
+}
+
+func TestNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if sent, err := Notify(Ready); sent || err != nil {
+		t.Errorf("Notify without a socket = %v, %v", sent, err)
+	}
+
+	conn := notifySocket(t, filepath.Join(t.TempDir(), "notify"))
+	sent, err := Notify(Ready, Status("serving %d\nrequests", 3))
+	if !sent || err != nil {
+		t.Fatalf("Notify = %v, %v", sent, err)
+	}
+	if got, want := readDatagram(t, conn), "READY=1\nSTATUS=serving 3 requests"; got != want {
+		t.Errorf("datagram = %q, want %q", got, want)
+	}
+}
+
+func TestNotifyAbstract(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("abstract sockets are Linux only")
+	}
+	conn := notifySocket(t, "@x-systemd-test-"+strconv.Itoa(time.Now().Nanosecond()))
+	if _, err := Notify(Stopping); err != nil {
+		t.Fatal(err)
+	}
+	if got := readDatagram(t, conn); got != Stopping {
+		t.Errorf("datagram = %q, want %q", got, Stopping)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	pid := strconv.Itoa(0x7fffffff)
+	tests := []struct {
+		usec, pid string
+		want      time.Duration
+		wantErr   bool
+	}{
+		{"", "", 0, false},
+		{"30000000", "", 30 * time.Second, false},
+		{"30000000", pid, 0, false},
+		{"soon", "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Setenv("WATCHDOG_USEC", tt.usec)
+		t.Setenv("WATCHDOG_PID", tt.pid)
+		got, err := WatchdogInterval()
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("WatchdogInterval(%q, %q) = %v, %v", tt.usec, tt.pid, got, err)
+		}
+	}
+}
+
+func TestWatchdog(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "")
+	if err := Watchdog(context.Background()); err != nil {
+		t.Errorf("Watchdog when off = %v", err)
+	}
+
+	conn := notifySocket(t, filepath.Join(t.TempDir(), "notify"))
+	t.Setenv("WATCHDOG_USEC", "20000")
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- Watchdog(ctx) }()
+	for range 3 {
+		if got := readDatagram(t, conn); got != WatchdogPing {
+			t.Errorf("datagram = %q, want %q", got, WatchdogPing)
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Watchdog = %v", err)
+	}
+}
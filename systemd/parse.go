@@ -0,0 +1,353 @@
+package systemd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrSyntax is returned by Parse for input that is not a valid unit file.
+var ErrSyntax = errors.New("systemd: syntax error")
+
+// Parse reads a unit file or drop-in in systemd's INI dialect. Comments
+// start with # or ;, a line ending in a backslash continues on the next,
+// and repeated section headers add to the same section. For a list, each
+// assignment adds to it and an empty assignment clears it, except for
+// dependencies such as After=, which systemd does not let be reset; for
+// anything else the last assignment wins. Values are kept as written, so
+// specifiers such as %h and quoting in Exec lines survive a round trip;
+// only Environment= is split into its VAR=value words.
+//
+// Keys without a field go to the section's Extra and unknown sections to
+// Sections. The returned unit has no Name; ParseFile sets it from the
+// file name.
+func Parse(r io.Reader) (*UnitFile, error) {
+	u := &UnitFile{}
+	p := parser{u: u}
+	sc := bufio.NewScanner(r)
+	var cont strings.Builder
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if cont.Len() == 0 {
+			p.line = n
+		}
+		// Comments are dropped even in the middle of a continuation.
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if s, ok := strings.CutSuffix(line, `\`); ok {
+			cont.WriteString(strings.TrimSpace(s))
+			cont.WriteString(" ")
+			continue
+		}
+		cont.WriteString(line)
+		line = strings.TrimSpace(cont.String())
+		cont.Reset()
+		if err := p.parseLine(line); err != nil {
+			return nil, err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if cont.Len() > 0 {
+		if err := p.parseLine(strings.TrimSpace(cont.String())); err != nil {
+			return nil, err
+		}
+	}
+	return u, nil
+}
+
+// ParseFile parses the unit file filename and names the unit after it.
+func ParseFile(filename string) (*UnitFile, error) {
+	f, err := os.Open(filename) // #nosec G304 -- caller-chosen unit path
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	u, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	u.Name = filepath.Base(filename)
+	return u, nil
+}
+
+type parser struct {
+	u       *UnitFile
+	line    int
+	section string
+	value   reflect.Value // the typed section, if any
+	other   *Section      // the untyped section, if any
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: line %d: %s", ErrSyntax, p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) parseLine(line string) error {
+	if line == "" {
+		return nil
+	}
+	if strings.HasPrefix(line, "[") {
+		name, ok := strings.CutSuffix(line, "]")
+		if !ok || len(name) < 2 {
+			return p.errorf("bad section header %q", line)
+		}
+		p.startSection(name[1:])
+		return nil
+	}
+	key, value, ok := strings.Cut(line, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return p.errorf("expected Key=Value, got %q", line)
+	}
+	if p.section == "" {
+		return p.errorf("%s= outside of a section", key)
+	}
+	return p.assign(key, strings.TrimSpace(value))
+}
+
+func (p *parser) startSection(name string) {
+	p.section = name
+	p.value = reflect.Value{}
+	p.other = nil
+	u := p.u
+	switch name {
+	case "Unit":
+		p.value = reflect.ValueOf(&u.Unit).Elem()
+	case "Install":
+		p.value = reflect.ValueOf(&u.Install).Elem()
+	case "Service":
+		if u.Service == nil {
+			u.Service = &ServiceSection{}
+		}
+		p.value = reflect.ValueOf(u.Service).Elem()
+	case "Socket":
+		if u.Socket == nil {
+			u.Socket = &SocketSection{}
+		}
+		p.value = reflect.ValueOf(u.Socket).Elem()
+	case "Timer":
+		if u.Timer == nil {
+			u.Timer = &TimerSection{}
+		}
+		p.value = reflect.ValueOf(u.Timer).Elem()
+	case "Path":
+		if u.Path == nil {
+			u.Path = &PathSection{}
+		}
+		p.value = reflect.ValueOf(u.Path).Elem()
+	default:
+		i := slices.IndexFunc(u.Sections, func(s Section) bool { return s.Name == name })
+		if i < 0 {
+			u.Sections = append(u.Sections, Section{Name: name})
+			i = len(u.Sections) - 1
+		}
+		p.other = &u.Sections[i]
+	}
+}
+
+func (p *parser) assign(key, value string) error {
+	if p.other != nil {
+		p.other.Directives = append(p.other.Directives, Directive{key, value})
+		return nil
+	}
+	fs := fields(p.value.Type())
+	i := slices.IndexFunc(fs, func(f field) bool { return f.key == key })
+	if i < 0 {
+		extra := p.value.FieldByName("Extra")
+		extra.Set(reflect.Append(extra, reflect.ValueOf(Directive{key, value})))
+		return nil
+	}
+	f := fs[i]
+	fv := p.value.Field(f.index)
+	if value == "" {
+		// systemd ignores an empty dependency list rather than reset it.
+		if !f.noReset {
+			fv.SetZero()
+		}
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return p.errorf("%s=%s: not a number", key, value)
+		}
+		fv.SetInt(int64(n))
+	case reflect.Pointer:
+		b, ok := parseBool(value)
+		if !ok {
+			return p.errorf("%s=%s: not a boolean", key, value)
+		}
+		fv.Set(reflect.ValueOf(Bool(b)))
+	case reflect.Slice:
+		var values []string
+		switch f.kind {
+		case "words":
+			values = strings.Fields(value)
+		case "env":
+			var err error
+			if values, err = splitWords(value); err != nil {
+				return p.errorf("%s=%s: %v", key, value, err)
+			}
+		default:
+			values = []string{value}
+		}
+		fv.Set(reflect.AppendSlice(fv, reflect.ValueOf(values)))
+	}
+	return nil
+}
+
+func parseBool(s string) (bool, bool) {
+	switch strings.ToLower(s) {
+	case "1", "yes", "y", "true", "t", "on":
+		return true, true
+	case "0", "no", "n", "false", "f", "off":
+		return false, true
+	}
+	return false, false
+}
+
+// splitWords splits s at whitespace, removing the double or single quotes
+// around words and undoing the backslash escapes quoteWord writes.
+func splitWords(s string) ([]string, error) {
+	var words []string
+	var b strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			switch r {
+			case 't':
+				r = '\t'
+			case 'n':
+				r = '\n'
+			}
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				b.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, b.String())
+				b.Reset()
+				inWord = false
+			}
+		default:
+			b.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, errors.New("unterminated quote or escape")
+	}
+	if inWord {
+		words = append(words, b.String())
+	}
+	return words, nil
+}
+
+// Change is a directive that differs between two units. Old is empty for
+// an added directive and New for a removed one; a list holds one entry
+// per assignment.
+type Change struct {
+	Section string
+	Key     string
+	Old     []string
+	New     []string
+}
+
+func (c Change) String() string {
+	switch {
+	case len(c.Old) == 0:
+		return fmt.Sprintf("+[%s] %s=%s", c.Section, c.Key, strings.Join(c.New, " "))
+	case len(c.New) == 0:
+		return fmt.Sprintf("-[%s] %s=%s", c.Section, c.Key, strings.Join(c.Old, " "))
+	}
+	return fmt.Sprintf("~[%s] %s=%s -> %s", c.Section, c.Key, strings.Join(c.Old, " "), strings.Join(c.New, " "))
+}
+
+// Diff reports the directives that differ between a and b, in the order
+// they are written. Units are compared as parsed, so comments,
+// continuation lines and spellings such as true for yes are not changes.
+// A nil unit is empty: Diff(nil, b) lists everything b sets.
+func Diff(a, b *UnitFile) []Change {
+	old, oldOrder := a.assignments()
+	cur, curOrder := b.assignments()
+	var changes []Change
+	for _, sk := range mergeOrder(oldOrder, curOrder) {
+		o, n := old[sk], cur[sk]
+		if !slices.Equal(o, n) {
+			changes = append(changes, Change{Section: sk.section, Key: sk.key, Old: o, New: n})
+		}
+	}
+	return changes
+}
+
+type sectionKey struct{ section, key string }
+
+// assignments groups the directives of u by section and key.
+func (u *UnitFile) assignments() (map[sectionKey][]string, []sectionKey) {
+	values := map[sectionKey][]string{}
+	var order []sectionKey
+	add := func(section string, ds []Directive) {
+		for _, d := range ds {
+			sk := sectionKey{section, d.Key}
+			if _, ok := values[sk]; !ok {
+				order = append(order, sk)
+			}
+			values[sk] = append(values[sk], d.Value)
+		}
+	}
+	if u == nil {
+		return values, order
+	}
+	for _, s := range u.sections() {
+		add(s.name, directives(s.value, false))
+	}
+	for _, s := range u.Sections {
+		add(s.Name, s.Directives)
+	}
+	return values, order
+}
+
+// mergeOrder returns a followed by the keys of b missing from a, each
+// inserted after the last key of its section.
+func mergeOrder(a, b []sectionKey) []sectionKey {
+	out := slices.Clone(a)
+	for _, sk := range b {
+		if slices.Contains(out, sk) {
+			continue
+		}
+		i := len(out)
+		for j := len(out) - 1; j >= 0; j-- {
+			if out[j].section == sk.section {
+				i = j + 1
+				break
+			}
+		}
+		out = slices.Insert(out, i, sk)
+	}
+	return out
+}
@@ -0,0 +1,209 @@
+package systemd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	in := `# Installed by hand.
+[Unit]
+Description=API server
+After=network.target
+After=postgresql.service redis.service
+Documentation=man:api(8)
+
+[Service]
+Type=notify
+; the empty assignment drops the packaged command
+ExecStart=/usr/bin/api-old
+ExecStart=
+ExecStart=/usr/bin/api \
+    --config %h/.config/api.yaml \
+# a comment inside a continuation
+    --listen :8080
+Environment=A=1 "GREETING=hello world" 'QUOTE=say "hi"'
+Environment=PATH=/usr/bin
+NoNewPrivileges=true
+Restart = on-failure
+Restart=always
+OOMScoreAdjust=-100
+
+[X-Deploy]
+Revision=abc
+
+[Install]
+WantedBy=multi-user.target
+
+[X-Deploy]
+Revision=def
+`
+	u, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Unit.Description != "API server" {
+		t.Errorf("Description = %q", u.Unit.Description)
+	}
+	if want := []string{"network.target", "postgresql.service", "redis.service"}; !slices.Equal(u.Unit.After, want) {
+		t.Errorf("After = %q, want %q", u.Unit.After, want)
+	}
+	if u.Service == nil || u.Socket != nil {
+		t.Fatalf("sections = %+v", u)
+	}
+	s := u.Service
+	if want := []string{"/usr/bin/api --config %h/.config/api.yaml --listen :8080"}; !slices.Equal(s.ExecStart, want) {
+		t.Errorf("ExecStart = %q, want %q", s.ExecStart, want)
+	}
+	if want := []string{"A=1", "GREETING=hello world", `QUOTE=say "hi"`, "PATH=/usr/bin"}; !slices.Equal(s.Environment, want) {
+		t.Errorf("Environment = %q, want %q", s.Environment, want)
+	}
+	if s.NoNewPrivileges == nil || !*s.NoNewPrivileges || s.Restart != "always" || s.Type != "notify" {
+		t.Errorf("Service = %+v", s)
+	}
+	if want := []Directive{{"OOMScoreAdjust", "-100"}}; !slices.Equal(s.Extra, want) {
+		t.Errorf("Extra = %v", s.Extra)
+	}
+	if len(u.Sections) != 1 || len(u.Sections[0].Directives) != 2 {
+		t.Errorf("Sections = %+v", u.Sections)
+	}
+
+	// The model round-trips: rendering and parsing again is stable.
+	back, err := Parse(strings.NewReader(u.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.String() != u.String() {
+		t.Errorf("round trip =\n%s\nwant\n%s", back, u)
+	}
+	if changes := Diff(u, back); len(changes) != 0 {
+		t.Errorf("Diff after round trip = %v", changes)
+	}
+}
+
+func TestParseEmptyDependency(t *testing.T) {
+	base := "[Unit]\nAfter=network.target\nWants=redis.service\n\n[Install]\nWantedBy=multi-user.target\n"
+	// systemd ignores empty assignments of dependency lists.
+	reset := base + "[Unit]\nAfter=\nWants=\n\n[Install]\nWantedBy=\n"
+	a, err := Parse(strings.NewReader(base))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Parse(strings.NewReader(reset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(b.Unit.After, []string{"network.target"}) || !slices.Equal(b.Install.WantedBy, []string{"multi-user.target"}) {
+		t.Errorf("After = %q, WantedBy = %q after empty assignments", b.Unit.After, b.Install.WantedBy)
+	}
+	if changes := Diff(a, b); len(changes) != 0 {
+		t.Errorf("Diff = %v, want none", changes)
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	u := NewUnit("backup.timer")
+	u.Unit.Description = "Nightly backup"
+	u.Timer.OnCalendar = []string{"*-*-* 02:00:00", "Sat *-*-* 12:00:00"}
+	u.Timer.Persistent = Bool(false)
+	u.Install.WantedBy = []string{"timers.target"}
+
+	svc := NewUnit("api.service")
+	svc.Service.ExecStart = []string{Command("/bin/sh", "-c", `echo "50%" > $HOME/out`)}
+	svc.Service.Environment = []string{"EMPTY=", `TAB=a	b`, `BACKSLASH=C:\tmp`}
+	svc.Socket = &SocketSection{Backlog: 16}
+
+	for _, want := range []*UnitFile{u, svc} {
+		got, err := Parse(strings.NewReader(want.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got.Name = want.Name
+		if got.String() != want.String() {
+			t.Errorf("%s: round trip =\n%s\nwant\n%s", want.Name, got, want)
+		}
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "api.service")
+	if err := os.WriteFile(filename, []byte("[Service]\nExecStart=/usr/bin/api\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	u, err := ParseFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Name != "api.service" || u.Type() != "service" || len(u.Service.ExecStart) != 1 {
+		t.Errorf("ParseFile = %+v", u)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{
+		"ExecStart=/bin/true\n",
+		"[Service\n",
+		"[]\n",
+		"[Service]\nno equals sign\n",
+		"[Service]\n=value\n",
+		"[Socket]\nBacklog=lots\n",
+		"[Service]\nPrivateTmp=maybe\n",
+		"[Service]\nEnvironment=\"A=1\n",
+	} {
+		if _, err := Parse(strings.NewReader(in)); !errors.Is(err, ErrSyntax) {
+			t.Errorf("Parse(%q) err = %v, want ErrSyntax", in, err)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	installed, err := Parse(strings.NewReader(`[Unit]
+Description=API
+After=network.target
+
+[Service]
+ExecStart=/usr/bin/api
+Restart=always
+PrivateTmp=true
+Environment=A=1
+
+[Install]
+WantedBy=multi-user.target
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := NewUnit("api.service")
+	next.Unit.Description = "API"
+	next.Unit.After = []string{"network.target"}
+	next.Unit.Wants = []string{"network-online.target"}
+	next.Service.ExecStart = []string{"/usr/bin/api --listen :8080"}
+	next.Service.Restart = "always"
+	next.Service.PrivateTmp = Bool(true)
+	next.Service.Environment = []string{"A=1", "B=2"}
+	next.Install.WantedBy = []string{"multi-user.target"}
+	next.Sections = []Section{{Name: "X-Deploy", Directives: []Directive{{"Revision", "abc"}}}}
+
+	var got []string
+	for _, c := range Diff(installed, next) {
+		got = append(got, c.String())
+	}
+	want := []string{
+		"+[Unit] Wants=network-online.target",
+		"~[Service] ExecStart=/usr/bin/api -> /usr/bin/api --listen :8080",
+		"~[Service] Environment=A=1 -> A=1 B=2",
+		"+[X-Deploy] Revision=abc",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Diff =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	removed := Diff(next, nil)
+	if len(removed) != 9 || len(removed[0].New) != 0 {
+		t.Errorf("Diff(next, nil) = %v", removed)
+	}
+}
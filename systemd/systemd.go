@@ -1,19 +1,31 @@
 package systemd
 
 import (
-	"html/template"
 	"os"
+	"strconv"
+	"strings"
+	"text/template"
 )
 
 // serviceTemplate emits a single systemd unit file. The User= line
 // is conditional so user units (which run as the invoking user by
-// definition) can omit it cleanly.
+// definition) can omit it cleanly, and so are After= and Requires=,
+// since an empty assignment resets the list rather than leaving it
+// unset. It is a text/template: unit files are not HTML, and escaping
+// would turn the & of a shell command into &amp;.
 var serviceTemplate = `[Unit]
 Description={{ .Name }}
+{{- if .After }}
 After={{ .After }}
+{{- end }}
+{{- if .Requires }}
 Requires={{ .Requires }}
+{{- end }}
 
 [Service]
+{{- if .Type }}
+Type={{ .Type }}
+{{- end }}
 {{- if .User }}
 User={{ .User }}
 {{- end }}
@@ -21,6 +33,9 @@ TimeoutStartSec={{ .TimeoutStartSec }}
 ExecStart={{ .ExecStart }}
 Restart={{ .Restart }}
 RestartSec={{ .RestartSec }}
+{{- if .WatchdogSec }}
+WatchdogSec={{ .WatchdogSec }}
+{{- end }}
 
 [Install]
 WantedBy={{ .WantedBy }}
@@ -46,6 +61,8 @@ type Service struct {
 	TimeoutStartSec int
 	Restart         string
 	RestartSec      int
+	Type            string // empty => systemd's default, simple; "notify" waits for Notify(Ready)
+	WatchdogSec     int    // 0 => no watchdog; otherwise the service must run Watchdog
 	WantedBy        string // "multi-user.target" for system; "default.target" for user
 }
 
@@ -89,3 +106,25 @@ func (s *Service) ToFile(filename string) error {
 	defer func() { _ = f.Close() }()
 	return tmpl.Execute(f, s)
 }
+
+// UnitFile converts s to the typed model, for drop-ins or for settings
+// the fixed template lacks. The unit is named after s.Name.
+func (s *Service) UnitFile() *UnitFile {
+	u := NewUnit(s.Name + ".service")
+	u.Unit.Description = s.Name
+	u.Unit.After = strings.Fields(s.After)
+	u.Unit.Requires = strings.Fields(s.Requires)
+	u.Service.User = s.User
+	u.Service.TimeoutStartSec = strconv.Itoa(s.TimeoutStartSec)
+	if s.ExecStart != "" {
+		u.Service.ExecStart = []string{s.ExecStart}
+	}
+	u.Service.Restart = s.Restart
+	u.Service.RestartSec = strconv.Itoa(s.RestartSec)
+	u.Service.Type = s.Type
+	if s.WatchdogSec > 0 {
+		u.Service.WatchdogSec = strconv.Itoa(s.WatchdogSec)
+	}
+	u.Install.WantedBy = strings.Fields(s.WantedBy)
+	return u
+}
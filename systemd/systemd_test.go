@@ -368,3 +368,60 @@ func TestServiceToFileMultipleServices(t *testing.T) {
 		}
 	}
 }
+
+func TestServiceToFilePlainText(t *testing.T) {
+	s := NewService("shell", `/bin/sh -c "make build && ./run <input >output"`)
+	filename := filepath.Join(t.TempDir(), "shell.service")
+	if err := s.ToFile(filename); err != nil {
+		t.Fatalf("ToFile: %v", err)
+	}
+	body, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	got := string(body)
+	if !strings.Contains(got, `ExecStart=/bin/sh -c "make build && ./run <input >output"`) {
+		t.Errorf("ExecStart was escaped; got:\n%s", got)
+	}
+	if strings.Contains(got, "Requires=") || strings.Contains(got, "After=") {
+		t.Errorf("empty Requires= or After= emitted; got:\n%s", got)
+	}
+}
+
+func TestServiceUnitFile(t *testing.T) {
+	s := NewService("api", "/usr/bin/api")
+	s.After = "network.target postgresql.service"
+	u := s.UnitFile()
+	if u.Name != "api.service" || u.Service.User != "root" || len(u.Unit.After) != 2 {
+		t.Errorf("UnitFile = %+v", u)
+	}
+	if got := u.String(); !strings.Contains(got, "ExecStart=/usr/bin/api\n") || !strings.Contains(got, "WantedBy=multi-user.target\n") {
+		t.Errorf("UnitFile().String() =\n%s", got)
+	}
+}
+
+func TestServiceNotifyWatchdog(t *testing.T) {
+	s := NewService("api", "/usr/bin/api")
+	s.Type = "notify"
+	s.WatchdogSec = 30
+	filename := filepath.Join(t.TempDir(), "api.service")
+	if err := s.ToFile(filename); err != nil {
+		t.Fatalf("ToFile: %v", err)
+	}
+	body, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	for _, want := range []string{"[Service]\nType=notify\n", "\nWatchdogSec=30\n"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("unit lacks %q:\n%s", want, body)
+		}
+	}
+	u := s.UnitFile()
+	if u.Service.Type != "notify" || u.Service.WatchdogSec != "30" {
+		t.Errorf("UnitFile().Service = %+v", u.Service)
+	}
+	if NewService("api", "/usr/bin/api").UnitFile().Service.WatchdogSec != "" {
+		t.Error("WatchdogSec set without a watchdog")
+	}
+}
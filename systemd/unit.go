@@ -0,0 +1,503 @@
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidValue is returned when rendering a directive whose value
+// cannot be written on one line of a unit file.
+var ErrInvalidValue = errors.New("systemd: invalid directive value")
+
+// Directive is one Key=Value assignment.
+type Directive struct {
+	Key   string
+	Value string
+}
+
+func (d Directive) String() string { return d.Key + "=" + d.Value }
+
+// Section is a section with no typed model, such as [X-Vendor], kept in
+// order.
+type Section struct {
+	Name       string
+	Directives []Directive
+}
+
+// Fields of the section types carry a `systemd:"Key,kind"` tag. The key
+// defaults to the field name. For []string fields the kind says how the
+// list is written: "lines" writes one directive per entry, "words" joins
+// the entries with spaces on one line, and "env" writes one quoted
+// VAR=value per line. A "noreset" flag marks lists that cannot be
+// emptied with "Key=", such as the dependencies; in a drop-in they add to
+// those of the unit. Extra holds directives without a field.
+
+// UnitSection is the [Unit] section shared by every unit type.
+type UnitSection struct {
+	Description           string
+	Documentation         []string `systemd:",words"`
+	Requires              []string `systemd:",words,noreset"`
+	Requisite             []string `systemd:",words,noreset"`
+	Wants                 []string `systemd:",words,noreset"`
+	BindsTo               []string `systemd:",words,noreset"`
+	PartOf                []string `systemd:",words,noreset"`
+	Conflicts             []string `systemd:",words,noreset"`
+	Before                []string `systemd:",words,noreset"`
+	After                 []string `systemd:",words,noreset"`
+	OnFailure             []string `systemd:",words,noreset"`
+	OnSuccess             []string `systemd:",words,noreset"`
+	DefaultDependencies   *bool
+	RefuseManualStart     *bool
+	RefuseManualStop      *bool
+	StartLimitIntervalSec string
+	StartLimitBurst       int
+	ConditionPathExists   []string `systemd:",lines"`
+	AssertPathExists      []string `systemd:",lines"`
+	Extra                 []Directive
+}
+
+// ServiceSection is the [Service] section of a .service unit.
+type ServiceSection struct {
+	Type                     string
+	RemainAfterExit          *bool
+	PIDFile                  string
+	BusName                  string
+	ExecCondition            []string `systemd:",lines"`
+	ExecStartPre             []string `systemd:",lines"`
+	ExecStart                []string `systemd:",lines"`
+	ExecStartPost            []string `systemd:",lines"`
+	ExecReload               []string `systemd:",lines"`
+	ExecStop                 []string `systemd:",lines"`
+	ExecStopPost             []string `systemd:",lines"`
+	Restart                  string
+	RestartSec               string
+	TimeoutStartSec          string
+	TimeoutStopSec           string
+	RuntimeMaxSec            string
+	WatchdogSec              string
+	NotifyAccess             string
+	SuccessExitStatus        []string `systemd:",words"`
+	RestartPreventExitStatus []string `systemd:",words"`
+	User                     string
+	Group                    string
+	SupplementaryGroups      []string `systemd:",words"`
+	DynamicUser              *bool
+	WorkingDirectory         string
+	RootDirectory            string
+	Environment              []string `systemd:",env"`
+	EnvironmentFile          []string `systemd:",lines"`
+	UMask                    string
+	StandardInput            string
+	StandardOutput           string
+	StandardError            string
+	SyslogIdentifier         string
+	KillMode                 string
+	KillSignal               string
+	LimitNOFILE              string
+	CPUQuota                 string
+	MemoryMax                string
+	NoNewPrivileges          *bool
+	PrivateTmp               *bool
+	ProtectSystem            string
+	ProtectHome              string
+	ReadWritePaths           []string `systemd:",words"`
+	CapabilityBoundingSet    []string `systemd:",words"`
+	AmbientCapabilities      []string `systemd:",words"`
+	RuntimeDirectory         []string `systemd:",words"`
+	StateDirectory           []string `systemd:",words"`
+	CacheDirectory           []string `systemd:",words"`
+	LogsDirectory            []string `systemd:",words"`
+	ConfigurationDirectory   []string `systemd:",words"`
+	Extra                    []Directive
+}
+
+// TimerSection is the [Timer] section of a .timer unit.
+type TimerSection struct {
+	OnActiveSec        []string `systemd:",lines"`
+	OnBootSec          []string `systemd:",lines"`
+	OnStartupSec       []string `systemd:",lines"`
+	OnUnitActiveSec    []string `systemd:",lines"`
+	OnUnitInactiveSec  []string `systemd:",lines"`
+	OnCalendar         []string `systemd:",lines"`
+	AccuracySec        string
+	RandomizedDelaySec string
+	FixedRandomDelay   *bool
+	OnClockChange      *bool
+	OnTimezoneChange   *bool
+	Persistent         *bool
+	WakeSystem         *bool
+	RemainAfterElapse  *bool
+	Unit               string
+	Extra              []Directive
+}
+
+// SocketSection is the [Socket] section of a .socket unit.
+type SocketSection struct {
+	ListenStream           []string `systemd:",lines"`
+	ListenDatagram         []string `systemd:",lines"`
+	ListenSequentialPacket []string `systemd:",lines"`
+	ListenFIFO             []string `systemd:",lines"`
+	ListenSpecial          []string `systemd:",lines"`
+	Accept                 *bool
+	Service                string
+	BindIPv6Only           string
+	Backlog                int
+	SocketUser             string
+	SocketGroup            string
+	SocketMode             string
+	DirectoryMode          string
+	FileDescriptorName     string
+	ReusePort              *bool
+	NoDelay                *bool
+	KeepAlive              *bool
+	RemoveOnStop           *bool
+	Extra                  []Directive
+}
+
+// PathSection is the [Path] section of a .path unit.
+type PathSection struct {
+	PathExists              []string `systemd:",lines"`
+	PathExistsGlob          []string `systemd:",lines"`
+	PathChanged             []string `systemd:",lines"`
+	PathModified            []string `systemd:",lines"`
+	DirectoryNotEmpty       []string `systemd:",lines"`
+	Unit                    string
+	MakeDirectory           *bool
+	DirectoryMode           string
+	TriggerLimitIntervalSec string
+	TriggerLimitBurst       int
+	Extra                   []Directive
+}
+
+// InstallSection is the [Install] section read by systemctl enable.
+type InstallSection struct {
+	Alias           []string `systemd:",words,noreset"`
+	WantedBy        []string `systemd:",words,noreset"`
+	RequiredBy      []string `systemd:",words,noreset"`
+	Also            []string `systemd:",words,noreset"`
+	DefaultInstance string
+	Extra           []Directive
+}
+
+// UnitFile is a typed systemd unit. Name carries the unit type as its
+// suffix: a .service has Service set, a .timer Timer, a .socket Socket, a
+// .path Path, and a .target only [Unit] and [Install].
+type UnitFile struct {
+	Name     string
+	Unit     UnitSection
+	Service  *ServiceSection
+	Socket   *SocketSection
+	Timer    *TimerSection
+	Path     *PathSection
+	Install  InstallSection
+	Sections []Section
+}
+
+// NewUnit returns an empty unit named name, with the section for its type
+// allocated.
+func NewUnit(name string) *UnitFile {
+	u := &UnitFile{Name: name}
+	switch u.Type() {
+	case "service":
+		u.Service = &ServiceSection{}
+	case "socket":
+		u.Socket = &SocketSection{}
+	case "timer":
+		u.Timer = &TimerSection{}
+	case "path":
+		u.Path = &PathSection{}
+	}
+	return u
+}
+
+// Type returns the unit type from Name's suffix, such as "service".
+func (u *UnitFile) Type() string {
+	return strings.TrimPrefix(path.Ext(u.Name), ".")
+}
+
+// Bool returns a pointer to v, for the optional yes/no directives.
+func Bool(v bool) *bool { return &v }
+
+// sections returns the unit's sections in the order they are written.
+func (u *UnitFile) sections() []namedSection {
+	out := []namedSection{{"Unit", reflect.ValueOf(&u.Unit).Elem()}}
+	if u.Service != nil {
+		out = append(out, namedSection{"Service", reflect.ValueOf(u.Service).Elem()})
+	}
+	if u.Socket != nil {
+		out = append(out, namedSection{"Socket", reflect.ValueOf(u.Socket).Elem()})
+	}
+	if u.Timer != nil {
+		out = append(out, namedSection{"Timer", reflect.ValueOf(u.Timer).Elem()})
+	}
+	if u.Path != nil {
+		out = append(out, namedSection{"Path", reflect.ValueOf(u.Path).Elem()})
+	}
+	return append(out, namedSection{"Install", reflect.ValueOf(&u.Install).Elem()})
+}
+
+type namedSection struct {
+	name  string
+	value reflect.Value
+}
+
+// field describes how one struct field maps to a directive.
+type field struct {
+	key     string
+	kind    string
+	noReset bool
+	index   int
+}
+
+func fields(t reflect.Type) []field {
+	var out []field
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if f.Name == "Extra" {
+			continue
+		}
+		key, opts, _ := strings.Cut(f.Tag.Get("systemd"), ",")
+		if key == "" {
+			key = f.Name
+		}
+		kind, flags, _ := strings.Cut(opts, ",")
+		out = append(out, field{key: key, kind: kind, noReset: flags == "noreset", index: i})
+	}
+	return out
+}
+
+// directives returns the assignments for a section struct. In a drop-in,
+// each list that systemd lets be reset is preceded by an empty assignment
+// so it replaces the list of the unit instead of adding to it.
+func directives(v reflect.Value, dropIn bool) []Directive {
+	var out []Directive
+	for _, f := range fields(v.Type()) {
+		fv := v.Field(f.index)
+		switch fv.Kind() {
+		case reflect.String:
+			if s := fv.String(); s != "" {
+				out = append(out, Directive{f.key, s})
+			}
+		case reflect.Int:
+			if n := fv.Int(); n != 0 {
+				out = append(out, Directive{f.key, strconv.FormatInt(n, 10)})
+			}
+		case reflect.Pointer:
+			if !fv.IsNil() {
+				out = append(out, Directive{f.key, formatBool(fv.Elem().Bool())})
+			}
+		case reflect.Slice:
+			if fv.Len() == 0 {
+				continue
+			}
+			if dropIn && !f.noReset {
+				out = append(out, Directive{f.key, ""})
+			}
+			values := make([]string, fv.Len())
+			for i := range values {
+				values[i] = fv.Index(i).String()
+			}
+			switch f.kind {
+			case "words":
+				out = append(out, Directive{f.key, strings.Join(values, " ")})
+			case "env":
+				for _, s := range values {
+					out = append(out, Directive{f.key, quoteWord(s)})
+				}
+			default:
+				for _, s := range values {
+					out = append(out, Directive{f.key, s})
+				}
+			}
+		}
+	}
+	if extra, ok := v.FieldByName("Extra").Interface().([]Directive); ok {
+		out = append(out, extra...)
+	}
+	return out
+}
+
+func formatBool(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// String renders the unit file. Invalid values are written as they are;
+// use WriteTo to have them reported.
+func (u *UnitFile) String() string {
+	var b strings.Builder
+	_ = u.render(&b, false)
+	return b.String()
+}
+
+// WriteTo writes the unit file to w. It fails with ErrInvalidValue for a
+// value containing a newline or ending in a backslash, which would run
+// into the next line.
+func (u *UnitFile) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	if err := u.render(&b, false); err != nil {
+		return 0, err
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// ToFile writes the unit file to filename.
+func (u *UnitFile) ToFile(filename string) error {
+	return writeFile(filename, func(w io.Writer) error {
+		_, err := u.WriteTo(w)
+		return err
+	})
+}
+
+// DropIn renders u as a drop-in to place in DropInPath. Only the
+// directives set in u are written. Lists such as ExecStart= and
+// Environment= are reset first so they replace those of the installed
+// unit; dependencies such as After= and WantedBy= cannot be reset by a
+// drop-in, so they add to those of the unit.
+func (u *UnitFile) DropIn() (string, error) {
+	var b strings.Builder
+	if err := u.render(&b, true); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// DropInPath returns where a drop-in for u goes under the unit directory
+// dir: dir/<name>.d/override.conf, the file systemctl edit creates.
+func (u *UnitFile) DropInPath(dir string) string {
+	return filepath.Join(dir, u.Name+".d", "override.conf")
+}
+
+// WriteDropIn writes DropIn to DropInPath(dir), creating the .d
+// directory.
+func (u *UnitFile) WriteDropIn(dir string) error {
+	s, err := u.DropIn()
+	if err != nil {
+		return err
+	}
+	filename := u.DropInPath(dir)
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil { // #nosec G301 -- unit directories are world-readable
+		return err
+	}
+	return writeFile(filename, func(w io.Writer) error {
+		_, err := io.WriteString(w, s)
+		return err
+	})
+}
+
+func writeFile(filename string, write func(io.Writer) error) error {
+	f, err := os.Create(filename) // #nosec G304 -- caller-chosen unit path
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (u *UnitFile) render(b *strings.Builder, dropIn bool) error {
+	var bad error
+	section := func(name string, ds []Directive) {
+		if len(ds) == 0 {
+			return
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(b, "[%s]\n", name)
+		for _, d := range ds {
+			if bad == nil && (strings.ContainsAny(d.Value, "\r\n") || strings.HasSuffix(d.Value, `\`)) {
+				bad = fmt.Errorf("%w: %s=%q", ErrInvalidValue, d.Key, d.Value)
+			}
+			b.WriteString(d.String())
+			b.WriteString("\n")
+		}
+	}
+	for _, s := range u.sections() {
+		section(s.name, directives(s.value, dropIn))
+	}
+	for _, s := range u.Sections {
+		section(s.Name, s.Directives)
+	}
+	return bad
+}
+
+// Command quotes args as one command line for ExecStart= and the other
+// Exec directives, so each argument reaches the program exactly as given:
+// whitespace, quotes and backslashes are quoted, and the % of specifiers
+// and the $ of variable expansion are escaped.
+func Command(args ...string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		a = strings.NewReplacer("%", "%%", "$", "$$").Replace(a)
+		quoted[i] = quoteWord(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// EscapeSpecifiers escapes % so systemd does not expand specifiers such
+// as %h or %n in s.
+func EscapeSpecifiers(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
+
+// quoteWord double-quotes s if it is empty or contains characters that
+// would split or alter it.
+func quoteWord(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\;") {
+		return s
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// FormatTimespan formats d as a systemd time span, such as "1min 30s".
+// Zero is "0".
+func FormatTimespan(d time.Duration) string {
+	if d == 0 {
+		return "0"
+	}
+	units := []struct {
+		suffix string
+		d      time.Duration
+	}{
+		{"d", 24 * time.Hour}, {"h", time.Hour}, {"min", time.Minute}, {"s", time.Second},
+		{"ms", time.Millisecond}, {"us", time.Microsecond},
+	}
+	var parts []string
+	for _, u := range units {
+		if n := d / u.d; n > 0 {
+			parts = append(parts, strconv.FormatInt(int64(n), 10)+u.suffix)
+			d -= n * u.d
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,180 @@
+package systemd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnitFileService(t *testing.T) {
+	u := NewUnit("api.service")
+	u.Unit.Description = "API server"
+	u.Unit.After = []string{"network-online.target", "postgresql.service"}
+	u.Unit.Wants = []string{"network-online.target"}
+	u.Service.Type = "simple"
+	u.Service.ExecStartPre = []string{"/usr/bin/api migrate", "/usr/bin/api check"}
+	u.Service.ExecStart = []string{Command("/usr/bin/api", "--listen", ":8080", "--motd", `50% "off" $today`)}
+	u.Service.Environment = []string{"GOMAXPROCS=2", "GREETING=hello world"}
+	u.Service.NoNewPrivileges = Bool(true)
+	u.Service.PrivateTmp = Bool(false)
+	u.Service.Restart = "on-failure"
+	u.Service.RestartSec = FormatTimespan(1500 * time.Millisecond)
+	u.Service.Extra = []Directive{{"OOMScoreAdjust", "-100"}}
+	u.Install.WantedBy = []string{"multi-user.target"}
+	u.Sections = []Section{{Name: "X-Deploy", Directives: []Directive{{"Revision", "abc123"}}}}
+
+	want := `[Unit]
+Description=API server
+Wants=network-online.target
+After=network-online.target postgresql.service
+
+[Service]
+Type=simple
+ExecStartPre=/usr/bin/api migrate
+ExecStartPre=/usr/bin/api check
+ExecStart=/usr/bin/api --listen :8080 --motd "50%% \"off\" $$today"
+Restart=on-failure
+RestartSec=1s 500ms
+Environment=GOMAXPROCS=2
+Environment="GREETING=hello world"
+NoNewPrivileges=yes
+PrivateTmp=no
+OOMScoreAdjust=-100
+
+[Install]
+WantedBy=multi-user.target
+
+[X-Deploy]
+Revision=abc123
+`
+	if got := u.String(); got != want {
+		t.Errorf("String =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUnitFileTypes(t *testing.T) {
+	timer := NewUnit("backup.timer")
+	timer.Unit.Description = "Nightly backup"
+	timer.Timer.OnCalendar = []string{"*-*-* 02:00:00", "Sat *-*-* 12:00:00"}
+	timer.Timer.RandomizedDelaySec = "15min"
+	timer.Timer.Persistent = Bool(true)
+	timer.Install.WantedBy = []string{"timers.target"}
+
+	socket := NewUnit("api.socket")
+	socket.Socket.ListenStream = []string{"8080", "/run/api.sock"}
+	socket.Socket.SocketMode = "0660"
+	socket.Socket.Backlog = 128
+
+	watch := NewUnit("inbox.path")
+	watch.Path.DirectoryNotEmpty = []string{"/var/spool/inbox"}
+	watch.Path.MakeDirectory = Bool(true)
+
+	target := NewUnit("app.target")
+	target.Unit.Wants = []string{"api.service", "worker.service"}
+
+	tests := []struct {
+		u    *UnitFile
+		typ  string
+		want string
+	}{
+		{timer, "timer", "[Unit]\nDescription=Nightly backup\n\n[Timer]\nOnCalendar=*-*-* 02:00:00\nOnCalendar=Sat *-*-* 12:00:00\nRandomizedDelaySec=15min\nPersistent=yes\n\n[Install]\nWantedBy=timers.target\n"},
+		{socket, "socket", "[Socket]\nListenStream=8080\nListenStream=/run/api.sock\nBacklog=128\nSocketMode=0660\n"},
+		{watch, "path", "[Path]\nDirectoryNotEmpty=/var/spool/inbox\nMakeDirectory=yes\n"},
+		{target, "target", "[Unit]\nWants=api.service worker.service\n"},
+	}
+	for _, tt := range tests {
+		if typ := tt.u.Type(); typ != tt.typ {
+			t.Errorf("%s: Type = %q, want %q", tt.u.Name, typ, tt.typ)
+		}
+		if got := tt.u.String(); got != tt.want {
+			t.Errorf("%s: String =\n%s\nwant\n%s", tt.u.Name, got, tt.want)
+		}
+	}
+}
+
+func TestUnitFileDropIn(t *testing.T) {
+	u := NewUnit("api.service")
+	u.Unit.After = []string{"redis.service"}
+	u.Service.ExecStart = []string{"/usr/bin/api --verbose"}
+	u.Service.Environment = []string{"DEBUG=1"}
+	u.Service.MemoryMax = "512M"
+	u.Install.WantedBy = []string{"multi-user.target"}
+
+	got, err := u.DropIn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[Unit]\nAfter=redis.service\n\n[Service]\nExecStart=\nExecStart=/usr/bin/api --verbose\nEnvironment=\nEnvironment=DEBUG=1\nMemoryMax=512M\n\n[Install]\nWantedBy=multi-user.target\n"
+	if got != want {
+		t.Errorf("DropIn =\n%s\nwant\n%s", got, want)
+	}
+	// systemd cannot reset dependencies from a drop-in.
+	for _, reset := range []string{"\nAfter=\n", "\nWantedBy=\n"} {
+		if strings.Contains(got, reset) {
+			t.Errorf("DropIn resets a dependency list with %q", strings.TrimSpace(reset))
+		}
+	}
+
+	dir := t.TempDir()
+	if p := u.DropInPath(dir); p != filepath.Join(dir, "api.service.d", "override.conf") {
+		t.Errorf("DropInPath = %q", p)
+	}
+	if err := u.WriteDropIn(dir); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(u.DropInPath(dir))
+	if err != nil || string(b) != want {
+		t.Errorf("written drop-in = %q, %v", b, err)
+	}
+}
+
+func TestUnitFileInvalidValue(t *testing.T) {
+	for _, value := range []string{"/bin/a\n/bin/b", `/bin/a \`} {
+		u := NewUnit("bad.service")
+		u.Service.ExecStart = []string{value}
+		if _, err := u.WriteTo(&strings.Builder{}); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("WriteTo(%q) err = %v, want ErrInvalidValue", value, err)
+		}
+		if err := u.ToFile(filepath.Join(t.TempDir(), u.Name)); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("ToFile(%q) err = %v, want ErrInvalidValue", value, err)
+		}
+	}
+}
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"/bin/echo", "hi"}, "/bin/echo hi"},
+		{[]string{"/bin/sh", "-c", "echo a && echo b"}, `/bin/sh -c "echo a && echo b"`},
+		{[]string{"/bin/echo", ""}, `/bin/echo ""`},
+		{[]string{"/bin/echo", `C:\tmp`}, `/bin/echo "C:\\tmp"`},
+		{[]string{"/bin/echo", "%h", "$HOME"}, "/bin/echo %%h $$HOME"},
+		{[]string{"/bin/echo", "it's"}, `/bin/echo "it's"`},
+	}
+	for _, tt := range tests {
+		if got := Command(tt.args...); got != tt.want {
+			t.Errorf("Command(%q) = %s, want %s", tt.args, got, tt.want)
+		}
+	}
+	if got := EscapeSpecifiers("100%"); got != "100%%" {
+		t.Errorf("EscapeSpecifiers = %q", got)
+	}
+}
+
+func TestFormatTimespan(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                                   "0",
+		3 * time.Second:                     "3s",
+		90 * time.Second:                    "1min 30s",
+		26*time.Hour + 250*time.Millisecond: "1d 2h 250ms",
+	} {
+		if got := FormatTimespan(d); got != want {
+			t.Errorf("FormatTimespan(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+//go:build !windows
+
+package term
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrNoCapability is returned by CopyToClipboard when the terminal is not
+// known to support OSC 52.
+var ErrNoCapability = errors.New("term: terminal lacks the capability")
+
+// Capabilities are the OSC features a terminal is known to support.
+// Terminals silently ignore sequences they do not understand, but some
+// print them, so each feature is only used once it has been detected.
+type Capabilities struct {
+	// Hyperlinks is OSC 8 clickable links.
+	Hyperlinks bool
+	// Title is OSC 0/2 window and tab titles.
+	Title bool
+	// Clipboard is OSC 52 clipboard copy, which also works over SSH.
+	Clipboard bool
+	// Notify is OSC 9 desktop notifications.
+	Notify bool
+}
+
+// DetectCapabilities reports what w supports. A writer that is not a
+// terminal supports nothing. Otherwise the answer comes from TERM,
+// TERM_PROGRAM and the variables terminals set to identify themselves;
+// FORCE_HYPERLINK=1 or 0 overrides hyperlink detection.
+func DetectCapabilities(w io.Writer) Capabilities {
+	f, ok := w.(*os.File)
+	return capabilitiesFromEnv(os.Getenv, ok && IsTerminal(int(f.Fd())))
+}
+
+func capabilitiesFromEnv(getenv func(string) string, tty bool) Capabilities {
+	termEnv := strings.ToLower(getenv("TERM"))
+	if !tty || termEnv == "dumb" {
+		return Capabilities{}
+	}
+	program := getenv("TERM_PROGRAM")
+	termIs := func(names ...string) bool {
+		for _, n := range names {
+			if strings.Contains(termEnv, n) {
+				return true
+			}
+		}
+		return false
+	}
+	programIs := func(names ...string) bool {
+		for _, n := range names {
+			if strings.EqualFold(program, n) {
+				return true
+			}
+		}
+		return false
+	}
+	modern := programIs("iTerm.app", "WezTerm", "ghostty") ||
+		termIs("kitty", "wezterm", "ghostty") ||
+		getenv("KITTY_WINDOW_ID") != ""
+
+	var c Capabilities
+	c.Title = termEnv != "" && termEnv != "linux"
+	c.Hyperlinks = modern || programIs("vscode", "Hyper") ||
+		termIs("alacritty", "foot") ||
+		getenv("WT_SESSION") != "" ||
+		atoi(getenv("VTE_VERSION")) >= 5000
+	switch getenv("FORCE_HYPERLINK") {
+	case "1":
+		c.Hyperlinks = true
+	case "0":
+		c.Hyperlinks = false
+	}
+	c.Clipboard = modern || termIs("alacritty", "foot") ||
+		getenv("WT_SESSION") != "" || getenv("TMUX") != ""
+	c.Notify = modern || getenv("ConEmuANSI") == "ON"
+	return c
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// oscSafe drops control characters, which would end the sequence early or
+// smuggle in another one.
+func oscSafe(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// oscURI percent-encodes the bytes of s outside 32 to 126, the only ones
+// OSC 8 allows in a URI, so control characters cannot end the sequence
+// and non-ASCII addresses stay intact.
+func oscURI(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c > 0x7e {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// Hyperlink returns text as an OSC 8 link to url, regardless of what the
+// terminal supports. Use Link when the destination is not known to be
+// capable.
+func Hyperlink(url, text string) string {
+	return Osc + "8;;" + oscURI(url) + St + text + Osc + "8;;" + St
+}
+
+// Link returns text linked to url when w supports hyperlinks. Otherwise
+// it returns "text (url)", or just url when text is empty or the same, so
+// the address is never lost.
+func Link(w io.Writer, url, text string) string {
+	if DetectCapabilities(w).Hyperlinks {
+		if text == "" {
+			text = url
+		}
+		return Hyperlink(url, text)
+	}
+	if text == "" || text == url {
+		return url
+	}
+	return text + " (" + url + ")"
+}
+
+// SetTitle sets the window and tab title of w, doing nothing when w is not
+// a terminal that supports titles.
+func SetTitle(w io.Writer, title string) error {
+	if !DetectCapabilities(w).Title {
+		return nil
+	}
+	_, err := io.WriteString(w, Osc+"0;"+oscSafe(title)+Bel)
+	return err
+}
+
+// CopyToClipboard places data on the system clipboard through the terminal
+// with OSC 52, which works across SSH and tmux. It returns ErrNoCapability
+// when w is not known to support it, so the caller can print data instead.
+func CopyToClipboard(w io.Writer, data []byte) error {
+	if !DetectCapabilities(w).Clipboard {
+		return ErrNoCapability
+	}
+	_, err := io.WriteString(w, Osc+"52;c;"+base64.StdEncoding.EncodeToString(data)+Bel)
+	return err
+}
+
+// Notify shows message as a desktop notification with OSC 9. Terminals
+// without notifications get a bell instead, and writers that are not
+// terminals get nothing.
+func Notify(w io.Writer, message string) error {
+	c := DetectCapabilities(w)
+	switch {
+	case c.Notify:
+		_, err := io.WriteString(w, Osc+"9;"+oscSafe(message)+Bel)
+		return err
+	case c.Title:
+		_, err := io.WriteString(w, Bel)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+//go:build !windows
+
+package term
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestCapabilitiesFromEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		tty  bool
+		want Capabilities
+	}{
+		{"not a tty", map[string]string{"TERM_PROGRAM": "iTerm.app"}, false, Capabilities{}},
+		{"dumb", map[string]string{"TERM": "dumb", "TERM_PROGRAM": "iTerm.app"}, true, Capabilities{}},
+		{"linux console", map[string]string{"TERM": "linux"}, true, Capabilities{}},
+		{"plain xterm", map[string]string{"TERM": "xterm-256color"}, true, Capabilities{Title: true}},
+		{"iterm", map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "iTerm.app"}, true, Capabilities{true, true, true, true}},
+		{"kitty", map[string]string{"TERM": "xterm-kitty"}, true, Capabilities{true, true, true, true}},
+		{"vte", map[string]string{"TERM": "xterm-256color", "VTE_VERSION": "6003"}, true, Capabilities{Hyperlinks: true, Title: true}},
+		{"old vte", map[string]string{"TERM": "xterm-256color", "VTE_VERSION": "4800"}, true, Capabilities{Title: true}},
+		{"tmux", map[string]string{"TERM": "tmux-256color", "TMUX": "/tmp/tmux-1/default,1,0"}, true, Capabilities{Title: true, Clipboard: true}},
+		{"forced links", map[string]string{"TERM": "xterm", "FORCE_HYPERLINK": "1"}, true, Capabilities{Hyperlinks: true, Title: true}},
+		{"links off", map[string]string{"TERM": "xterm-kitty", "FORCE_HYPERLINK": "0"}, true, Capabilities{Title: true, Clipboard: true, Notify: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := capabilitiesFromEnv(func(k string) string { return tt.env[k] }, tt.tty)
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHyperlink(t *testing.T) {
+	got := Hyperlink("https://example.com/a\x1b]b", "docs")
+	want := "\x1b]8;;https://example.com/a%1B]b\x1b\\docs\x1b]8;;\x1b\\"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := Hyperlink("https://example.com/café", "x"), "\x1b]8;;https://example.com/caf%C3%A9\x1b\\x\x1b]8;;\x1b\\"; got != want {
+		t.Errorf("non-ASCII URI: got %q, want %q", got, want)
+	}
+	if w := StringWidth(got); w != 4 {
+		t.Errorf("StringWidth = %d, want 4", w)
+	}
+}
+
+func TestOSCFallbacks(t *testing.T) {
+	var buf bytes.Buffer
+	if got := Link(&buf, "gs://bucket/key", "object"); got != "object (gs://bucket/key)" {
+		t.Errorf("Link = %q", got)
+	}
+	if got := Link(&buf, "https://x.test", ""); got != "https://x.test" {
+		t.Errorf("Link without text = %q", got)
+	}
+	if err := SetTitle(&buf, "build"); err != nil {
+		t.Errorf("SetTitle: %v", err)
+	}
+	if err := Notify(&buf, "done"); err != nil {
+		t.Errorf("Notify: %v", err)
+	}
+	if err := CopyToClipboard(&buf, []byte("secret")); !errors.Is(err, ErrNoCapability) {
+		t.Errorf("CopyToClipboard err = %v, want ErrNoCapability", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("wrote %q to a non-terminal", buf.String())
+	}
+}
@@ -0,0 +1,78 @@
+//go:build !windows
+
+package term
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Profile is the color capability of an output.
+type Profile int
+
+const (
+	// NoColor means plain text: no colors and no attributes.
+	NoColor Profile = iota
+	// ANSI is the 16 color palette.
+	ANSI
+	// ANSI256 is the xterm 256 color palette.
+	ANSI256
+	// TrueColor is 24-bit RGB.
+	TrueColor
+)
+
+func (p Profile) String() string {
+	switch p {
+	case ANSI:
+		return "ansi"
+	case ANSI256:
+		return "ansi256"
+	case TrueColor:
+		return "truecolor"
+	default:
+		return "none"
+	}
+}
+
+// DetectProfile reports the color profile of w from the environment:
+//
+//   - NO_COLOR set to anything non-empty disables color.
+//   - CLICOLOR_FORCE set to anything but "0" enables color even when w is
+//     not a terminal.
+//   - Otherwise w must be a terminal, and CLICOLOR=0 or TERM=dumb disable
+//     color.
+//   - COLORTERM=truecolor or 24bit, or a TERM naming truecolor, 24bit or
+//     direct, selects TrueColor; a TERM containing 256color selects
+//     ANSI256; anything else gets ANSI.
+func DetectProfile(w io.Writer) Profile {
+	f, ok := w.(*os.File)
+	return profileFromEnv(os.Getenv, ok && IsTerminal(int(f.Fd())))
+}
+
+func profileFromEnv(getenv func(string) string, tty bool) Profile {
+	if getenv("NO_COLOR") != "" {
+		return NoColor
+	}
+	termEnv := strings.ToLower(getenv("TERM"))
+	force := getenv("CLICOLOR_FORCE")
+	if force == "" || force == "0" {
+		if !tty || getenv("CLICOLOR") == "0" || termEnv == "dumb" {
+			return NoColor
+		}
+	}
+	switch colorTerm := strings.ToLower(getenv("COLORTERM")); {
+	case colorTerm == "truecolor" || colorTerm == "24bit":
+		return TrueColor
+	case strings.Contains(termEnv, "truecolor") || strings.Contains(termEnv, "24bit") || strings.Contains(termEnv, "direct"):
+		return TrueColor
+	case strings.Contains(termEnv, "256color"):
+		return ANSI256
+	}
+	return ANSI
+}
+
+// stdoutProfile is the profile of os.Stdout, detected once, used by the
+// TermColor helpers that print to stdout.
+var stdoutProfile = sync.OnceValue(func() Profile { return DetectProfile(os.Stdout) })
@@ -0,0 +1,512 @@
+//go:build !windows
+
+package term
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoAnswer is returned when a prompt cannot ask (input is not a
+// terminal or the Prompter is non-interactive) and no answer or default
+// is available.
+var ErrNoAnswer = errors.New("term: no answer in non-interactive mode")
+
+// PrompterOption configures a Prompter.
+type PrompterOption func(*Prompter)
+
+// WithPromptInput sets the file prompts read from. Defaults to stdin.
+func WithPromptInput(f *os.File) PrompterOption {
+	return func(p *Prompter) { p.in = f }
+}
+
+// WithPromptOutput sets where prompts are written. Defaults to stderr so
+// stdout stays clean for a command's real output.
+func WithPromptOutput(w io.Writer) PrompterOption {
+	return func(p *Prompter) { p.out = w }
+}
+
+// WithAnswers supplies answers keyed by prompt key. They take precedence
+// over flags, the environment and asking.
+func WithAnswers(answers map[string]string) PrompterOption {
+	return func(p *Prompter) { p.answers = answers }
+}
+
+// WithFlags answers a prompt from the flag named like its key, when that
+// flag was set on the command line.
+func WithFlags(fs *flag.FlagSet) PrompterOption {
+	return func(p *Prompter) { p.flags = fs }
+}
+
+// WithEnvPrefix answers a prompt from the environment variable named
+// prefix + key, upper-cased with non-alphanumerics turned into
+// underscores: with prefix "LOOM_", key "ssh-user" reads LOOM_SSH_USER.
+func WithEnvPrefix(prefix string) PrompterOption {
+	return func(p *Prompter) { p.envPrefix = prefix }
+}
+
+// WithNonInteractive never asks, even on a terminal. Prompts without an
+// answer fall back to their default or fail with ErrNoAnswer.
+func WithNonInteractive() PrompterOption {
+	return func(p *Prompter) { p.nonInteractive = true }
+}
+
+// Prompter asks questions on a terminal. Every prompt has a key; before
+// asking, the Prompter looks for an answer under that key in WithAnswers,
+// then WithFlags, then WithEnvPrefix, so the same flow runs unattended in
+// CI.
+type Prompter struct {
+	in             *os.File
+	out            io.Writer
+	answers        map[string]string
+	flags          *flag.FlagSet
+	envPrefix      string
+	nonInteractive bool
+}
+
+// NewPrompter returns a Prompter reading stdin and writing stderr.
+func NewPrompter(opts ...PrompterOption) *Prompter {
+	p := &Prompter{in: os.Stdin, out: os.Stderr}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// PromptOption configures a single prompt.
+type PromptOption func(*promptConfig)
+
+type promptConfig struct {
+	defaults []string
+	validate func(string) error
+}
+
+// WithDefault sets the answer used when the user just presses enter, or
+// when nobody can be asked. Input and Select use the first value;
+// MultiSelect preselects all of them.
+func WithDefault(values ...string) PromptOption {
+	return func(c *promptConfig) { c.defaults = values }
+}
+
+// WithValidator rejects Input answers for which fn returns an error. The
+// error is shown and the question asked again; answers from flags or the
+// environment fail the prompt instead.
+func WithValidator(fn func(string) error) PromptOption {
+	return func(c *promptConfig) { c.validate = fn }
+}
+
+func newPromptConfig(opts []PromptOption) *promptConfig {
+	c := &promptConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *promptConfig) def() (string, bool) {
+	if len(c.defaults) == 0 {
+		return "", false
+	}
+	return c.defaults[0], true
+}
+
+func (c *promptConfig) check(v string) error {
+	if c.validate == nil {
+		return nil
+	}
+	return c.validate(v)
+}
+
+// Input asks for a line of text.
+func (p *Prompter) Input(ctx context.Context, key, label string, opts ...PromptOption) (string, error) {
+	cfg := newPromptConfig(opts)
+	def, hasDef := cfg.def()
+	if v, ok := p.answer(key); ok {
+		if err := cfg.check(v); err != nil {
+			return "", fmt.Errorf("term: answer for %s: %w", key, err)
+		}
+		return v, nil
+	}
+	if !p.interactive() {
+		if hasDef {
+			return def, nil
+		}
+		return "", fmt.Errorf("%w: %s", ErrNoAnswer, key)
+	}
+	for {
+		if hasDef {
+			fmt.Fprintf(p.out, "%s [%s]: ", label, def)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", label)
+		}
+		line, err := readLineContext(ctx, int(p.in.Fd()))
+		if err != nil {
+			return "", p.readError(ctx, err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "" && hasDef {
+			line = def
+		}
+		if err := cfg.check(line); err != nil {
+			fmt.Fprintf(p.out, "%s\n", err)
+			continue
+		}
+		return line, nil
+	}
+}
+
+// Confirm asks a yes/no question.
+func (p *Prompter) Confirm(ctx context.Context, key, label string, def bool) (bool, error) {
+	if v, ok := p.answer(key); ok {
+		yes, valid := parseYesNo(v)
+		if !valid {
+			return false, fmt.Errorf("term: answer for %s: %q is not yes or no", key, v)
+		}
+		return yes, nil
+	}
+	if !p.interactive() {
+		return def, nil
+	}
+	choices := "Y/n"
+	if !def {
+		choices = "y/N"
+	}
+	for {
+		fmt.Fprintf(p.out, "%s (%s) ", label, choices)
+		line, err := readLineContext(ctx, int(p.in.Fd()))
+		if err != nil {
+			return false, p.readError(ctx, err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			return def, nil
+		}
+		if yes, valid := parseYesNo(line); valid {
+			return yes, nil
+		}
+	}
+}
+
+// Select asks for one of options using a menu navigated with the arrow
+// keys (or j/k) and confirmed with enter. An answer from flags or the
+// environment may be an option, matched case-insensitively, or its
+// 1-based position.
+func (p *Prompter) Select(ctx context.Context, key, label string, options []string, opts ...PromptOption) (string, error) {
+	if len(options) == 0 {
+		return "", errors.New("term: select without options")
+	}
+	cfg := newPromptConfig(opts)
+	if v, ok := p.answer(key); ok {
+		i, err := optionIndex(options, v)
+		if err != nil {
+			return "", fmt.Errorf("term: answer for %s: %w", key, err)
+		}
+		return options[i], nil
+	}
+	cursor := 0
+	if def, ok := cfg.def(); ok {
+		i, err := optionIndex(options, def)
+		if err != nil {
+			return "", fmt.Errorf("term: default for %s: %w", key, err)
+		}
+		cursor = i
+		if !p.interactive() {
+			return options[i], nil
+		}
+	}
+	if !p.interactive() {
+		return "", fmt.Errorf("%w: %s", ErrNoAnswer, key)
+	}
+	chosen, err := p.menu(ctx, label, options, cursor, nil)
+	if err != nil {
+		return "", err
+	}
+	return options[chosen[0]], nil
+}
+
+// MultiSelect asks for any number of options using a menu: arrows move,
+// space toggles, "a" toggles all and enter confirms. An answer from flags
+// or the environment is a comma-separated list of options or positions.
+func (p *Prompter) MultiSelect(ctx context.Context, key, label string, options []string, opts ...PromptOption) ([]string, error) {
+	if len(options) == 0 {
+		return nil, errors.New("term: multi-select without options")
+	}
+	cfg := newPromptConfig(opts)
+	pick := func(values []string) ([]string, error) {
+		out := make([]string, 0, len(values))
+		for _, v := range values {
+			if v = strings.TrimSpace(v); v == "" {
+				continue
+			}
+			i, err := optionIndex(options, v)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, options[i])
+		}
+		return out, nil
+	}
+	if v, ok := p.answer(key); ok {
+		out, err := pick(strings.Split(v, ","))
+		if err != nil {
+			return nil, fmt.Errorf("term: answer for %s: %w", key, err)
+		}
+		return out, nil
+	}
+	defaults, err := pick(cfg.defaults)
+	if err != nil {
+		return nil, fmt.Errorf("term: default for %s: %w", key, err)
+	}
+	if !p.interactive() {
+		if len(cfg.defaults) > 0 {
+			return defaults, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNoAnswer, key)
+	}
+	selected := make([]bool, len(options))
+	for _, d := range defaults {
+		i, _ := optionIndex(options, d)
+		selected[i] = true
+	}
+	chosen, err := p.menu(ctx, label, options, 0, selected)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(chosen))
+	for _, i := range chosen {
+		out = append(out, options[i])
+	}
+	return out, nil
+}
+
+// answer looks key up in the configured non-interactive sources.
+func (p *Prompter) answer(key string) (string, bool) {
+	if v, ok := p.answers[key]; ok {
+		return v, true
+	}
+	if p.flags != nil {
+		var value string
+		var set bool
+		p.flags.Visit(func(f *flag.Flag) {
+			if f.Name == key {
+				value, set = f.Value.String(), true
+			}
+		})
+		if set {
+			return value, true
+		}
+	}
+	if p.envPrefix != "" {
+		return os.LookupEnv(envName(p.envPrefix + key))
+	}
+	return "", false
+}
+
+func (p *Prompter) interactive() bool {
+	return !p.nonInteractive && IsTerminal(int(p.in.Fd()))
+}
+
+func (p *Prompter) readError(ctx context.Context, err error) error {
+	fmt.Fprintln(p.out)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return fmt.Errorf("term: read answer: %w", err)
+}
+
+// menu runs an interactive list in raw mode. selected is nil for a single
+// choice. It returns the chosen indexes in option order.
+func (p *Prompter) menu(ctx context.Context, label string, options []string, cursor int, selected []bool) ([]int, error) {
+	fd := int(p.in.Fd())
+	old, err := MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("term: raw mode: %w", err)
+	}
+	signals, stop := RestoreOnSignal(fd, old)
+	ctx, release := cancelOnSignal(ctx, signals)
+	defer func() {
+		release()
+		stop()
+		_ = Restore(fd, old)
+	}()
+
+	multi := selected != nil
+	profile := DetectProfile(p.out)
+	highlight := NewStyle().Foreground(ANSIColor(6)).Bold()
+	hint := "(arrows to move, enter to choose)"
+	if multi {
+		hint = "(arrows to move, space to toggle, a for all, enter to confirm)"
+	}
+	fmt.Fprintf(p.out, "%s %s\r\n", label, hint)
+
+	draw := func(redraw bool) {
+		var b strings.Builder
+		if redraw {
+			fmt.Fprintf(&b, "\x1b[%dA", len(options))
+		}
+		for i, o := range options {
+			b.WriteString("\r\x1b[K")
+			marker := "  "
+			if i == cursor {
+				marker = "> "
+			}
+			box := ""
+			if multi {
+				box = "[ ] "
+				if selected[i] {
+					box = "[x] "
+				}
+			}
+			line := marker + box + o
+			if i == cursor {
+				line = highlight.Render(profile, line)
+			}
+			b.WriteString(line)
+			b.WriteString("\r\n")
+		}
+		_, _ = io.WriteString(p.out, b.String())
+	}
+	draw(false)
+
+	for {
+		k, err := readKey(ctx, fd)
+		if err != nil {
+			return nil, interrupted(ctx, err)
+		}
+		switch k {
+		case keyUp:
+			cursor = (cursor - 1 + len(options)) % len(options)
+		case keyDown:
+			cursor = (cursor + 1) % len(options)
+		case keySpace:
+			if multi {
+				selected[cursor] = !selected[cursor]
+			}
+		case keyAll:
+			if multi {
+				all := true
+				for _, s := range selected {
+					all = all && s
+				}
+				for i := range selected {
+					selected[i] = !all
+				}
+			}
+		case keyInterrupt:
+			return nil, ErrInterrupted
+		case keyEnter:
+			var chosen []int
+			var names []string
+			for i := range options {
+				if (multi && selected[i]) || (!multi && i == cursor) {
+					chosen = append(chosen, i)
+					names = append(names, options[i])
+				}
+			}
+			// Replace the menu with a one-line summary of the answer.
+			fmt.Fprintf(p.out, "\x1b[%dA\r\x1b[J%s\r\n", len(options)+1, label+": "+strings.Join(names, ", "))
+			return chosen, nil
+		default:
+			continue
+		}
+		draw(true)
+	}
+}
+
+type key int
+
+const (
+	keyOther key = iota
+	keyUp
+	keyDown
+	keyEnter
+	keySpace
+	keyAll
+	keyInterrupt
+)
+
+// escapeWait is how long to wait for the rest of an escape sequence.
+const escapeWait = 50 * time.Millisecond
+
+// readKey reads one key press from a terminal in raw mode.
+func readKey(ctx context.Context, fd int) (key, error) {
+	b, err := readByteContext(ctx, fd)
+	if err != nil {
+		return keyOther, err
+	}
+	switch b {
+	case '\r', '\n':
+		return keyEnter, nil
+	case ' ':
+		return keySpace, nil
+	case 'a':
+		return keyAll, nil
+	case 'k':
+		return keyUp, nil
+	case 'j':
+		return keyDown, nil
+	case 3, 4: // Ctrl-C, Ctrl-D
+		return keyInterrupt, nil
+	case 0x1b:
+		seqCtx, cancel := context.WithTimeout(ctx, escapeWait)
+		defer cancel()
+		b1, err := readByteContext(seqCtx, fd)
+		if err != nil || (b1 != '[' && b1 != 'O') {
+			return keyOther, ctx.Err()
+		}
+		b2, err := readByteContext(seqCtx, fd)
+		if err != nil {
+			return keyOther, ctx.Err()
+		}
+		switch b2 {
+		case 'A':
+			return keyUp, nil
+		case 'B':
+			return keyDown, nil
+		}
+	}
+	return keyOther, nil
+}
+
+// optionIndex finds v among options by case-insensitive name or 1-based
+// position.
+func optionIndex(options []string, v string) (int, error) {
+	for i, o := range options {
+		if strings.EqualFold(o, v) {
+			return i, nil
+		}
+	}
+	if n, err := strconv.Atoi(v); err == nil && n >= 1 && n <= len(options) {
+		return n - 1, nil
+	}
+	return 0, fmt.Errorf("%q is not one of %s", v, strings.Join(options, ", "))
+}
+
+func parseYesNo(s string) (yes, valid bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "y", "yes", "true", "1":
+		return true, true
+	case "n", "no", "false", "0":
+		return false, true
+	}
+	return false, false
+}
+
+func envName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, s)
+}
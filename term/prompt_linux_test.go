@@ -0,0 +1,82 @@
+package term
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ptyPrompter returns a Prompter on the terminal side of a pty, and a
+// function that types keys on the controlling side. Everything the
+// prompter draws is drained so writes never block.
+func ptyPrompter(t *testing.T) (*Prompter, func(string)) {
+	t.Helper()
+	ptmx, tty := openPTY(t)
+	go func() { _, _ = io.Copy(io.Discard, ptmx) }()
+	p := NewPrompter(WithPromptInput(tty), WithPromptOutput(tty))
+	return p, func(keys string) {
+		// Give the prompt time to switch modes before typing.
+		time.Sleep(20 * time.Millisecond)
+		_, _ = ptmx.WriteString(keys)
+	}
+}
+
+func TestPrompterInputInteractive(t *testing.T) {
+	p, typeKeys := ptyPrompter(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go typeKeys("\n")
+	got, err := p.Input(ctx, "name", "Name", WithDefault("svc"))
+	if err != nil || got != "svc" {
+		t.Fatalf("enter for default = %q, %v", got, err)
+	}
+
+	notEmpty := func(s string) error {
+		if s == "" {
+			return io.ErrUnexpectedEOF
+		}
+		return nil
+	}
+	go typeKeys("\nvalue\n")
+	got, err = p.Input(ctx, "name", "Name", WithValidator(notEmpty))
+	if err != nil || got != "value" {
+		t.Fatalf("after failed validation = %q, %v", got, err)
+	}
+}
+
+func TestPrompterSelectInteractive(t *testing.T) {
+	p, typeKeys := ptyPrompter(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go typeKeys("\x1b[B\x1b[B\x1b[A\r")
+	got, err := p.Select(ctx, "size", "Size", []string{"s", "m", "l"})
+	if err != nil || got != "m" {
+		t.Fatalf("Select = %q, %v", got, err)
+	}
+
+	// b starts selected, so toggling every option leaves a and c.
+	go typeKeys(" j j \r")
+	multi, err := p.MultiSelect(ctx, "svc", "Services", []string{"a", "b", "c"}, WithDefault("b"))
+	if err != nil || strings.Join(multi, ",") != "a,c" {
+		t.Fatalf("MultiSelect = %v, %v", multi, err)
+	}
+
+	go typeKeys("\x03")
+	if _, err := p.Select(ctx, "size", "Size", []string{"s"}); !errors.Is(err, ErrInterrupted) {
+		t.Fatalf("Ctrl-C: err = %v", err)
+	}
+}
+
+func TestPrompterConfirmCanceled(t *testing.T) {
+	p, _ := ptyPrompter(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := p.Confirm(ctx, "go", "Go?", true); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want deadline exceeded", err)
+	}
+}
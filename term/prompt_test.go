@@ -0,0 +1,143 @@
+//go:build !windows
+
+package term
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPrompterAnswerSources(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("region", "unused-default", "")
+	fs.String("zone", "", "")
+	if err := fs.Parse([]string{"-region", "us-west1"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_SSH_USER", "deploy")
+
+	p := NewPrompter(WithNonInteractive(), WithFlags(fs), WithEnvPrefix("APP_"),
+		WithAnswers(map[string]string{"name": "svc"}))
+	ctx := context.Background()
+
+	tests := []struct{ key, want string }{
+		{"name", "svc"},
+		{"region", "us-west1"},
+		{"ssh-user", "deploy"},
+	}
+	for _, tt := range tests {
+		got, err := p.Input(ctx, tt.key, "label")
+		if err != nil || got != tt.want {
+			t.Errorf("Input(%s) = %q, %v; want %q", tt.key, got, err, tt.want)
+		}
+	}
+	// An unset flag is not an answer.
+	if _, err := p.Input(ctx, "zone", "Zone"); !errors.Is(err, ErrNoAnswer) {
+		t.Errorf("unset flag: err = %v, want ErrNoAnswer", err)
+	}
+	if got, err := p.Input(ctx, "zone", "Zone", WithDefault("a")); err != nil || got != "a" {
+		t.Errorf("default = %q, %v", got, err)
+	}
+}
+
+func TestPrompterValidatorOnAnswer(t *testing.T) {
+	p := NewPrompter(WithNonInteractive(), WithAnswers(map[string]string{"port": "http"}))
+	numeric := func(s string) error {
+		if strings.Trim(s, "0123456789") != "" {
+			return errors.New("must be a number")
+		}
+		return nil
+	}
+	if _, err := p.Input(context.Background(), "port", "Port", WithValidator(numeric)); err == nil {
+		t.Error("invalid answer accepted")
+	}
+}
+
+func TestPrompterSelectNonInteractive(t *testing.T) {
+	options := []string{"small", "Medium", "large"}
+	ctx := context.Background()
+	p := NewPrompter(WithNonInteractive(), WithAnswers(map[string]string{"size": "medium", "pos": "3", "bad": "huge"}))
+
+	if got, err := p.Select(ctx, "size", "Size", options); err != nil || got != "Medium" {
+		t.Errorf("by name = %q, %v", got, err)
+	}
+	if got, err := p.Select(ctx, "pos", "Size", options); err != nil || got != "large" {
+		t.Errorf("by position = %q, %v", got, err)
+	}
+	if _, err := p.Select(ctx, "bad", "Size", options); err == nil {
+		t.Error("unknown option accepted")
+	}
+	if got, err := p.Select(ctx, "none", "Size", options, WithDefault("small")); err != nil || got != "small" {
+		t.Errorf("default = %q, %v", got, err)
+	}
+	if _, err := p.Select(ctx, "none", "Size", options); !errors.Is(err, ErrNoAnswer) {
+		t.Errorf("no answer: err = %v", err)
+	}
+}
+
+func TestPrompterMultiSelectNonInteractive(t *testing.T) {
+	options := []string{"api", "worker", "cron"}
+	ctx := context.Background()
+	p := NewPrompter(WithNonInteractive(), WithAnswers(map[string]string{"svc": "cron, 1"}))
+	got, err := p.MultiSelect(ctx, "svc", "Services", options)
+	if err != nil || strings.Join(got, ",") != "cron,api" {
+		t.Errorf("MultiSelect = %v, %v", got, err)
+	}
+	got, err = p.MultiSelect(ctx, "other", "Services", options, WithDefault("worker", "api"))
+	if err != nil || strings.Join(got, ",") != "worker,api" {
+		t.Errorf("defaults = %v, %v", got, err)
+	}
+	if _, err := p.MultiSelect(ctx, "svc", "Services", nil); err == nil {
+		t.Error("MultiSelect without options succeeded")
+	}
+}
+
+func TestPrompterConfirmNonInteractive(t *testing.T) {
+	ctx := context.Background()
+	p := NewPrompter(WithNonInteractive(), WithAnswers(map[string]string{"go": "YES", "bad": "maybe"}))
+	if ok, err := p.Confirm(ctx, "go", "Go?", false); err != nil || !ok {
+		t.Errorf("Confirm = %v, %v", ok, err)
+	}
+	if _, err := p.Confirm(ctx, "bad", "Go?", false); err == nil {
+		t.Error("invalid yes/no accepted")
+	}
+	if ok, err := p.Confirm(ctx, "missing", "Go?", true); err != nil || !ok {
+		t.Errorf("default = %v, %v", ok, err)
+	}
+}
+
+func TestPrompterDoesNotAskWithoutTerminal(t *testing.T) {
+	var out bytes.Buffer
+	// A pipe is not a terminal, so the prompter must not block on it.
+	p := NewPrompter(WithPromptInput(pipeReader(t)), WithPromptOutput(&out))
+	if _, err := p.Input(context.Background(), "k", "Label"); !errors.Is(err, ErrNoAnswer) {
+		t.Errorf("err = %v, want ErrNoAnswer", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("prompt written without a terminal: %q", out.String())
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got := envName("loom_ssh-user.name"); got != "LOOM_SSH_USER_NAME" {
+		t.Errorf("envName = %q", got)
+	}
+}
+
+func pipeReader(t *testing.T) *os.File {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		r.Close()
+		w.Close()
+	})
+	return r
+}
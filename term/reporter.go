@@ -0,0 +1,283 @@
+//go:build !windows
+
+package term
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stream tells which output a task line came from.
+type Stream int
+
+const (
+	// StreamStdout is the task's standard output.
+	StreamStdout Stream = iota
+	// StreamStderr is the task's standard error.
+	StreamStderr
+	// StreamError is a message about the task itself, such as why it
+	// failed.
+	StreamError
+)
+
+func (s Stream) String() string {
+	switch s {
+	case StreamStderr:
+		return "stderr"
+	case StreamError:
+		return "error"
+	default:
+		return "stdout"
+	}
+}
+
+// Reporter presents the progress of tasks such as shell commands: when
+// they start and end, whether they succeeded and what they printed.
+// Implementations are safe for concurrent use.
+type Reporter interface {
+	// Start begins a top level task named by command and its args.
+	Start(command string, args ...string) Task
+}
+
+// Task is a running task returned by Reporter.Start or Task.Step.
+type Task interface {
+	// Line reports one line of output.
+	Line(stream Stream, text string)
+	// Step begins a sub-task, shown nested under this one.
+	Step(name string) Task
+	// End finishes the task; a nil err means it succeeded.
+	End(err error)
+}
+
+type taskKey struct{}
+
+// ContextWithTask returns a copy of ctx carrying t, so code further down
+// can report its work as steps of t.
+func ContextWithTask(ctx context.Context, t Task) context.Context {
+	return context.WithValue(ctx, taskKey{}, t)
+}
+
+// TaskFromContext returns the task stored by ContextWithTask, or nil.
+func TaskFromContext(ctx context.Context) Task {
+	t, _ := ctx.Value(taskKey{}).(Task)
+	return t
+}
+
+// StartTask begins a task for command: a step of the task in ctx if there
+// is one, otherwise a top level task of r, or of StdoutReporter when r is
+// nil.
+func StartTask(ctx context.Context, r Reporter, command string, args ...string) Task {
+	if parent := TaskFromContext(ctx); parent != nil {
+		return parent.Step(taskName(command, args))
+	}
+	if r == nil {
+		r = StdoutReporter()
+	}
+	return r.Start(command, args...)
+}
+
+func taskName(command string, args []string) string {
+	if len(args) == 0 {
+		return command
+	}
+	return command + " " + strings.Join(args, " ")
+}
+
+// StdoutReporter returns the reporter behind the StartlnWithTime,
+// Infoln, Warnln, Errorln and EndlnWithTime helpers. It writes to stdout
+// exactly as they do and is the default wherever a Reporter is optional.
+func StdoutReporter() Reporter { return stdoutReporter{} }
+
+type stdoutReporter struct{}
+
+func (stdoutReporter) Start(command string, args ...string) Task {
+	return &stdoutTask{start: StartlnWithTime(command, args...)}
+}
+
+type stdoutTask struct {
+	start time.Time
+	mu    sync.Mutex
+}
+
+func (t *stdoutTask) Line(stream Stream, text string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch stream {
+	case StreamStderr:
+		Warnln(text)
+	case StreamError:
+		Errorln(errors.New(text))
+	default:
+		Infoln(text)
+	}
+}
+
+func (t *stdoutTask) Step(name string) Task {
+	return &stdoutTask{start: StartlnWithTime(name)}
+}
+
+func (t *stdoutTask) End(err error) {
+	EndlnWithTime(time.Since(t.start), err == nil)
+}
+
+// NewReporter returns a TTY reporter when w takes colors and a plain one
+// otherwise.
+func NewReporter(w io.Writer) Reporter {
+	if DetectProfile(w) == NoColor {
+		return NewPlainReporter(w)
+	}
+	return NewTTYReporter(w)
+}
+
+// NewTTYReporter returns a reporter that writes the "=== Executing"
+// layout to w in color, indenting steps under their parent task.
+func NewTTYReporter(w io.Writer) Reporter {
+	p := DetectProfile(w)
+	if p == NoColor {
+		p = ANSI
+	}
+	return &textReporter{w: w, profile: p, now: time.Now}
+}
+
+// NewPlainReporter is NewTTYReporter without colors, for logs and pipes.
+func NewPlainReporter(w io.Writer) Reporter {
+	return &textReporter{w: w, profile: NoColor, now: time.Now}
+}
+
+var (
+	reportTime   = NewStyle().Foreground(ANSIColor(3))
+	reportStdout = NewStyle().Foreground(ANSIColor(2))
+	reportStderr = NewStyle().Foreground(ANSIColor(1))
+	reportFailed = NewStyle().Foreground(ANSIColor(9))
+	reportPassed = NewStyle().Foreground(ANSIColor(10))
+)
+
+type textReporter struct {
+	w       io.Writer
+	profile Profile
+	now     func() time.Time
+	mu      sync.Mutex
+}
+
+func (r *textReporter) Start(command string, args ...string) Task {
+	return r.start(taskName(command, args), 0)
+}
+
+func (r *textReporter) start(name string, depth int) Task {
+	t := &textTask{r: r, indent: strings.Repeat("  ", depth), depth: depth, start: r.now()}
+	r.printf(t.indent, "=== Executing: '%s' at %s", reportTime.Render(r.profile, name), reportTime.Render(r.profile, t.start.Format("15:04:05")))
+	return t
+}
+
+func (r *textReporter) printf(indent, format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, _ = fmt.Fprintf(r.w, indent+format+"\n", args...)
+}
+
+type textTask struct {
+	r      *textReporter
+	indent string
+	depth  int
+	start  time.Time
+}
+
+func (t *textTask) Line(stream Stream, text string) {
+	p := t.r.profile
+	switch stream {
+	case StreamStderr:
+		t.r.printf(t.indent, "%s", reportStderr.Render(p, "%%% "+text))
+	case StreamError:
+		t.r.printf(t.indent, "%s", reportFailed.Render(p, "~~~ "+text))
+	default:
+		t.r.printf(t.indent, "%s", reportStdout.Render(p, text))
+	}
+}
+
+func (t *textTask) Step(name string) Task {
+	return t.r.start(name, t.depth+1)
+}
+
+func (t *textTask) End(err error) {
+	p := t.r.profile
+	status := reportPassed.Render(p, "√")
+	if err != nil {
+		status = reportFailed.Render(p, "✗")
+	}
+	end := t.r.now()
+	t.r.printf(t.indent, "=== %s End: %s, Total: %s", status, reportTime.Render(p, end.Format("15:04:05")), reportTime.Render(p, end.Sub(t.start).String()))
+}
+
+// ReportEvent is one JSON line written by a JSON reporter. Event is
+// "start", "line" or "end"; Path holds the names of the enclosing tasks
+// followed by this one.
+type ReportEvent struct {
+	Event    string    `json:"event"`
+	Task     string    `json:"task"`
+	Path     []string  `json:"path"`
+	Time     time.Time `json:"time"`
+	Stream   string    `json:"stream,omitempty"`
+	Text     string    `json:"text,omitempty"`
+	Status   string    `json:"status,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Duration float64   `json:"duration_seconds,omitempty"`
+}
+
+// NewJSONReporter returns a reporter that writes one ReportEvent per line
+// to w, for tools that consume task output.
+func NewJSONReporter(w io.Writer) Reporter {
+	return &jsonReporter{enc: json.NewEncoder(w), now: time.Now}
+}
+
+type jsonReporter struct {
+	enc *json.Encoder
+	now func() time.Time
+	mu  sync.Mutex
+}
+
+func (r *jsonReporter) Start(command string, args ...string) Task {
+	return r.start([]string{taskName(command, args)})
+}
+
+func (r *jsonReporter) start(path []string) Task {
+	t := &jsonTask{r: r, path: path, start: r.now()}
+	r.write(ReportEvent{Event: "start", Task: path[len(path)-1], Path: path, Time: t.start})
+	return t
+}
+
+func (r *jsonReporter) write(e ReportEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = r.enc.Encode(e)
+}
+
+type jsonTask struct {
+	r     *jsonReporter
+	path  []string
+	start time.Time
+}
+
+func (t *jsonTask) name() string { return t.path[len(t.path)-1] }
+
+func (t *jsonTask) Line(stream Stream, text string) {
+	t.r.write(ReportEvent{Event: "line", Task: t.name(), Path: t.path, Time: t.r.now(), Stream: stream.String(), Text: text})
+}
+
+func (t *jsonTask) Step(name string) Task {
+	return t.r.start(append(t.path[:len(t.path):len(t.path)], name))
+}
+
+func (t *jsonTask) End(err error) {
+	end := t.r.now()
+	e := ReportEvent{Event: "end", Task: t.name(), Path: t.path, Time: end, Status: "ok", Duration: end.Sub(t.start).Seconds()}
+	if err != nil {
+		e.Status, e.Error = "failed", err.Error()
+	}
+	t.r.write(e)
+}
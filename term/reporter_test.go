@@ -0,0 +1,104 @@
+//go:build !windows
+
+package term
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixedClock returns times one second apart starting at noon.
+func fixedClock() func() time.Time {
+	t := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now := t
+		t = t.Add(time.Second)
+		return now
+	}
+}
+
+func TestPlainReporter(t *testing.T) {
+	var buf bytes.Buffer
+	r := &textReporter{w: &buf, profile: NoColor, now: fixedClock()}
+	task := r.Start("make", "build")
+	task.Line(StreamStdout, "compiling")
+	step := task.Step("go vet")
+	step.Line(StreamStderr, "warning")
+	step.End(nil)
+	task.Line(StreamError, "exit status 2")
+	task.End(errors.New("exit status 2"))
+
+	want := "" +
+		"=== Executing: 'make build' at 12:00:00\n" +
+		"compiling\n" +
+		"  === Executing: 'go vet' at 12:00:01\n" +
+		"  %%% warning\n" +
+		"  === √ End: 12:00:02, Total: 1s\n" +
+		"~~~ exit status 2\n" +
+		"=== ✗ End: 12:00:03, Total: 3s\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestTTYReporterColors(t *testing.T) {
+	var buf bytes.Buffer
+	task := NewTTYReporter(&buf).Start("ls")
+	task.Line(StreamStdout, "file")
+	task.End(nil)
+	if !strings.Contains(buf.String(), reportStdout.Render(ANSI, "file")) {
+		t.Errorf("stdout line not colored: %q", buf.String())
+	}
+}
+
+func TestJSONReporter(t *testing.T) {
+	var buf bytes.Buffer
+	r := &jsonReporter{now: fixedClock()}
+	r.enc = json.NewEncoder(&buf)
+	task := r.Start("deploy")
+	step := task.Step("upload")
+	step.Line(StreamStdout, "ok")
+	step.End(errors.New("boom"))
+	task.End(nil)
+
+	var events []ReportEvent
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var e ReportEvent
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("bad line %q: %v", line, err)
+		}
+		events = append(events, e)
+	}
+	if len(events) != 5 {
+		t.Fatalf("got %d events, want 5", len(events))
+	}
+	if e := events[2]; e.Event != "line" || e.Stream != "stdout" || e.Text != "ok" || strings.Join(e.Path, "/") != "deploy/upload" {
+		t.Errorf("line event = %+v", e)
+	}
+	if e := events[3]; e.Status != "failed" || e.Error != "boom" || e.Duration != 2 {
+		t.Errorf("step end event = %+v", e)
+	}
+	if e := events[4]; e.Event != "end" || e.Status != "ok" || e.Duration != 4 || len(e.Path) != 1 {
+		t.Errorf("task end event = %+v", e)
+	}
+}
+
+func TestStartTaskUsesContext(t *testing.T) {
+	var buf bytes.Buffer
+	r := &textReporter{w: &buf, profile: NoColor, now: fixedClock()}
+	parent := r.Start("release")
+	ctx := ContextWithTask(context.Background(), parent)
+	StartTask(ctx, nil, "git", "tag", "v1").End(nil)
+	parent.End(nil)
+	if !strings.Contains(buf.String(), "  === Executing: 'git tag v1'") {
+		t.Errorf("step not nested under the context task:\n%s", buf.String())
+	}
+	if TaskFromContext(context.Background()) != nil {
+		t.Error("TaskFromContext on an empty context should be nil")
+	}
+}
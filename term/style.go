@@ -0,0 +1,237 @@
+//go:build !windows
+
+package term
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type colorMode uint8
+
+const (
+	colorNone colorMode = iota
+	color16
+	color256
+	colorRGB
+)
+
+// Color is a foreground or background color. The zero value means the
+// terminal's default.
+type Color struct {
+	mode    colorMode
+	index   uint8
+	r, g, b uint8
+}
+
+// ANSIColor returns one of the 16 basic colors: 0-7 are black, red, green,
+// yellow, blue, magenta, cyan and white, 8-15 their bright variants.
+func ANSIColor(n uint8) Color {
+	return Color{mode: color16, index: n % 16}
+}
+
+// Color256 returns a color from the xterm 256 color palette.
+func Color256(n uint8) Color {
+	return Color{mode: color256, index: n}
+}
+
+// RGB returns a 24-bit color.
+func RGB(r, g, b uint8) Color {
+	return Color{mode: colorRGB, r: r, g: g, b: b}
+}
+
+// Hex parses "#rrggbb" or "rrggbb". Invalid input yields the default color.
+func Hex(s string) Color {
+	s = strings.TrimPrefix(s, "#")
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil || len(s) != 6 {
+		return Color{}
+	}
+	return RGB(uint8(v>>16), uint8(v>>8), uint8(v))
+}
+
+// downsample converts c to the best match p can display.
+func (c Color) downsample(p Profile) Color {
+	switch {
+	case c.mode == colorNone || p == NoColor:
+		return Color{}
+	case c.mode == colorRGB && p == ANSI256:
+		return Color256(rgbTo256(c.r, c.g, c.b))
+	case c.mode == colorRGB && p == ANSI:
+		return ANSIColor(nearest16(c.r, c.g, c.b))
+	case c.mode == color256 && p == ANSI:
+		if c.index < 16 {
+			return ANSIColor(c.index)
+		}
+		r, g, b := xterm256RGB(c.index)
+		return ANSIColor(nearest16(r, g, b))
+	}
+	return c
+}
+
+// sgr returns the SGR parameters for c, using base 30 for foreground and
+// 40 for background.
+func (c Color) sgr(base int) string {
+	switch c.mode {
+	case color16:
+		if c.index < 8 {
+			return strconv.Itoa(base + int(c.index))
+		}
+		return strconv.Itoa(base + 60 + int(c.index) - 8)
+	case color256:
+		return fmt.Sprintf("%d;5;%d", base+8, c.index)
+	case colorRGB:
+		return fmt.Sprintf("%d;2;%d;%d;%d", base+8, c.r, c.g, c.b)
+	}
+	return ""
+}
+
+// Style is a set of colors and attributes. Styles are values; every
+// method returns a modified copy.
+type Style struct {
+	fg, bg    Color
+	bold      bool
+	italic    bool
+	underline bool
+}
+
+// NewStyle returns an empty style, which renders text unchanged.
+func NewStyle() Style { return Style{} }
+
+// Foreground sets the text color.
+func (s Style) Foreground(c Color) Style { s.fg = c; return s }
+
+// Background sets the background color.
+func (s Style) Background(c Color) Style { s.bg = c; return s }
+
+// Bold sets the bold attribute.
+func (s Style) Bold() Style { s.bold = true; return s }
+
+// Italic sets the italic attribute.
+func (s Style) Italic() Style { s.italic = true; return s }
+
+// Underline sets the underline attribute.
+func (s Style) Underline() Style { s.underline = true; return s }
+
+// Sequence returns the escape sequence that switches to s on a terminal
+// with profile p, or "" when there is nothing to switch.
+func (s Style) Sequence(p Profile) string {
+	if p == NoColor {
+		return ""
+	}
+	var params []string
+	if s.bold {
+		params = append(params, "1")
+	}
+	if s.italic {
+		params = append(params, "3")
+	}
+	if s.underline {
+		params = append(params, "4")
+	}
+	if fg := s.fg.downsample(p).sgr(30); fg != "" {
+		params = append(params, fg)
+	}
+	if bg := s.bg.downsample(p).sgr(40); bg != "" {
+		params = append(params, bg)
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	return Esc + strings.Join(params, ";") + "m"
+}
+
+// Render formats args like fmt.Sprint and wraps the result in s, as far
+// as p supports it.
+func (s Style) Render(p Profile, args ...interface{}) string {
+	text := fmt.Sprint(args...)
+	seq := s.Sequence(p)
+	if seq == "" {
+		return text
+	}
+	return seq + text + Rst
+}
+
+// Fprint writes args styled for the profile detected for w.
+func (s Style) Fprint(w io.Writer, args ...interface{}) (int, error) {
+	return io.WriteString(w, s.Render(DetectProfile(w), args...))
+}
+
+// Fprintln is Fprint followed by a newline.
+func (s Style) Fprintln(w io.Writer, args ...interface{}) (int, error) {
+	return io.WriteString(w, s.Render(DetectProfile(w), args...)+"\n")
+}
+
+// ansiPalette is the conventional xterm RGB value of each of the 16 basic
+// colors.
+var ansiPalette = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+var cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// xterm256RGB returns the RGB value of a 256 palette index.
+func xterm256RGB(n uint8) (r, g, b uint8) {
+	switch {
+	case n < 16:
+		c := ansiPalette[n]
+		return c[0], c[1], c[2]
+	case n < 232:
+		n -= 16
+		return cubeLevels[n/36], cubeLevels[n/6%6], cubeLevels[n%6]
+	default:
+		v := 8 + 10*(n-232)
+		return v, v, v
+	}
+}
+
+// rgbTo256 picks the closer of the nearest 6x6x6 cube entry and the
+// nearest grey on the 24 step ramp.
+func rgbTo256(r, g, b uint8) uint8 {
+	cube := func(v uint8) uint8 {
+		if v < 48 {
+			return 0
+		}
+		if v < 115 {
+			return 1
+		}
+		return (v - 35) / 40
+	}
+	ci := 16 + 36*cube(r) + 6*cube(g) + cube(b)
+	cr, cg, cb := xterm256RGB(ci)
+
+	avg := (int(r) + int(g) + int(b)) / 3
+	gi := uint8(232)
+	if avg > 238 {
+		gi = 255
+	} else if avg > 8 {
+		gi = uint8(232 + (avg-3)/10)
+	}
+	gr, gg, gb := xterm256RGB(gi)
+
+	if distance(r, g, b, gr, gg, gb) < distance(r, g, b, cr, cg, cb) {
+		return gi
+	}
+	return ci
+}
+
+// nearest16 returns the basic color closest to r, g, b.
+func nearest16(r, g, b uint8) uint8 {
+	best, bestDist := uint8(0), -1
+	for i, c := range ansiPalette {
+		if d := distance(r, g, b, c[0], c[1], c[2]); bestDist < 0 || d < bestDist {
+			best, bestDist = uint8(i), d
+		}
+	}
+	return best
+}
+
+func distance(r1, g1, b1, r2, g2, b2 uint8) int {
+	dr, dg, db := int(r1)-int(r2), int(g1)-int(g2), int(b1)-int(b2)
+	return dr*dr + dg*dg + db*db
+}
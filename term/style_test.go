@@ -0,0 +1,107 @@
+//go:build !windows
+
+package term
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestProfileFromEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		tty  bool
+		want Profile
+	}{
+		{"not a tty", map[string]string{"TERM": "xterm-256color"}, false, NoColor},
+		{"basic tty", map[string]string{"TERM": "xterm"}, true, ANSI},
+		{"256color", map[string]string{"TERM": "xterm-256color"}, true, ANSI256},
+		{"colorterm truecolor", map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor"}, true, TrueColor},
+		{"colorterm 24bit", map[string]string{"COLORTERM": "24bit"}, true, TrueColor},
+		{"term direct", map[string]string{"TERM": "xterm-direct"}, true, TrueColor},
+		{"dumb", map[string]string{"TERM": "dumb", "COLORTERM": "truecolor"}, true, NoColor},
+		{"NO_COLOR", map[string]string{"NO_COLOR": "1", "TERM": "xterm-256color"}, true, NoColor},
+		{"NO_COLOR beats force", map[string]string{"NO_COLOR": "1", "CLICOLOR_FORCE": "1"}, true, NoColor},
+		{"CLICOLOR=0", map[string]string{"CLICOLOR": "0", "TERM": "xterm"}, true, NoColor},
+		{"force on pipe", map[string]string{"CLICOLOR_FORCE": "1", "TERM": "xterm-256color"}, false, ANSI256},
+		{"force on dumb", map[string]string{"CLICOLOR_FORCE": "1", "TERM": "dumb"}, false, ANSI},
+		{"force=0 is off", map[string]string{"CLICOLOR_FORCE": "0"}, false, NoColor},
+	}
+	for _, tt := range tests {
+		got := profileFromEnv(func(k string) string { return tt.env[k] }, tt.tty)
+		if got != tt.want {
+			t.Errorf("%s: profile = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDetectProfileNonFile(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "")
+	if p := DetectProfile(&bytes.Buffer{}); p != NoColor {
+		t.Errorf("buffer profile = %v, want none", p)
+	}
+}
+
+func TestStyleSequence(t *testing.T) {
+	s := NewStyle().Bold().Underline().Foreground(RGB(255, 0, 0)).Background(Color256(21))
+	tests := []struct {
+		p    Profile
+		want string
+	}{
+		{TrueColor, "\x1b[1;4;38;2;255;0;0;48;5;21m"},
+		{ANSI256, "\x1b[1;4;38;5;196;48;5;21m"},
+		{ANSI, "\x1b[1;4;91;44m"},
+		{NoColor, ""},
+	}
+	for _, tt := range tests {
+		if got := s.Sequence(tt.p); got != tt.want {
+			t.Errorf("%v: Sequence = %q, want %q", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestStyleRender(t *testing.T) {
+	s := NewStyle().Italic().Foreground(ANSIColor(2))
+	if got := s.Render(ANSI, "ok"); got != "\x1b[3;32mok"+Rst {
+		t.Errorf("Render = %q", got)
+	}
+	if got := s.Render(NoColor, "ok"); got != "ok" {
+		t.Errorf("Render without color = %q", got)
+	}
+	if got := NewStyle().Render(TrueColor, "plain"); got != "plain" {
+		t.Errorf("empty style = %q", got)
+	}
+	var buf bytes.Buffer
+	_, _ = s.Fprintln(&buf, "x")
+	if buf.String() != "x\n" {
+		t.Errorf("Fprintln to non-terminal = %q", buf.String())
+	}
+}
+
+func TestColorConversion(t *testing.T) {
+	tests := []struct {
+		r, g, b uint8
+		want256 uint8
+	}{
+		{0, 0, 0, 16},
+		{255, 255, 255, 231},
+		{128, 128, 128, 244},
+		{0, 135, 255, 33},
+	}
+	for _, tt := range tests {
+		if got := rgbTo256(tt.r, tt.g, tt.b); got != tt.want256 {
+			t.Errorf("rgbTo256(%d,%d,%d) = %d, want %d", tt.r, tt.g, tt.b, got, tt.want256)
+		}
+	}
+	if got := Hex("#00ff00"); got != RGB(0, 255, 0) {
+		t.Errorf("Hex = %+v", got)
+	}
+	if got := Hex("nope"); got != (Color{}) {
+		t.Errorf("invalid Hex = %+v", got)
+	}
+	if got := Color256(196).downsample(ANSI); got != ANSIColor(9) {
+		t.Errorf("256->16 = %+v, want bright red", got)
+	}
+}
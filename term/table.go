@@ -0,0 +1,391 @@
+//go:build !windows
+
+package term
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Align is the horizontal alignment of a table column.
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignRight
+	AlignCenter
+)
+
+// Format selects how a Table is written.
+type Format int
+
+const (
+	// FormatAuto draws a table on a terminal and writes TSV otherwise.
+	FormatAuto Format = iota
+	// FormatText always draws the table.
+	FormatText
+	// FormatCSV writes RFC 4180 CSV with escapes stripped.
+	FormatCSV
+	// FormatTSV writes tab separated values with escapes stripped.
+	FormatTSV
+)
+
+// Border is the set of strings a table is drawn with. Each is expected to
+// be one cell wide, or empty to leave that part out.
+type Border struct {
+	Horizontal, Vertical                  string
+	TopLeft, TopMiddle, TopRight          string
+	MiddleLeft, Cross, MiddleRight        string
+	BottomLeft, BottomMiddle, BottomRight string
+}
+
+var (
+	// BorderNone separates columns with spaces and draws no lines.
+	BorderNone = Border{}
+	// BorderASCII draws with plain ASCII characters.
+	BorderASCII = Border{
+		Horizontal: "-", Vertical: "|",
+		TopLeft: "+", TopMiddle: "+", TopRight: "+",
+		MiddleLeft: "+", Cross: "+", MiddleRight: "+",
+		BottomLeft: "+", BottomMiddle: "+", BottomRight: "+",
+	}
+	// BorderLight draws with Unicode box drawing characters.
+	BorderLight = Border{
+		Horizontal: "─", Vertical: "│",
+		TopLeft: "┌", TopMiddle: "┬", TopRight: "┐",
+		MiddleLeft: "├", Cross: "┼", MiddleRight: "┤",
+		BottomLeft: "└", BottomMiddle: "┴", BottomRight: "┘",
+	}
+	// BorderRounded is BorderLight with rounded corners.
+	BorderRounded = Border{
+		Horizontal: "─", Vertical: "│",
+		TopLeft: "╭", TopMiddle: "┬", TopRight: "╮",
+		MiddleLeft: "├", Cross: "┼", MiddleRight: "┤",
+		BottomLeft: "╰", BottomMiddle: "┴", BottomRight: "╯",
+	}
+)
+
+// minColumnWidth is how narrow a column may be squeezed to fit the table
+// into its maximum width.
+const minColumnWidth = 3
+
+// Column describes a table column.
+type Column struct {
+	Header string
+	Align  Align
+	// MaxWidth caps the column width in cells; 0 means no cap.
+	MaxWidth int
+	// Wrap wraps long cells onto several lines instead of truncating them.
+	Wrap bool
+}
+
+// TableOption configures a Table.
+type TableOption func(*Table)
+
+// WithColumns sets the table columns.
+func WithColumns(columns ...Column) TableOption {
+	return func(t *Table) {
+		t.columns = columns
+	}
+}
+
+// WithHeaders sets left aligned columns with the given headers.
+func WithHeaders(headers ...string) TableOption {
+	return func(t *Table) {
+		t.columns = make([]Column, len(headers))
+		for i, h := range headers {
+			t.columns[i] = Column{Header: h}
+		}
+	}
+}
+
+// WithBorder sets the border style. The default is BorderNone.
+func WithBorder(b Border) TableOption {
+	return func(t *Table) {
+		t.border = b
+	}
+}
+
+// WithFormat sets the output format. The default is FormatAuto.
+func WithFormat(f Format) TableOption {
+	return func(t *Table) {
+		t.format = f
+	}
+}
+
+// WithMaxWidth limits the drawn table to n cells, shrinking the widest
+// columns first. By default a table written to a terminal is limited to
+// the terminal width.
+func WithMaxWidth(n int) TableOption {
+	return func(t *Table) {
+		t.maxWidth = n
+	}
+}
+
+// Table renders rows of cells as an aligned table.
+type Table struct {
+	columns  []Column
+	rows     [][]string
+	border   Border
+	format   Format
+	maxWidth int
+}
+
+// NewTable returns an empty table.
+func NewTable(opts ...TableOption) *Table {
+	t := &Table{}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// AddRow appends a row, formatting each cell like fmt.Sprint. Rows longer
+// than the column list add left aligned columns without a header.
+func (t *Table) AddRow(cells ...interface{}) {
+	row := make([]string, len(cells))
+	for i, c := range cells {
+		row[i] = fmt.Sprint(c)
+	}
+	for len(t.columns) < len(row) {
+		t.columns = append(t.columns, Column{})
+	}
+	t.rows = append(t.rows, row)
+}
+
+// Render writes the table to w in the configured format.
+func (t *Table) Render(w io.Writer) error {
+	format, maxWidth := t.format, t.maxWidth
+	f, isFile := w.(*os.File)
+	tty := isFile && IsTerminal(int(f.Fd()))
+	if format == FormatAuto {
+		format = FormatTSV
+		if tty {
+			format = FormatText
+		}
+	}
+	if maxWidth == 0 && tty {
+		if cols, _, err := GetSize(int(f.Fd())); err == nil {
+			maxWidth = cols
+		}
+	}
+	switch format {
+	case FormatCSV:
+		return t.writeCSV(w)
+	case FormatTSV:
+		return t.writeTSV(w)
+	}
+	_, err := io.WriteString(w, t.text(maxWidth))
+	return err
+}
+
+// String returns the table drawn as text.
+func (t *Table) String() string {
+	return t.text(t.maxWidth)
+}
+
+func (t *Table) hasHeader() bool {
+	for _, c := range t.columns {
+		if c.Header != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// records returns the header, if any, and rows padded to the column count
+// with escapes stripped.
+func (t *Table) records() [][]string {
+	var out [][]string
+	if t.hasHeader() {
+		header := make([]string, len(t.columns))
+		for i, c := range t.columns {
+			header[i] = StripEscapes(c.Header)
+		}
+		out = append(out, header)
+	}
+	for _, row := range t.rows {
+		rec := make([]string, len(t.columns))
+		for i, cell := range row {
+			rec[i] = StripEscapes(cell)
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+func (t *Table) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(t.records()); err != nil {
+		return fmt.Errorf("term: writing csv: %w", err)
+	}
+	return nil
+}
+
+// tsvReplacer keeps cells from breaking the TSV layout.
+var tsvReplacer = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+
+func (t *Table) writeTSV(w io.Writer) error {
+	var b strings.Builder
+	for _, rec := range t.records() {
+		for i, cell := range rec {
+			rec[i] = tsvReplacer.Replace(cell)
+		}
+		b.WriteString(strings.Join(rec, "\t"))
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// widths returns the width of each column: its widest cell, capped by the
+// column's MaxWidth, then shrunk widest first until the table fits in
+// maxWidth cells.
+func (t *Table) widths(maxWidth int) []int {
+	widths := make([]int, len(t.columns))
+	for i, c := range t.columns {
+		widths[i] = StringWidth(c.Header)
+	}
+	for _, row := range t.rows {
+		for i, cell := range row {
+			for _, line := range strings.Split(cell, "\n") {
+				widths[i] = max(widths[i], StringWidth(line))
+			}
+		}
+	}
+	for i, c := range t.columns {
+		if c.MaxWidth > 0 {
+			widths[i] = min(widths[i], c.MaxWidth)
+		}
+	}
+	if maxWidth <= 0 {
+		return widths
+	}
+	for t.tableWidth(widths) > maxWidth {
+		widest := 0
+		for i := range widths {
+			if widths[i] > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= minColumnWidth {
+			break
+		}
+		widths[widest]--
+	}
+	return widths
+}
+
+// tableWidth is the width of a drawn line given the column widths.
+func (t *Table) tableWidth(widths []int) int {
+	n := 0
+	for _, w := range widths {
+		n += w
+	}
+	if t.border.Vertical == "" {
+		return n + 2*max(len(widths)-1, 0)
+	}
+	return n + 3*len(widths) + 1
+}
+
+// cellLines fits cell to width, wrapping or truncating per column.
+func cellLines(cell string, width int, c Column) []string {
+	if c.Wrap {
+		return Wrap(cell, width)
+	}
+	lines := strings.Split(cell, "\n")
+	for i, line := range lines {
+		lines[i] = Truncate(line, width)
+	}
+	return lines
+}
+
+func (t *Table) text(maxWidth int) string {
+	if len(t.columns) == 0 {
+		return ""
+	}
+	widths := t.widths(maxWidth)
+	var b strings.Builder
+	t.rule(&b, widths, t.border.TopLeft, t.border.TopMiddle, t.border.TopRight)
+	if t.hasHeader() {
+		header := make([]string, len(t.columns))
+		for i, c := range t.columns {
+			header[i] = c.Header
+		}
+		t.row(&b, widths, header)
+		if t.border.Horizontal == "" {
+			sep := make([]string, len(widths))
+			for i, w := range widths {
+				sep[i] = strings.Repeat("-", w)
+			}
+			t.row(&b, widths, sep)
+		} else {
+			t.rule(&b, widths, t.border.MiddleLeft, t.border.Cross, t.border.MiddleRight)
+		}
+	}
+	for _, row := range t.rows {
+		t.row(&b, widths, row)
+	}
+	t.rule(&b, widths, t.border.BottomLeft, t.border.BottomMiddle, t.border.BottomRight)
+	return b.String()
+}
+
+// rule draws a horizontal border line, if the border has one.
+func (t *Table) rule(b *strings.Builder, widths []int, left, middle, right string) {
+	if t.border.Horizontal == "" {
+		return
+	}
+	b.WriteString(left)
+	for i, w := range widths {
+		if i > 0 {
+			b.WriteString(middle)
+		}
+		b.WriteString(strings.Repeat(t.border.Horizontal, w+2))
+	}
+	b.WriteString(right)
+	b.WriteByte('\n')
+}
+
+// row draws one table row, which spans as many lines as its tallest cell.
+func (t *Table) row(b *strings.Builder, widths []int, row []string) {
+	cells := make([][]string, len(widths))
+	height := 1
+	for i, w := range widths {
+		var cell string
+		if i < len(row) {
+			cell = row[i]
+		}
+		cells[i] = cellLines(cell, w, t.columns[i])
+		height = max(height, len(cells[i]))
+	}
+	for l := 0; l < height; l++ {
+		var line strings.Builder
+		if t.border.Vertical != "" {
+			line.WriteString(t.border.Vertical + " ")
+		}
+		for i, w := range widths {
+			if i > 0 {
+				if t.border.Vertical != "" {
+					line.WriteString(" " + t.border.Vertical + " ")
+				} else {
+					line.WriteString("  ")
+				}
+			}
+			var text string
+			if l < len(cells[i]) {
+				text = cells[i][l]
+			}
+			line.WriteString(pad(text, w, t.columns[i].Align))
+		}
+		if t.border.Vertical != "" {
+			line.WriteString(" " + t.border.Vertical)
+			b.WriteString(line.String())
+		} else {
+			b.WriteString(strings.TrimRight(line.String(), " "))
+		}
+		b.WriteByte('\n')
+	}
+}
@@ -0,0 +1,160 @@
+//go:build !windows
+
+package term
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStringWidth(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"hello", 5},
+		{"日本語", 6},
+		{"ｈｉ", 4},
+		{"é", 1},
+		{"\x1b[31mred\x1b[0m", 3},
+		{"\x1b[38;2;1;2;3m色\x1b[0m", 2},
+		{"\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", 4},
+	}
+	for _, tt := range tests {
+		if got := StringWidth(tt.in); got != tt.want {
+			t.Errorf("StringWidth(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{"hello", 5, "hello"},
+		{"hello world", 5, "hell…"},
+		{"日本語テキスト", 5, "日本…"},
+		{"\x1b[31mhello world\x1b[0m", 6, "\x1b[31mhello…" + Rst},
+		{"abc", 0, ""},
+	}
+	for _, tt := range tests {
+		got := Truncate(tt.in, tt.n)
+		if got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.in, tt.n, got, tt.want)
+		}
+		if w := StringWidth(got); w > tt.n {
+			t.Errorf("Truncate(%q, %d) is %d cells wide", tt.in, tt.n, w)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	got := Wrap("the quick brown fox jumps", 10)
+	want := []string{"the quick", "brown fox", "jumps"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Wrap = %q, want %q", got, want)
+	}
+	got = Wrap("abcdefghij", 4)
+	want = []string{"abcd", "efgh", "ij"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Wrap long word = %q, want %q", got, want)
+	}
+	got = Wrap("日本語テキスト", 5)
+	want = []string{"日本", "語テ", "キス", "ト"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Wrap wide = %q, want %q", got, want)
+	}
+}
+
+func TestTableText(t *testing.T) {
+	tbl := NewTable(WithColumns(
+		Column{Header: "NAME"},
+		Column{Header: "SIZE", Align: AlignRight},
+	))
+	tbl.AddRow("a", 1)
+	tbl.AddRow("日本", 1024)
+	want := "" +
+		"NAME  SIZE\n" +
+		"----  ----\n" +
+		"a        1\n" +
+		"日本  1024\n"
+	if got := tbl.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTableBorderAndColor(t *testing.T) {
+	red := NewStyle().Foreground(ANSIColor(1))
+	tbl := NewTable(WithHeaders("K", "V"), WithBorder(BorderASCII))
+	tbl.AddRow(red.Render(ANSI, "x"), "yes")
+	want := "" +
+		"+---+-----+\n" +
+		"| K | V   |\n" +
+		"+---+-----+\n" +
+		"| " + red.Render(ANSI, "x") + " | yes |\n" +
+		"+---+-----+\n"
+	if got := tbl.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(tbl.String(), "\n"), "\n") {
+		if w := StringWidth(line); w != 11 {
+			t.Errorf("line %q is %d cells wide, want 11", line, w)
+		}
+	}
+}
+
+func TestTableWrapAndTruncate(t *testing.T) {
+	tbl := NewTable(WithColumns(
+		Column{Header: "ID", MaxWidth: 4},
+		Column{Header: "NOTE", MaxWidth: 10, Wrap: true},
+	), WithBorder(BorderLight))
+	tbl.AddRow("abcdefgh", "one two three four")
+	want := "" +
+		"┌──────┬────────────┐\n" +
+		"│ ID   │ NOTE       │\n" +
+		"├──────┼────────────┤\n" +
+		"│ abc… │ one two    │\n" +
+		"│      │ three four │\n" +
+		"└──────┴────────────┘\n"
+	if got := tbl.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTableMaxWidth(t *testing.T) {
+	tbl := NewTable(WithHeaders("A", "B"), WithMaxWidth(12))
+	tbl.AddRow("short", "a much longer value")
+	for _, line := range strings.Split(strings.TrimSuffix(tbl.String(), "\n"), "\n") {
+		if w := StringWidth(line); w > 12 {
+			t.Errorf("line %q is %d cells wide, want at most 12", line, w)
+		}
+	}
+}
+
+func TestTableRenderPlain(t *testing.T) {
+	tbl := NewTable(WithHeaders("NAME", "NOTE"))
+	tbl.AddRow("\x1b[1ma\x1b[0m", "x\ty")
+	tbl.AddRow("b", `say "hi", bye`)
+
+	var buf bytes.Buffer
+	if err := tbl.Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := "NAME\tNOTE\na\tx y\nb\tsay \"hi\", bye\n"; buf.String() != want {
+		t.Errorf("TSV = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	tbl = NewTable(WithHeaders("NAME", "NOTE"), WithFormat(FormatCSV))
+	tbl.AddRow("b", `say "hi", bye`)
+	if err := tbl.Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := "NAME,NOTE\nb,\"say \"\"hi\"\", bye\"\n"; buf.String() != want {
+		t.Errorf("CSV = %q, want %q", buf.String(), want)
+	}
+}
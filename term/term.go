@@ -12,12 +12,16 @@ import (
 	"os/signal"
 	"strings"
 	"sync"
-	"syscall"
 	"time"
 )
 
 var (
-	// if ENV: NO_COLOR is not empty, will disable color render.
+	// NoColorFlag is true when color output is allowed; set it to false to
+	// turn off the TermColor helpers. Despite the name, true means color on.
+	//
+	// Deprecated: use DetectProfile, which also honors CLICOLOR_FORCE,
+	// TERM=dumb and whether the output is a terminal, and Style for
+	// rendering. The TermColor helpers already consult the stdout profile.
 	NoColorFlag = os.Getenv("NO_COLOR") == ""
 )
 
@@ -26,6 +30,7 @@ const (
 	Esc                      = "\u001B["
 	Osc                      = "\u001B]"
 	Bel                      = "\u0007"
+	St                       = "\u001B\\"
 	Rst                      = Esc + "0m"
 )
 
@@ -80,7 +85,7 @@ func renderCode(color string, args ...interface{}) string {
 	if len(color) == 0 {
 		return message
 	}
-	if NoColorFlag {
+	if NoColorFlag && stdoutProfile() != NoColor {
 		return fmt.Sprintf("%s%s%s", color, message, Rst)
 	}
 	return message
@@ -165,70 +170,65 @@ func DisplayLn(reader io.Reader, wg *sync.WaitGroup, displayFn func(string)) {
 	wg.Done()
 }
 
-func echo(on bool) error {
-	// Common settings and variables for both stty calls.
-	attrs := syscall.ProcAttr{
-		Dir:   "",
-		Env:   []string{},
-		Files: []uintptr{os.Stdin.Fd(), os.Stdout.Fd(), os.Stderr.Fd()},
-		Sys:   nil}
-	var ws syscall.WaitStatus
-	cmd := "echo"
-	if !on {
-		cmd = "-echo"
-	}
-
-	// Enable/disable echoing.
-	pid, err := syscall.ForkExec(
-		"/bin/stty",
-		[]string{"stty", cmd},
-		&attrs)
-	if err != nil {
-		return fmt.Errorf("stty fork: %w", err)
-	}
-
-	// Wait for the stty process to complete.
-	if _, err := syscall.Wait4(pid, &ws, 0, nil); err != nil {
-		return fmt.Errorf("stty wait: %w", err)
-	}
-	return nil
-}
-
 // PasswordPromptContext writes prompt to stdout, disables terminal echo, and
-// reads a line from stdin. Echo is restored before returning. If ctx is
-// canceled while waiting for input the function returns ctx.Err(); the caller
-// is responsible for any signal-to-cancel wiring (e.g. signal.NotifyContext
-// with os.Interrupt).
+// reads a line from stdin. Echo is restored before returning, including
+// when the process is interrupted. If ctx is canceled while waiting for
+// input the function returns ctx.Err() without leaving a goroutine
+// blocked on stdin; the caller is responsible for any signal-to-cancel
+// wiring (e.g. signal.NotifyContext with os.Interrupt). A SIGINT, SIGTERM
+// or SIGHUP received while echo is off restores it and returns
+// ErrInterrupted.
 func PasswordPromptContext(ctx context.Context, prompt string) (string, error) {
 	fmt.Print(prompt)
-	if err := echo(false); err != nil {
-		return "", fmt.Errorf("disable echo: %w", err)
+	fd := int(os.Stdin.Fd())
+	if IsTerminal(fd) {
+		old, err := GetState(fd)
+		if err != nil {
+			return "", fmt.Errorf("get terminal state: %w", err)
+		}
+		if err := SetEcho(fd, false); err != nil {
+			return "", fmt.Errorf("disable echo: %w", err)
+		}
+		signals, stop := RestoreOnSignal(fd, old)
+		var release func()
+		ctx, release = cancelOnSignal(ctx, signals)
+		defer func() {
+			release()
+			stop()
+			// Best-effort restore; the caller already has a value (or error),
+			// and there is no useful action to take if restore fails.
+			_ = Restore(fd, old)
+		}()
 	}
-	defer func() {
-		// Best-effort echo restore; the caller already has a value (or error),
-		// and there is no useful action to take if restore fails.
-		_ = echo(true)
-		fmt.Println("")
-	}()
+	defer fmt.Println("")
 
-	type result struct {
-		text string
-		err  error
+	text, err := readLineContext(ctx, fd)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", interrupted(ctx, ctx.Err())
+		}
+		return "", fmt.Errorf("read password: %w", err)
 	}
-	done := make(chan result, 1)
-	go func() {
-		text, err := bufio.NewReader(os.Stdin).ReadString('\n')
-		done <- result{text: text, err: err}
-	}()
+	return strings.TrimSpace(text), nil
+}
 
-	select {
-	case <-ctx.Done():
-		return "", ctx.Err()
-	case r := <-done:
-		if r.err != nil {
-			return "", fmt.Errorf("read password: %w", r.err)
+// readLineContext reads up to and excluding the next newline from fd. A
+// final line without a newline is returned as is; EOF with nothing read
+// is an error.
+func readLineContext(ctx context.Context, fd int) (string, error) {
+	var line []byte
+	for {
+		b, err := readByteContext(ctx, fd)
+		if errors.Is(err, io.EOF) && len(line) > 0 {
+			return string(line), nil
+		}
+		if err != nil {
+			return "", err
 		}
-		return strings.TrimSpace(r.text), nil
+		if b == '\n' {
+			return string(line), nil
+		}
+		line = append(line, b)
 	}
 }
 
@@ -242,7 +242,7 @@ func PasswordPrompt(prompt string) string {
 	text, err := PasswordPromptContext(ctx, prompt)
 	cancel()
 	if err != nil {
-		if errors.Is(err, context.Canceled) {
+		if errors.Is(err, context.Canceled) || errors.Is(err, ErrInterrupted) {
 			fmt.Println("\n^C interrupt.")
 		} else {
 			fmt.Println("ERROR:", err.Error())
@@ -252,26 +252,29 @@ func PasswordPrompt(prompt string) string {
 	return text
 }
 
+// YesNoPrompt asks label on stderr and reads y/yes or n/no from stdin,
+// returning def for an empty answer. It returns def when stdin is closed
+// or fails instead of asking again. Prompter.Confirm adds context and
+// non-interactive support.
 func YesNoPrompt(label string, def bool) bool {
 	choices := "Y/n"
 	if !def {
 		choices = "y/N"
 	}
 	r := bufio.NewReader(os.Stdin)
-	var s string
 	for {
 		fmt.Fprintf(os.Stderr, "%s (%s) ", label, choices)
-		s, _ = r.ReadString('\n')
+		s, err := r.ReadString('\n')
 		s = strings.TrimSpace(s)
 		if s == "" {
 			return def
 		}
-		s = strings.ToLower(s)
-		if s == "y" || s == "yes" {
-			return true
+		if yes, valid := parseYesNo(s); valid {
+			return yes
 		}
-		if s == "n" || s == "no" {
-			return false
+		if err != nil {
+			fmt.Fprintln(os.Stderr)
+			return def
 		}
 	}
 }
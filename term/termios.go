@@ -0,0 +1,126 @@
+//go:build !windows
+
+package term
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ErrNotSupported is returned by the termios helpers on platforms without
+// terminal ioctls.
+var ErrNotSupported = errors.New("term: not supported on this platform")
+
+// ErrInterrupted is returned when the user presses Ctrl-C or Ctrl-D in a
+// menu, or when the process receives SIGINT, SIGTERM or SIGHUP while a
+// prompt has the terminal.
+var ErrInterrupted = errors.New("term: prompt interrupted")
+
+// State is a saved terminal configuration, as returned by GetState and
+// MakeRaw, to be handed back to Restore.
+type State struct {
+	state
+}
+
+// IsTerminal reports whether fd refers to a terminal.
+func IsTerminal(fd int) bool {
+	_, err := getState(fd)
+	return err == nil
+}
+
+// GetState returns the current configuration of the terminal fd.
+func GetState(fd int) (*State, error) {
+	st, err := getState(fd)
+	if err != nil {
+		return nil, err
+	}
+	return &State{st}, nil
+}
+
+// MakeRaw puts the terminal fd into raw mode: no echo, no line buffering,
+// no signal keys and no output processing. It returns the previous state
+// for Restore.
+func MakeRaw(fd int) (*State, error) {
+	old, err := getState(fd)
+	if err != nil {
+		return nil, err
+	}
+	if err := setState(fd, old.raw()); err != nil {
+		return nil, err
+	}
+	return &State{old}, nil
+}
+
+// Restore returns the terminal fd to a previously saved state.
+func Restore(fd int, s *State) error {
+	return setState(fd, s.state)
+}
+
+// SetEcho turns echoing of typed characters on or off, leaving line
+// editing alone. It is what password prompts need.
+func SetEcho(fd int, on bool) error {
+	st, err := getState(fd)
+	if err != nil {
+		return err
+	}
+	return setState(fd, st.echo(on))
+}
+
+// GetSize returns the width and height of the terminal fd in characters.
+func GetSize(fd int) (width, height int, err error) {
+	return getSize(fd)
+}
+
+// RestoreOnSignal restores s on fd if the process receives SIGINT, SIGTERM
+// or SIGHUP, and then sends the signal on the returned channel. It does
+// not re-raise it: other signal.Notify registrations receive it once as
+// usual, and a process without any keeps running, so the caller should
+// stop using the terminal when the signal arrives, as the prompts in this
+// package do by returning ErrInterrupted. The returned stop function
+// disarms it and must be called once the terminal has been restored
+// normally.
+func RestoreOnSignal(fd int, s *State) (signals <-chan os.Signal, stop func()) {
+	c := make(chan os.Signal, 1)
+	out := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		select {
+		case sig := <-c:
+			_ = Restore(fd, s)
+			out <- sig
+		case <-done:
+		}
+	}()
+	return out, func() {
+		signal.Stop(c)
+		close(done)
+	}
+}
+
+// cancelOnSignal returns a copy of ctx that is canceled with cause
+// ErrInterrupted once signals delivers. The returned function releases
+// it.
+func cancelOnSignal(ctx context.Context, signals <-chan os.Signal) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	go func() {
+		select {
+		case <-signals:
+			cancel(ErrInterrupted)
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() { cancel(context.Canceled) }
+}
+
+// interrupted returns ErrInterrupted if ctx was canceled by
+// cancelOnSignal, and err otherwise.
+func interrupted(ctx context.Context, err error) error {
+	if errors.Is(context.Cause(ctx), ErrInterrupted) {
+		return ErrInterrupted
+	}
+	return err
+}
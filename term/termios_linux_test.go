@@ -0,0 +1,183 @@
+package term
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY returns the controlling and terminal sides of a new
+// pseudo-terminal.
+func openPTY(t *testing.T) (ptmx, tty *os.File) {
+	t.Helper()
+	ptmx, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		t.Skipf("no pty support: %v", err)
+	}
+	t.Cleanup(func() { ptmx.Close() })
+	fd := int(ptmx.Fd())
+	if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		t.Skipf("unlockpt: %v", err)
+	}
+	n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	if err != nil {
+		t.Skipf("ptsname: %v", err)
+	}
+	tty, err = os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("open pts: %v", err)
+	}
+	t.Cleanup(func() { tty.Close() })
+	return ptmx, tty
+}
+
+func TestMakeRawAndRestore(t *testing.T) {
+	_, tty := openPTY(t)
+	fd := int(tty.Fd())
+	if !IsTerminal(fd) {
+		t.Fatal("pty is not a terminal")
+	}
+	old, err := MakeRaw(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := getState(fd)
+	if raw.termios.Lflag&(unix.ECHO|unix.ICANON) != 0 {
+		t.Errorf("raw mode still has ECHO/ICANON: %#x", raw.termios.Lflag)
+	}
+	if err := Restore(fd, old); err != nil {
+		t.Fatal(err)
+	}
+	restored, _ := getState(fd)
+	if restored.termios != old.termios {
+		t.Error("Restore did not bring back the original termios")
+	}
+}
+
+func TestSetEcho(t *testing.T) {
+	_, tty := openPTY(t)
+	fd := int(tty.Fd())
+	if err := SetEcho(fd, false); err != nil {
+		t.Fatal(err)
+	}
+	st, _ := getState(fd)
+	if st.termios.Lflag&unix.ECHO != 0 {
+		t.Error("ECHO still set")
+	}
+	if st.termios.Lflag&unix.ICANON == 0 {
+		t.Error("SetEcho should leave line editing on")
+	}
+	if err := SetEcho(fd, true); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := getState(fd); st.termios.Lflag&unix.ECHO == 0 {
+		t.Error("ECHO not restored")
+	}
+}
+
+func TestGetSize(t *testing.T) {
+	_, tty := openPTY(t)
+	fd := int(tty.Fd())
+	if err := unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, &unix.Winsize{Col: 120, Row: 40}); err != nil {
+		t.Fatal(err)
+	}
+	w, h, err := GetSize(fd)
+	if err != nil || w != 120 || h != 40 {
+		t.Errorf("GetSize = %d x %d, %v; want 120 x 40", w, h, err)
+	}
+}
+
+func TestIsTerminalFalseForPipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	if IsTerminal(int(r.Fd())) {
+		t.Error("pipe reported as terminal")
+	}
+	if _, err := MakeRaw(int(r.Fd())); err == nil {
+		t.Error("MakeRaw on a pipe should fail")
+	}
+}
+
+func TestReadLineContext(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	fd := int(r.Fd())
+
+	_, _ = w.WriteString("secret\nnext\n")
+	got, err := readLineContext(context.Background(), fd)
+	if err != nil || got != "secret" {
+		t.Fatalf("readLineContext = %q, %v", got, err)
+	}
+	// The second line must still be there for the next reader.
+	if got, _ := readLineContext(context.Background(), fd); got != "next" {
+		t.Errorf("second line = %q", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = readLineContext(ctx, fd)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want deadline exceeded", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("cancellation took too long")
+	}
+
+	_ = w.Close()
+	if _, err := readLineContext(context.Background(), fd); err == nil {
+		t.Error("EOF with nothing read should be an error")
+	}
+}
+
+func TestRestoreOnSignal(t *testing.T) {
+	_, tty := openPTY(t)
+	fd := int(tty.Fd())
+	old, err := MakeRaw(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Another handler in the process, as signal.NotifyContext installs.
+	other := make(chan os.Signal, 2)
+	signal.Notify(other, syscall.SIGINT)
+	defer signal.Stop(other)
+	signals, stop := RestoreOnSignal(fd, old)
+	defer stop()
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGINT); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case sig := <-signals:
+		if sig != syscall.SIGINT {
+			t.Errorf("signal = %v, want SIGINT", sig)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RestoreOnSignal did not report the signal")
+	}
+	if st, _ := getState(fd); st.termios != old.termios {
+		t.Error("terminal not restored")
+	}
+	// The other handler sees the signal once; it is not raised again.
+	<-other
+	select {
+	case <-other:
+		t.Error("signal delivered twice to another handler")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -0,0 +1,37 @@
+//go:build !windows && !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package term
+
+import (
+	"context"
+	"io"
+	"syscall"
+)
+
+type state struct{}
+
+func getState(int) (state, error) { return state{}, ErrNotSupported }
+
+func setState(int, state) error { return ErrNotSupported }
+
+func (s state) raw() state { return s }
+
+func (s state) echo(bool) state { return s }
+
+func getSize(int) (width, height int, err error) { return 0, 0, ErrNotSupported }
+
+// readByteContext cannot poll here, so it only checks ctx between reads.
+func readByteContext(ctx context.Context, fd int) (byte, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	var b [1]byte
+	n, err := syscall.Read(fd, b[:])
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return 0, io.EOF
+	}
+	return b[0], nil
+}
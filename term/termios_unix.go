@@ -0,0 +1,104 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package term
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+type state struct {
+	termios unix.Termios
+}
+
+func getState(fd int) (state, error) {
+	t, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
+	if err != nil {
+		return state{}, err
+	}
+	return state{*t}, nil
+}
+
+func setState(fd int, s state) error {
+	return unix.IoctlSetTermios(fd, ioctlWriteTermios, &s.termios)
+}
+
+func (s state) raw() state {
+	t := s.termios
+	t.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	t.Oflag &^= unix.OPOST
+	t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	t.Cflag &^= unix.CSIZE | unix.PARENB
+	t.Cflag |= unix.CS8
+	t.Cc[unix.VMIN] = 1
+	t.Cc[unix.VTIME] = 0
+	return state{t}
+}
+
+func (s state) echo(on bool) state {
+	t := s.termios
+	if on {
+		t.Lflag |= unix.ECHO
+	} else {
+		t.Lflag &^= unix.ECHO
+	}
+	return state{t}
+}
+
+func getSize(fd int) (width, height int, err error) {
+	ws, err := unix.IoctlGetWinsize(fd, unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(ws.Col), int(ws.Row), nil
+}
+
+// pollInterval bounds how long a read waits before checking its context.
+const pollInterval = 50 * time.Millisecond
+
+// waitReadable blocks until fd has input or ctx is done.
+func waitReadable(ctx context.Context, fd int) error {
+	fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}} // #nosec G115 -- fds fit in int32
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := unix.Poll(fds, int(pollInterval/time.Millisecond))
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return nil
+		}
+	}
+}
+
+// readByteContext reads a single byte from fd, giving up with ctx.Err()
+// once ctx is done. Reading one byte at a time never consumes input meant
+// for whoever reads fd next.
+func readByteContext(ctx context.Context, fd int) (byte, error) {
+	if err := waitReadable(ctx, fd); err != nil {
+		return 0, err
+	}
+	var b [1]byte
+	for {
+		n, err := unix.Read(fd, b[:])
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if n == 0 {
+			return 0, io.EOF
+		}
+		return b[0], nil
+	}
+}
@@ -0,0 +1,108 @@
+//go:build !windows
+
+package term
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// TreeGuides are the strings a tree is drawn with. All four must have the
+// same display width.
+type TreeGuides struct {
+	Branch, Last, Pipe, Space string
+}
+
+var (
+	// GuidesLight draws with Unicode box drawing characters.
+	GuidesLight = TreeGuides{Branch: "├── ", Last: "└── ", Pipe: "│   ", Space: "    "}
+	// GuidesASCII draws with plain ASCII characters.
+	GuidesASCII = TreeGuides{Branch: "|-- ", Last: "`-- ", Pipe: "|   ", Space: "    "}
+)
+
+// Tree is a labelled node with children, drawn as an indented hierarchy.
+type Tree struct {
+	Label    string
+	Children []*Tree
+}
+
+// NewTree returns a tree with a root labelled label.
+func NewTree(label string) *Tree {
+	return &Tree{Label: label}
+}
+
+// Add appends a child labelled label and returns it, so nested levels can
+// be built with chained calls.
+func (t *Tree) Add(label string) *Tree {
+	child := NewTree(label)
+	t.Children = append(t.Children, child)
+	return child
+}
+
+// AddTree appends existing trees as children and returns t.
+func (t *Tree) AddTree(children ...*Tree) *Tree {
+	t.Children = append(t.Children, children...)
+	return t
+}
+
+// String returns the tree drawn with GuidesLight.
+func (t *Tree) String() string {
+	return t.Draw(GuidesLight)
+}
+
+// Draw returns the tree drawn with guides. Labels spanning several lines
+// are indented to line up under their first line.
+func (t *Tree) Draw(guides TreeGuides) string {
+	var b strings.Builder
+	writeLabel(&b, "", "", t.Label)
+	t.draw(&b, guides, "")
+	return b.String()
+}
+
+func (t *Tree) draw(b *strings.Builder, guides TreeGuides, prefix string) {
+	for i, child := range t.Children {
+		guide, indent := guides.Branch, guides.Pipe
+		if i == len(t.Children)-1 {
+			guide, indent = guides.Last, guides.Space
+		}
+		writeLabel(b, prefix+guide, prefix+indent, child.Label)
+		child.draw(b, guides, prefix+indent)
+	}
+}
+
+func writeLabel(b *strings.Builder, first, rest, label string) {
+	for i, line := range strings.Split(label, "\n") {
+		if i == 0 {
+			b.WriteString(first)
+		} else {
+			b.WriteString(rest)
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+}
+
+// Render writes the tree to w. On a terminal it is drawn as String does;
+// otherwise each node is written as a TSV line holding the labels on its
+// path from the root, escapes stripped, which keeps it easy to process
+// with cut, awk and friends.
+func (t *Tree) Render(w io.Writer) error {
+	if f, ok := w.(*os.File); ok && IsTerminal(int(f.Fd())) {
+		_, err := io.WriteString(w, t.String())
+		return err
+	}
+	var b strings.Builder
+	t.paths(&b, nil)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (t *Tree) paths(b *strings.Builder, parents []string) {
+	path := append(parents[:len(parents):len(parents)], tsvReplacer.Replace(StripEscapes(t.Label)))
+	b.WriteString(strings.Join(path, "\t"))
+	b.WriteByte('\n')
+	for _, child := range t.Children {
+		child.paths(b, path)
+	}
+}
@@ -0,0 +1,49 @@
+//go:build !windows
+
+package term
+
+import (
+	"bytes"
+	"testing"
+)
+
+func testTree() *Tree {
+	root := NewTree("root")
+	a := root.Add("a")
+	a.Add("a1")
+	a.Add("\x1b[32ma2\x1b[0m")
+	root.Add("b\nsecond line")
+	return root
+}
+
+func TestTreeString(t *testing.T) {
+	want := "" +
+		"root\n" +
+		"├── a\n" +
+		"│   ├── a1\n" +
+		"│   └── \x1b[32ma2\x1b[0m\n" +
+		"└── b\n" +
+		"    second line\n"
+	if got := testTree().String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTreeDrawASCII(t *testing.T) {
+	tree := NewTree("r").AddTree(NewTree("x"), NewTree("y"))
+	want := "r\n|-- x\n`-- y\n"
+	if got := tree.Draw(GuidesASCII); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTreeRenderPlain(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testTree().Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "root\nroot\ta\nroot\ta\ta1\nroot\ta\ta2\nroot\tb second line\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
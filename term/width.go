@@ -0,0 +1,197 @@
+//go:build !windows
+
+package term
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/width"
+)
+
+// escapeLen returns the length of the escape sequence at the start of s,
+// or 0 if s does not start with one. CSI sequences end at their final
+// byte; OSC sequences end at BEL or ST.
+func escapeLen(s string) int {
+	if len(s) < 2 || s[0] != 0x1b {
+		return 0
+	}
+	switch s[1] {
+	case '[':
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(s)
+	case ']':
+		for i := 2; i < len(s); i++ {
+			if s[i] == 0x07 {
+				return i + 1
+			}
+			if s[i] == 0x1b && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return len(s)
+	}
+	return 2
+}
+
+// RuneWidth returns the number of terminal cells r occupies: 0 for
+// control and combining characters, 2 for East Asian wide and fullwidth
+// characters and 1 for everything else.
+func RuneWidth(r rune) int {
+	switch {
+	case r == 0, r < 0x20, r >= 0x7f && r < 0xa0:
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// StringWidth returns the number of terminal cells s occupies, ignoring
+// embedded escape sequences such as colors and hyperlinks.
+func StringWidth(s string) int {
+	n := 0
+	for i := 0; i < len(s); {
+		if l := escapeLen(s[i:]); l > 0 {
+			i += l
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		n += RuneWidth(r)
+		i += size
+	}
+	return n
+}
+
+// StripEscapes returns s without escape sequences.
+func StripEscapes(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if l := escapeLen(s[i:]); l > 0 {
+			i += l
+			continue
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String()
+}
+
+// Truncate shortens s to at most n cells, ending it with "…" when
+// anything was cut. Escape sequences are kept, and a reset is appended if
+// s contained any, so a cut never leaves a color running.
+func Truncate(s string, n int) string {
+	if StringWidth(s) <= n {
+		return s
+	}
+	if n <= 0 {
+		return ""
+	}
+	var b strings.Builder
+	w, styled := 0, false
+	for i := 0; i < len(s); {
+		if l := escapeLen(s[i:]); l > 0 {
+			b.WriteString(s[i : i+l])
+			styled = true
+			i += l
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		rw := RuneWidth(r)
+		if w+rw > n-1 {
+			break
+		}
+		b.WriteRune(r)
+		w += rw
+		i += size
+	}
+	b.WriteString("…")
+	if styled {
+		b.WriteString(Rst)
+	}
+	return b.String()
+}
+
+// Wrap breaks s into lines of at most n cells, at spaces where it can and
+// inside words that are longer than a line. Existing newlines are kept.
+func Wrap(s string, n int) []string {
+	if n <= 0 {
+		return []string{s}
+	}
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		var line strings.Builder
+		lw := 0
+		for _, word := range strings.Fields(para) {
+			ww := StringWidth(word)
+			if lw > 0 && lw+1+ww <= n {
+				line.WriteByte(' ')
+				line.WriteString(word)
+				lw += 1 + ww
+				continue
+			}
+			if lw > 0 {
+				lines = append(lines, line.String())
+				line.Reset()
+				lw = 0
+			}
+			for ww > n {
+				head, tail := splitWidth(word, n)
+				lines = append(lines, head)
+				word, ww = tail, StringWidth(tail)
+			}
+			line.WriteString(word)
+			lw = ww
+		}
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// splitWidth splits s after at most n cells, keeping escape sequences with
+// the part they precede.
+func splitWidth(s string, n int) (head, tail string) {
+	w := 0
+	for i := 0; i < len(s); {
+		if l := escapeLen(s[i:]); l > 0 {
+			i += l
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		rw := RuneWidth(r)
+		if w+rw > n && w > 0 {
+			return s[:i], s[i:]
+		}
+		w += rw
+		i += size
+	}
+	return s, ""
+}
+
+// pad fills s with spaces to n cells according to align.
+func pad(s string, n int, align Align) string {
+	gap := n - StringWidth(s)
+	if gap <= 0 {
+		return s
+	}
+	switch align {
+	case AlignRight:
+		return strings.Repeat(" ", gap) + s
+	case AlignCenter:
+		left := gap / 2
+		return strings.Repeat(" ", left) + s + strings.Repeat(" ", gap-left)
+	}
+	return s + strings.Repeat(" ", gap)
+}
@@ -0,0 +1,306 @@
+package times
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// date is a calendar day, independent of time zone.
+type date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func dateOf(t time.Time) date {
+	y, m, d := t.Date()
+	return date{y, m, d}
+}
+
+// Holiday is a named non-working day.
+type Holiday struct {
+	Date time.Time
+	Name string
+}
+
+// Calendar knows which days are business days: those that are neither
+// weekend days nor holidays.
+type Calendar struct {
+	loc      *time.Location
+	weekend  [7]bool
+	holidays map[date]string
+}
+
+// CalendarOption configures NewCalendar.
+type CalendarOption func(*calendarOptions)
+
+type calendarOptions struct {
+	loc      *time.Location
+	weekend  []time.Weekday
+	holidays []Holiday
+}
+
+// WithLocation sets the time zone a Calendar uses to split time into
+// days. The default is time.Local.
+func WithLocation(loc *time.Location) CalendarOption {
+	return func(o *calendarOptions) {
+		o.loc = loc
+	}
+}
+
+// WithWeekend sets a Calendar's non-working days of the week. The default
+// is Saturday and Sunday.
+func WithWeekend(days ...time.Weekday) CalendarOption {
+	return func(o *calendarOptions) {
+		o.weekend = append([]time.Weekday{}, days...)
+	}
+}
+
+// WithHolidays adds holidays to a Calendar.
+func WithHolidays(holidays ...Holiday) CalendarOption {
+	return func(o *calendarOptions) {
+		o.holidays = append(o.holidays, holidays...)
+	}
+}
+
+// NewCalendar returns a calendar with a Saturday and Sunday weekend and no
+// holidays, in time.Local.
+func NewCalendar(opts ...CalendarOption) *Calendar {
+	var o calendarOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	c := &Calendar{
+		loc:      time.Local,
+		holidays: map[date]string{},
+	}
+	if o.loc != nil {
+		c.loc = o.loc
+	}
+	if o.weekend != nil {
+		for _, d := range o.weekend {
+			c.weekend[d] = true
+		}
+	} else {
+		c.weekend[time.Saturday] = true
+		c.weekend[time.Sunday] = true
+	}
+	for _, h := range o.holidays {
+		c.AddHoliday(h.Date, h.Name)
+	}
+	return c
+}
+
+// Location returns the time zone of the calendar.
+func (c *Calendar) Location() *time.Location { return c.loc }
+
+// AddHoliday marks the day of t, as written in t's own location, as a
+// holiday called name.
+func (c *Calendar) AddHoliday(t time.Time, name string) {
+	c.holidays[dateOf(t)] = name
+}
+
+// Holidays returns the holidays in [from, to), in date order.
+func (c *Calendar) Holidays(from, to time.Time) []Holiday {
+	var out []Holiday
+	for d := StartOfDay(from, c.loc); d.Before(to); d = d.AddDate(0, 0, 1) {
+		if name, ok := c.holidays[dateOf(d)]; ok {
+			out = append(out, Holiday{Date: d, Name: name})
+		}
+	}
+	return out
+}
+
+// IsHoliday reports whether t falls on a holiday and its name.
+func (c *Calendar) IsHoliday(t time.Time) (string, bool) {
+	name, ok := c.holidays[dateOf(t.In(c.loc))]
+	return name, ok
+}
+
+// IsWeekend reports whether t falls on a weekend day.
+func (c *Calendar) IsWeekend(t time.Time) bool {
+	return c.weekend[t.In(c.loc).Weekday()]
+}
+
+// IsBusinessDay reports whether t falls on neither a weekend day nor a
+// holiday.
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	_, holiday := c.IsHoliday(t)
+	return !holiday && !c.IsWeekend(t)
+}
+
+// AddBusinessDays moves t by n business days, forwards for positive n and
+// backwards for negative n, keeping its time of day. Starting from a
+// non-business day, the first step lands on the nearest business day in
+// that direction. If every day of the week is a weekend day, t is returned
+// unchanged.
+func (c *Calendar) AddBusinessDays(t time.Time, n int) time.Time {
+	if c.weekend == [7]bool{true, true, true, true, true, true, true} {
+		return t
+	}
+	t = t.In(c.loc)
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for n > 0 {
+		t = t.AddDate(0, 0, step)
+		if c.IsBusinessDay(t) {
+			n--
+		}
+	}
+	return t
+}
+
+// BusinessDaysBetween counts the business days from the day of from up to
+// but not including the day of to. It is negative when to is before from.
+func (c *Calendar) BusinessDaysBetween(from, to time.Time) int {
+	sign := 1
+	if to.Before(from) {
+		from, to, sign = to, from, -1
+	}
+	n := 0
+	end := dateOf(to.In(c.loc))
+	for d := StartOfDay(from, c.loc); dateOf(d) != end && d.Before(to); d = d.AddDate(0, 0, 1) {
+		if c.IsBusinessDay(d) {
+			n++
+		}
+	}
+	return sign * n
+}
+
+// LoadYAML adds holidays from a YAML list of dates and names, either at
+// the top level or under a "holidays" key:
+//
+//	holidays:
+//	  - date: 2024-12-25
+//	    name: Christmas Day
+func (c *Calendar) LoadYAML(r io.Reader) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	type entry struct {
+		Date string `yaml:"date"`
+		Name string `yaml:"name"`
+	}
+	var list []entry
+	if err := yaml.Unmarshal(b, &list); err != nil {
+		var doc struct {
+			Holidays []entry `yaml:"holidays"`
+		}
+		if err := yaml.Unmarshal(b, &doc); err != nil {
+			return fmt.Errorf("times: parsing holidays: %w", err)
+		}
+		list = doc.Holidays
+	}
+	for _, e := range list {
+		d, err := time.ParseInLocation(time.DateOnly, e.Date, c.loc)
+		if err != nil {
+			return fmt.Errorf("times: holiday %q: %w", e.Name, err)
+		}
+		c.AddHoliday(d, e.Name)
+	}
+	return nil
+}
+
+// LoadICal adds the days covered by the VEVENTs of an iCalendar (RFC 5545)
+// feed as holidays named by their SUMMARY, expanding recurring events
+// within [from, to). All-day events cover DTSTART up to but not including
+// DTEND; timed events cover the days they touch.
+func (c *Calendar) LoadICal(r io.Reader, from, to time.Time) error {
+	events, err := ReadEvents(r, c.loc)
+	if err != nil {
+		return err
+	}
+	for _, e := range events {
+		for _, o := range e.Occurrences(from, to) {
+			end := o.End
+			if !e.AllDay {
+				// A timed event touches the day it ends on unless it ends
+				// at midnight.
+				if day := StartOfDay(end, c.loc); end.After(day) || end.Equal(o.Start) {
+					end = day.AddDate(0, 0, 1)
+				}
+			}
+			for d := StartOfDay(o.Start, c.loc); d.Before(end); d = d.AddDate(0, 0, 1) {
+				c.AddHoliday(d, e.Summary)
+			}
+		}
+	}
+	return nil
+}
+
+// StartOfDay returns midnight at the start of t's day in loc.
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// EndOfDay returns the last nanosecond of t's day in loc.
+func EndOfDay(t time.Time, loc *time.Location) time.Time {
+	return StartOfDay(t, loc).AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
+
+// StartOfWeek returns midnight on the Monday starting t's ISO week in loc.
+func StartOfWeek(t time.Time, loc *time.Location) time.Time {
+	day := StartOfDay(t, loc)
+	return day.AddDate(0, 0, -int((day.Weekday()+6)%7))
+}
+
+// EndOfWeek returns the last nanosecond of the Sunday ending t's ISO week
+// in loc.
+func EndOfWeek(t time.Time, loc *time.Location) time.Time {
+	return StartOfWeek(t, loc).AddDate(0, 0, 7).Add(-time.Nanosecond)
+}
+
+// StartOfMonth returns midnight on the first of t's month in loc.
+func StartOfMonth(t time.Time, loc *time.Location) time.Time {
+	y, m, _ := t.In(loc).Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, loc)
+}
+
+// EndOfMonth returns the last nanosecond of t's month in loc.
+func EndOfMonth(t time.Time, loc *time.Location) time.Time {
+	return StartOfMonth(t, loc).AddDate(0, 1, 0).Add(-time.Nanosecond)
+}
+
+// StartOfQuarter returns midnight on the first day of t's calendar quarter
+// in loc.
+func StartOfQuarter(t time.Time, loc *time.Location) time.Time {
+	y, m, _ := t.In(loc).Date()
+	return time.Date(y, m-(m-1)%3, 1, 0, 0, 0, 0, loc)
+}
+
+// EndOfQuarter returns the last nanosecond of t's calendar quarter in loc.
+func EndOfQuarter(t time.Time, loc *time.Location) time.Time {
+	return StartOfQuarter(t, loc).AddDate(0, 3, 0).Add(-time.Nanosecond)
+}
+
+// Quarter returns the calendar quarter, 1 to 4, of t.
+func Quarter(t time.Time) int {
+	return (int(t.Month())-1)/3 + 1
+}
+
+// ISOWeekStart returns midnight in loc on the Monday of ISO week week of
+// year. Weeks outside the year carry over into the neighbouring ones.
+func ISOWeekStart(year, week int, loc *time.Location) time.Time {
+	// January 4th is always in week 1.
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	return jan4.AddDate(0, 0, -int((jan4.Weekday()+6)%7)+(week-1)*7)
+}
+
+// ISOWeeksInYear returns 52 or 53, the number of ISO weeks in year.
+func ISOWeeksInYear(year int) int {
+	_, w := time.Date(year, time.December, 28, 0, 0, 0, 0, time.UTC).ISOWeek()
+	return w
+}
+
+// ISOWeekString formats t's ISO week as "2006-W01".
+func ISOWeekString(t time.Time) string {
+	y, w := t.ISOWeek()
+	return fmt.Sprintf("%04d-W%02d", y, w)
+}
@@ -0,0 +1,201 @@
+package times
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendarBusinessDays(t *testing.T) {
+	day := func(m time.Month, d int) time.Time {
+		return time.Date(2024, m, d, 9, 30, 0, 0, time.UTC)
+	}
+	c := NewCalendar(WithLocation(time.UTC), WithHolidays(
+		Holiday{Date: day(time.December, 25), Name: "Christmas Day"},
+		Holiday{Date: day(time.December, 26), Name: "Boxing Day"},
+	))
+
+	if !c.IsBusinessDay(day(time.December, 24)) {
+		t.Error("Tuesday Dec 24th should be a business day")
+	}
+	if name, ok := c.IsHoliday(day(time.December, 25)); !ok || name != "Christmas Day" {
+		t.Errorf("IsHoliday(Dec 25th) = %q, %v", name, ok)
+	}
+	if c.IsBusinessDay(day(time.December, 28)) || !c.IsWeekend(day(time.December, 28)) {
+		t.Error("Saturday Dec 28th should be a weekend day")
+	}
+
+	tests := []struct {
+		from time.Time
+		n    int
+		want time.Time
+	}{
+		{day(time.December, 24), 1, day(time.December, 27)},
+		{day(time.December, 24), 3, day(time.December, 31)},
+		{day(time.December, 27), -1, day(time.December, 24)},
+		{day(time.December, 28), 1, day(time.December, 30)},
+		{day(time.December, 28), -1, day(time.December, 27)},
+		{day(time.December, 24), 0, day(time.December, 24)},
+	}
+	for _, tt := range tests {
+		if got := c.AddBusinessDays(tt.from, tt.n); !got.Equal(tt.want) {
+			t.Errorf("AddBusinessDays(%v, %d) = %v, want %v", tt.from, tt.n, got, tt.want)
+		}
+	}
+
+	if n := c.BusinessDaysBetween(day(time.December, 23), day(time.December, 31)); n != 4 {
+		t.Errorf("BusinessDaysBetween = %d, want 4", n)
+	}
+	if n := c.BusinessDaysBetween(day(time.December, 31), day(time.December, 23)); n != -4 {
+		t.Errorf("reversed BusinessDaysBetween = %d, want -4", n)
+	}
+	if n := c.BusinessDaysBetween(day(time.December, 23), day(time.December, 23)); n != 0 {
+		t.Errorf("same-day BusinessDaysBetween = %d, want 0", n)
+	}
+	if hs := c.Holidays(day(time.December, 1), day(time.December, 31)); len(hs) != 2 || hs[1].Name != "Boxing Day" {
+		t.Errorf("Holidays = %v", hs)
+	}
+}
+
+func TestCalendarWeekend(t *testing.T) {
+	// A Friday and Saturday weekend.
+	c := NewCalendar(WithLocation(time.UTC), WithWeekend(time.Friday, time.Saturday))
+	thu := time.Date(2024, time.May, 16, 0, 0, 0, 0, time.UTC)
+	if got, want := c.AddBusinessDays(thu, 1), thu.AddDate(0, 0, 3); !got.Equal(want) {
+		t.Errorf("AddBusinessDays = %v, want %v", got, want)
+	}
+	if c := NewCalendar(WithWeekend(time.Sunday, time.Monday, time.Tuesday, time.Wednesday,
+		time.Thursday, time.Friday, time.Saturday)); !c.AddBusinessDays(thu, 5).Equal(thu) {
+		t.Error("AddBusinessDays without business days should return t")
+	}
+}
+
+func TestCalendarLocation(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	c := NewCalendar(WithLocation(tokyo))
+	// Friday 20:00 UTC is Saturday morning in Tokyo.
+	fri := time.Date(2024, time.May, 17, 20, 0, 0, 0, time.UTC)
+	if c.IsBusinessDay(fri) {
+		t.Error("Friday evening UTC is Saturday in Tokyo")
+	}
+}
+
+func TestCalendarLoadYAML(t *testing.T) {
+	for _, doc := range []string{
+		"- date: 2024-01-01\n  name: New Year's Day\n- date: 2024-07-04\n  name: Independence Day\n",
+		"holidays:\n  - date: 2024-01-01\n    name: New Year's Day\n  - date: 2024-07-04\n    name: Independence Day\n",
+	} {
+		c := NewCalendar(WithLocation(time.UTC))
+		if err := c.LoadYAML(strings.NewReader(doc)); err != nil {
+			t.Fatal(err)
+		}
+		if name, ok := c.IsHoliday(time.Date(2024, time.July, 4, 12, 0, 0, 0, time.UTC)); !ok || name != "Independence Day" {
+			t.Errorf("IsHoliday(Jul 4th) = %q, %v", name, ok)
+		}
+	}
+	c := NewCalendar()
+	if err := c.LoadYAML(strings.NewReader("- date: 4th of July\n")); err == nil {
+		t.Error("LoadYAML accepted a bad date")
+	}
+}
+
+func TestCalendarLoadICal(t *testing.T) {
+	const ics = "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20241225\r\n" +
+		"DTEND;VALUE=DATE:20241227\r\n" +
+		"SUMMARY:Christmas\\, and\r\n" +
+		"  Boxing Day\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART:20240527T000000Z\r\n" +
+		"SUMMARY:Memorial Day\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20200704\r\n" +
+		"RRULE:FREQ=YEARLY\r\n" +
+		"SUMMARY:Independence Day\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	c := NewCalendar(WithLocation(time.UTC))
+	from, to := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if err := c.LoadICal(strings.NewReader(ics), from, to); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		day  time.Time
+		name string
+		ok   bool
+	}{
+		{time.Date(2024, time.December, 25, 0, 0, 0, 0, time.UTC), "Christmas, and Boxing Day", true},
+		{time.Date(2024, time.December, 26, 0, 0, 0, 0, time.UTC), "Christmas, and Boxing Day", true},
+		{time.Date(2024, time.December, 27, 0, 0, 0, 0, time.UTC), "", false},
+		{time.Date(2024, time.May, 27, 15, 0, 0, 0, time.UTC), "Memorial Day", true},
+		{time.Date(2025, time.July, 4, 0, 0, 0, 0, time.UTC), "Independence Day", true},
+		// Recurrences are only expanded within the window.
+		{time.Date(2026, time.July, 4, 0, 0, 0, 0, time.UTC), "", false},
+	} {
+		if name, ok := c.IsHoliday(tt.day); ok != tt.ok || name != tt.name {
+			t.Errorf("IsHoliday(%v) = %q, %v, want %q, %v", tt.day, name, ok, tt.name, tt.ok)
+		}
+	}
+
+	bad := "BEGIN:VEVENT\nDTSTART:tomorrow\nEND:VEVENT\n"
+	if err := NewCalendar().LoadICal(strings.NewReader(bad), from, to); !errors.Is(err, ErrInvalidICal) {
+		t.Errorf("LoadICal(bad) = %v, want ErrInvalidICal", err)
+	}
+}
+
+func TestPeriodBoundaries(t *testing.T) {
+	// Wednesday.
+	at := time.Date(2024, time.August, 14, 15, 4, 5, 0, time.UTC)
+	last := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 23, 59, 59, 999999999, time.UTC)
+	}
+	tests := []struct {
+		name string
+		got  time.Time
+		want time.Time
+	}{
+		{"StartOfDay", StartOfDay(at, time.UTC), time.Date(2024, time.August, 14, 0, 0, 0, 0, time.UTC)},
+		{"EndOfDay", EndOfDay(at, time.UTC), last(2024, time.August, 14)},
+		{"StartOfWeek", StartOfWeek(at, time.UTC), time.Date(2024, time.August, 12, 0, 0, 0, 0, time.UTC)},
+		{"EndOfWeek", EndOfWeek(at, time.UTC), last(2024, time.August, 18)},
+		{"StartOfMonth", StartOfMonth(at, time.UTC), time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC)},
+		{"EndOfMonth", EndOfMonth(at, time.UTC), last(2024, time.August, 31)},
+		{"StartOfQuarter", StartOfQuarter(at, time.UTC), time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		{"EndOfQuarter", EndOfQuarter(at, time.UTC), last(2024, time.September, 30)},
+		{"StartOfWeek Sunday", StartOfWeek(time.Date(2024, time.August, 18, 12, 0, 0, 0, time.UTC), time.UTC),
+			time.Date(2024, time.August, 12, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if !tt.got.Equal(tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if q := Quarter(at); q != 3 {
+		t.Errorf("Quarter = %d, want 3", q)
+	}
+}
+
+func TestISOWeeks(t *testing.T) {
+	if got, want := ISOWeekStart(2021, 1, time.UTC), time.Date(2021, time.January, 4, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("ISOWeekStart(2021, 1) = %v, want %v", got, want)
+	}
+	if got, want := ISOWeekStart(2020, 53, time.UTC), time.Date(2020, time.December, 28, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("ISOWeekStart(2020, 53) = %v, want %v", got, want)
+	}
+	for year, want := range map[int]int{2015: 53, 2019: 52, 2020: 53, 2024: 52, 2026: 53} {
+		if got := ISOWeeksInYear(year); got != want {
+			t.Errorf("ISOWeeksInYear(%d) = %d, want %d", year, got, want)
+		}
+	}
+	if s := ISOWeekString(time.Date(2021, time.January, 3, 0, 0, 0, 0, time.UTC)); s != "2020-W53" {
+		t.Errorf("ISOWeekString = %q, want 2020-W53", s)
+	}
+}
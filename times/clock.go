@@ -0,0 +1,273 @@
+package times
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and makes timers. Code that takes a Clock can be
+// tested with a FakeClock instead of waiting on the wall clock.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is the Clock counterpart of time.Timer. Like time.Timer since Go
+// 1.23, Stop and Reset discard a value not yet received from C.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker is the Clock counterpart of time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+	Reset(d time.Duration)
+}
+
+// RealClock returns the Clock backed by the time package.
+func RealClock() Clock { return realClock{} }
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) NewTimer(d time.Duration) Timer         { return realTimer{time.NewTimer(d)} }
+func (realClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+
+type realTimer struct{ *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.Timer.C }
+
+type realTicker struct{ *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// FakeClock is a Clock that only moves when told to. Timers and tickers
+// fire, in order, as Advance or Set carries the clock past them. Sends
+// never block: as with real tickers, a tick is dropped when the previous
+// one has not been received.
+type FakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeTimer
+}
+
+// NewFakeClock returns a FakeClock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the fake time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Since returns the fake time elapsed since t.
+func (c *FakeClock) Since(t time.Time) time.Duration { return c.Now().Sub(t) }
+
+// After returns a channel that receives the fake time once it has moved
+// on by d.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time { return c.NewTimer(d).C() }
+
+// Sleep blocks until another goroutine moves the clock on by d.
+func (c *FakeClock) Sleep(d time.Duration) { <-c.After(d) }
+
+// NewTimer returns a timer that fires once the clock has moved on by d.
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	return c.add(d, 0)
+}
+
+// NewTicker returns a ticker that fires every d of fake time. It panics if
+// d is not positive, like time.NewTicker.
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("times: non-positive interval for NewTicker")
+	}
+	return fakeTicker{c.add(d, d)}
+}
+
+// Advance moves the clock on by d, firing timers and tickers that fall
+// due on the way.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(c.now.Add(d))
+}
+
+// Set moves the clock to t, firing timers and tickers that fall due on the
+// way. Moving it backwards fires nothing.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(t)
+}
+
+// BlockUntil waits until at least n timers and tickers are pending, so a
+// test can be sure the code under test is waiting before it advances the
+// clock.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
+
+// set fires waiters due by t in time order. A ticker sends at most one
+// tick however many periods t skips, as its channel holds one like that
+// of time.Ticker, and its next tick moves to the first one after t.
+// c.mu is held.
+func (c *FakeClock) set(t time.Time) {
+	sort.SliceStable(c.waiters, func(i, j int) bool {
+		return c.waiters[i].when.Before(c.waiters[j].when)
+	})
+	var due []*fakeTimer
+	for _, w := range c.waiters {
+		if w.when.After(t) {
+			break
+		}
+		due = append(due, w)
+	}
+	for _, w := range due {
+		select {
+		case w.c <- w.when:
+		default:
+		}
+		if w.period > 0 {
+			w.when = w.when.Add((t.Sub(w.when)/w.period + 1) * w.period)
+		} else {
+			c.remove(w)
+		}
+	}
+	c.now = t
+}
+
+func (c *FakeClock) add(d, period time.Duration) *fakeTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &fakeTimer{clock: c, c: make(chan time.Time, 1), period: period}
+	c.schedule(w, d)
+	return w
+}
+
+// schedule makes w due d from now, firing it at once if d is not positive.
+// c.mu is held.
+func (c *FakeClock) schedule(w *fakeTimer, d time.Duration) {
+	w.when = c.now.Add(d)
+	if d <= 0 && w.period == 0 {
+		w.c <- c.now
+		return
+	}
+	c.waiters = append(c.waiters, w)
+	c.cond.Broadcast()
+}
+
+// remove drops w and reports whether it was pending. c.mu is held.
+func (c *FakeClock) remove(w *fakeTimer) bool {
+	for i, other := range c.waiters {
+		if other == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct {
+	clock  *FakeClock
+	c      chan time.Time
+	when   time.Time
+	period time.Duration
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.drain()
+	return t.clock.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.drain()
+	active := t.clock.remove(t)
+	t.clock.schedule(t, d)
+	return active
+}
+
+func (t *fakeTimer) drain() {
+	select {
+	case <-t.c:
+	default:
+	}
+}
+
+type fakeTicker struct{ t *fakeTimer }
+
+func (t fakeTicker) C() <-chan time.Time { return t.t.c }
+func (t fakeTicker) Stop()               { t.t.Stop() }
+
+func (t fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("times: non-positive interval for Ticker.Reset")
+	}
+	t.t.clock.mu.Lock()
+	defer t.t.clock.mu.Unlock()
+	t.t.period = d
+	t.t.clock.remove(t.t)
+	t.t.clock.schedule(t.t, d)
+}
+
+// ClockOption sets the Clock used in place of the time package. It is
+// accepted by IsWithinDays, DateSince, WriteEvents and NewScheduler, the
+// functions that read the current time.
+type ClockOption struct {
+	clock Clock
+}
+
+// WithClock sets the Clock used in place of the time package, so tests can
+// pass a FakeClock. The default is RealClock.
+func WithClock(c Clock) ClockOption {
+	return ClockOption{clock: c}
+}
+
+// clockOf returns the clock set by the last of opts, or RealClock.
+func clockOf(opts []ClockOption) Clock {
+	c := RealClock()
+	for _, o := range opts {
+		if o.clock != nil {
+			c = o.clock
+		}
+	}
+	return c
+}
+
+func (o ClockOption) applyDateSince(d *dateSinceOptions) {
+	if o.clock != nil {
+		d.clock = o.clock
+	}
+}
+
+func (o ClockOption) applyScheduler(s *Scheduler) {
+	if o.clock != nil {
+		s.clock = o.clock
+	}
+}
@@ -0,0 +1,172 @@
+package times
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2024, time.May, 15, 12, 0, 0, 0, time.UTC)
+
+// received returns what ch holds without waiting.
+func received(ch <-chan time.Time) (time.Time, bool) {
+	select {
+	case t := <-ch:
+		return t, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestFakeClockTimer(t *testing.T) {
+	c := NewFakeClock(epoch)
+	timer := c.NewTimer(time.Minute)
+	c.Advance(59 * time.Second)
+	if _, ok := received(timer.C()); ok {
+		t.Fatal("timer fired early")
+	}
+	c.Advance(time.Second)
+	if at, ok := received(timer.C()); !ok || !at.Equal(epoch.Add(time.Minute)) {
+		t.Fatalf("timer = %v, %v, want %v", at, ok, epoch.Add(time.Minute))
+	}
+	if timer.Stop() {
+		t.Error("Stop on a fired timer reported true")
+	}
+	if timer.Reset(time.Second) {
+		t.Error("Reset on a fired timer reported true")
+	}
+	if !timer.Stop() {
+		t.Error("Stop on a pending timer reported false")
+	}
+	c.Advance(time.Hour)
+	if _, ok := received(timer.C()); ok {
+		t.Error("stopped timer fired")
+	}
+	if _, ok := received(c.After(0)); !ok {
+		t.Error("After(0) should fire at once")
+	}
+}
+
+func TestFakeClockTicker(t *testing.T) {
+	c := NewFakeClock(epoch)
+	ticker := c.NewTicker(10 * time.Second)
+	var ticks []time.Time
+	for range 3 {
+		c.Advance(10 * time.Second)
+		at, ok := received(ticker.C())
+		if !ok {
+			t.Fatal("ticker did not fire")
+		}
+		ticks = append(ticks, at)
+	}
+	if !ticks[2].Equal(epoch.Add(30 * time.Second)) {
+		t.Errorf("ticks = %v", ticks)
+	}
+	// Unreceived ticks are dropped.
+	c.Advance(time.Minute)
+	if at, ok := received(ticker.C()); !ok || !at.Equal(epoch.Add(40*time.Second)) {
+		t.Errorf("tick after a long advance = %v, %v", at, ok)
+	}
+	if _, ok := received(ticker.C()); ok {
+		t.Error("ticker buffered more than one tick")
+	}
+	ticker.Reset(time.Hour)
+	c.Advance(time.Minute)
+	if _, ok := received(ticker.C()); ok {
+		t.Error("ticker fired before its reset interval")
+	}
+	ticker.Stop()
+	c.Advance(2 * time.Hour)
+	if _, ok := received(ticker.C()); ok {
+		t.Error("stopped ticker fired")
+	}
+}
+
+func TestFakeClockAdvanceFastTicker(t *testing.T) {
+	c := NewFakeClock(epoch)
+	ticker := c.NewTicker(time.Millisecond)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Advance(24 * time.Hour)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Advance(24h) with a 1ms ticker did not return")
+	}
+	if at, ok := received(ticker.C()); !ok || !at.Equal(epoch.Add(time.Millisecond)) {
+		t.Errorf("tick = %v, %v, want %v", at, ok, epoch.Add(time.Millisecond))
+	}
+	if _, ok := received(ticker.C()); ok {
+		t.Error("ticker buffered more than one tick")
+	}
+	// The next tick is the first period boundary after the advance.
+	c.Advance(time.Millisecond)
+	if at, ok := received(ticker.C()); !ok || !at.Equal(epoch.Add(24*time.Hour+time.Millisecond)) {
+		t.Errorf("next tick = %v, %v, want %v", at, ok, epoch.Add(24*time.Hour+time.Millisecond))
+	}
+}
+
+func TestFakeClockSetAndSleep(t *testing.T) {
+	c := NewFakeClock(epoch)
+	done := make(chan struct{})
+	go func() {
+		c.Sleep(time.Hour)
+		close(done)
+	}()
+	c.BlockUntil(1)
+	c.Set(epoch.Add(2 * time.Hour))
+	<-done
+	if got := c.Since(epoch); got != 2*time.Hour {
+		t.Errorf("Since = %v, want 2h", got)
+	}
+	c.Set(epoch)
+	if !c.Now().Equal(epoch) {
+		t.Errorf("Now after Set = %v", c.Now())
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	c := NewFakeClock(epoch)
+	if got, want := DateSince(2023, time.March, 15, time.UTC, WithClock(c)), "1 year 2 months 12 hours"; got != want {
+		t.Errorf("DateSince = %q, want %q", got, want)
+	}
+	if got, want := DateSince(2023, time.March, 15, time.UTC, WithClock(c), WithMaxUnits(1), WithAbbreviations()), "1y"; got != want {
+		t.Errorf("DateSince with HumanizeWith options = %q, want %q", got, want)
+	}
+	if !IsWithinDays(epoch.AddDate(0, 0, 2), 3, WithClock(c)) {
+		t.Error("two days ahead should be within 3 days")
+	}
+	c.Advance(48 * time.Hour)
+	if IsWithinDays(epoch.AddDate(0, 0, 1), 3, WithClock(c)) {
+		t.Error("yesterday should not be within 3 days")
+	}
+}
+
+func TestSchedulerFakeClock(t *testing.T) {
+	c := NewFakeClock(epoch)
+	s := NewScheduler(WithClock(c))
+	runs := make(chan time.Time, 10)
+	if err := s.Add("hourly", "TZ=UTC @hourly", func(context.Context) error {
+		runs <- c.Now()
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+	for i := range 3 {
+		c.BlockUntil(1)
+		c.Advance(time.Hour)
+		if at := <-runs; !at.Equal(epoch.Add(time.Duration(i+1) * time.Hour)) {
+			t.Errorf("run %d at %v", i, at)
+		}
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run = %v, want context.Canceled", err)
+	}
+}
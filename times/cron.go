@@ -0,0 +1,463 @@
+package times
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCron is returned by ParseCron for malformed expressions.
+var ErrInvalidCron = errors.New("times: invalid cron expression")
+
+// cronMacros are the @ shorthands ParseCron accepts, in 6-field form.
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+var (
+	monthNames = map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}
+	dayNames = map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}
+)
+
+// cronField describes the values one field may take.
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	secondField = cronField{"second", 0, 59, nil}
+	minuteField = cronField{"minute", 0, 59, nil}
+	hourField   = cronField{"hour", 0, 23, nil}
+	domField    = cronField{"day of month", 1, 31, nil}
+	monthField  = cronField{"month", 1, 12, monthNames}
+	dowField    = cronField{"day of week", 0, 7, dayNames}
+)
+
+// dayRule is a day of month or day of week extension such as L, 15W or
+// 5#3, given the date and the number of days in its month.
+type dayRule func(day int, wd time.Weekday, last int) bool
+
+// Schedule is a parsed cron expression. Its zero value is not useful; use
+// ParseCron.
+type Schedule struct {
+	expr               string
+	loc                *time.Location
+	every              time.Duration
+	second, minute     uint64
+	hour, dom, month   uint64
+	dow                uint64
+	domRules, dowRules []dayRule
+	domStar, dowStar   bool
+}
+
+// ParseCron parses a cron expression:
+//
+//   - five fields (minute hour day-of-month month day-of-week) or six with
+//     a leading seconds field
+//   - lists, ranges and steps ("1,15", "9-17", "*/5", "10-50/10"), and
+//     month and weekday names ("JAN", "MON-FRI"); Sunday is 0 or 7
+//   - @yearly, @annually, @monthly, @weekly, @daily, @midnight, @hourly,
+//     and "@every <duration>"
+//   - day of month "L" (last day), "L-3" (three days before it), "15W"
+//     (weekday nearest the 15th) and "LW" (last weekday)
+//   - day of week "5L" (last Friday of the month) and "FRI#3" (third
+//     Friday)
+//   - a "TZ=Area/City " or "CRON_TZ=Area/City " prefix; the default is
+//     the local time zone
+//
+// As in Vixie cron, when both day fields are restricted a day matching
+// either one matches.
+func ParseCron(expr string) (*Schedule, error) {
+	s := &Schedule{expr: expr, loc: time.Local}
+	spec := strings.TrimSpace(expr)
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		tz, rest, _ := strings.Cut(spec, " ")
+		loc, err := time.LoadLocation(tz[strings.Index(tz, "=")+1:])
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidCron, expr, err)
+		}
+		s.loc, spec = loc, strings.TrimSpace(rest)
+	}
+	if d, ok := strings.CutPrefix(spec, "@every "); ok {
+		every, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil || every <= 0 {
+			return nil, fmt.Errorf("%w %q: bad @every duration", ErrInvalidCron, expr)
+		}
+		s.every = every
+		return s, nil
+	}
+	if m, ok := cronMacros[strings.ToLower(spec)]; ok {
+		spec = m
+	}
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("%w %q: want 5 or 6 fields, got %d", ErrInvalidCron, expr, len(fields))
+	}
+	var err error
+	parse := func(dst *uint64, s string, f cronField) {
+		if err == nil {
+			*dst, err = parseCronField(s, f)
+		}
+	}
+	parse(&s.second, fields[0], secondField)
+	parse(&s.minute, fields[1], minuteField)
+	parse(&s.hour, fields[2], hourField)
+	parse(&s.month, fields[4], monthField)
+	if err == nil {
+		err = s.parseDays(fields[3], fields[5])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %w", ErrInvalidCron, expr, err)
+	}
+	return s, nil
+}
+
+// MustParseCron is ParseCron that panics on error, for expressions known
+// at compile time.
+func MustParseCron(expr string) *Schedule {
+	s, err := ParseCron(expr)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// String returns the expression the schedule was parsed from.
+func (s *Schedule) String() string { return s.expr }
+
+// Location returns the time zone the schedule is evaluated in.
+func (s *Schedule) Location() *time.Location { return s.loc }
+
+func parseCronField(s string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		b, err := parseCronRange(part, f)
+		if err != nil {
+			return 0, err
+		}
+		bits |= b
+	}
+	return bits, nil
+}
+
+func parseCronRange(s string, f cronField) (uint64, error) {
+	rng, stepStr, hasStep := strings.Cut(s, "/")
+	lo, hi := f.min, f.max
+	switch {
+	case rng == "*" || rng == "?":
+	case strings.Contains(rng, "-"):
+		a, b, _ := strings.Cut(rng, "-")
+		var err error
+		if lo, err = cronValue(a, f); err != nil {
+			return 0, err
+		}
+		if hi, err = cronValue(b, f); err != nil {
+			return 0, err
+		}
+	default:
+		v, err := cronValue(rng, f)
+		if err != nil {
+			return 0, err
+		}
+		lo, hi = v, v
+		if hasStep {
+			hi = f.max
+		}
+	}
+	step := 1
+	if hasStep {
+		n, err := strconv.Atoi(stepStr)
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("%s: bad step %q", f.name, stepStr)
+		}
+		step = n
+	}
+	if lo > hi {
+		return 0, fmt.Errorf("%s: range %q is backwards", f.name, s)
+	}
+	var bits uint64
+	for v := lo; v <= hi; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits, nil
+}
+
+func cronValue(s string, f cronField) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s: bad value %q", f.name, s)
+	}
+	return v, nil
+}
+
+// parseDays parses the day of month and day of week fields, splitting out
+// the L, W and # extensions.
+func (s *Schedule) parseDays(dom, dow string) error {
+	s.domStar = dom == "*" || dom == "?"
+	s.dowStar = dow == "*" || dow == "?"
+	var plain []string
+	for _, part := range strings.Split(dom, ",") {
+		rule, ok, err := domRule(strings.ToUpper(part))
+		if err != nil {
+			return err
+		}
+		if ok {
+			s.domRules = append(s.domRules, rule)
+		} else {
+			plain = append(plain, part)
+		}
+	}
+	if len(plain) > 0 {
+		bits, err := parseCronField(strings.Join(plain, ","), domField)
+		if err != nil {
+			return err
+		}
+		s.dom = bits
+	}
+	plain = plain[:0]
+	for _, part := range strings.Split(dow, ",") {
+		rule, ok, err := dowRule(strings.ToLower(part))
+		if err != nil {
+			return err
+		}
+		if ok {
+			s.dowRules = append(s.dowRules, rule)
+		} else {
+			plain = append(plain, part)
+		}
+	}
+	if len(plain) > 0 {
+		bits, err := parseCronField(strings.Join(plain, ","), dowField)
+		if err != nil {
+			return err
+		}
+		if bits&(1<<7) != 0 {
+			bits |= 1
+		}
+		s.dow = bits
+	}
+	return nil
+}
+
+// domRule returns the rule for L, L-n, nW or LW; ok is false for plain
+// values.
+func domRule(s string) (rule dayRule, ok bool, err error) {
+	switch {
+	case s == "L":
+		return func(day int, _ time.Weekday, last int) bool { return day == last }, true, nil
+	case s == "LW":
+		return func(day int, wd time.Weekday, last int) bool {
+			return day == nearestWeekday(last, wd, day, last)
+		}, true, nil
+	case strings.HasPrefix(s, "L-"):
+		n, err := strconv.Atoi(s[2:])
+		if err != nil || n < 1 || n > 30 {
+			return nil, false, fmt.Errorf("day of month: bad offset %q", s)
+		}
+		return func(day int, _ time.Weekday, last int) bool { return day == last-n }, true, nil
+	case strings.HasSuffix(s, "W"):
+		n, err := strconv.Atoi(strings.TrimSuffix(s, "W"))
+		if err != nil || n < 1 || n > 31 {
+			return nil, false, fmt.Errorf("day of month: bad weekday %q", s)
+		}
+		return func(day int, wd time.Weekday, last int) bool {
+			return n <= last && day == nearestWeekday(n, wd, day, last)
+		}, true, nil
+	}
+	return nil, false, nil
+}
+
+// nearestWeekday returns the Monday to Friday day of the month closest to
+// target without leaving the month, given that day falls on wd.
+func nearestWeekday(target int, wd time.Weekday, day, last int) int {
+	twd := time.Weekday((int(wd) + (target-day)%7 + 7) % 7)
+	switch twd {
+	case time.Saturday:
+		if target == 1 {
+			return target + 2
+		}
+		return target - 1
+	case time.Sunday:
+		if target == last {
+			return target - 2
+		}
+		return target + 1
+	}
+	return target
+}
+
+// dowRule returns the rule for nL or n#k; ok is false for plain values.
+func dowRule(s string) (rule dayRule, ok bool, err error) {
+	if a, b, ok := strings.Cut(s, "#"); ok {
+		wd, err := cronValue(a, dowField)
+		if err != nil {
+			return nil, false, err
+		}
+		k, err := strconv.Atoi(b)
+		if err != nil || k < 1 || k > 5 {
+			return nil, false, fmt.Errorf("day of week: bad occurrence %q", s)
+		}
+		return func(day int, w time.Weekday, _ int) bool {
+			return int(w) == wd%7 && (day-1)/7+1 == k
+		}, true, nil
+	}
+	if a, ok := strings.CutSuffix(s, "l"); ok && a != "" {
+		wd, err := cronValue(a, dowField)
+		if err != nil {
+			return nil, false, err
+		}
+		return func(day int, w time.Weekday, last int) bool {
+			return int(w) == wd%7 && day+7 > last
+		}, true, nil
+	}
+	return nil, false, nil
+}
+
+func (s *Schedule) dayMatches(w time.Time) bool {
+	day, wd := w.Day(), w.Weekday()
+	last := time.Date(w.Year(), w.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	dom := s.dom&(1<<uint(day)) != 0
+	for _, r := range s.domRules {
+		dom = dom || r(day, wd, last)
+	}
+	dow := s.dow&(1<<uint(wd)) != 0
+	for _, r := range s.dowRules {
+		dow = dow || r(day, wd, last)
+	}
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// cronHorizon bounds how far Next searches before giving up on a schedule
+// that never fires, such as the 30th of February.
+const cronHorizon = 5
+
+// Next returns the first time after after that matches the schedule, in
+// the schedule's location, or the zero time if there is none within five
+// years.
+//
+// Daylight saving time changes are handled the way Vixie cron does.
+// Schedules that run every hour follow real time: they skip the hour that
+// does not exist and run again in the hour that repeats. Schedules for
+// specific hours run once per day: a time skipped when the clock jumps
+// forward fires at the moment it jumps, and a time repeated when it falls
+// back fires on its first occurrence only.
+func (s *Schedule) Next(after time.Time) time.Time {
+	if s.every > 0 {
+		return after.Add(s.every)
+	}
+	after = after.In(s.loc)
+	if s.hour == allHours {
+		return s.nextInstant(after)
+	}
+	// w walks wall clock time; UTC has no gaps or repeats to get in the way.
+	w := wall(after).Truncate(time.Second).Add(time.Second)
+	limit := w.AddDate(cronHorizon, 0, 0)
+	for w.Before(limit) {
+		y, m, d := w.Date()
+		switch {
+		case s.month&(1<<uint(m)) == 0:
+			w = time.Date(y, m+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.dayMatches(w):
+			w = time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+		case s.hour&(1<<uint(w.Hour())) == 0:
+			w = time.Date(y, m, d, w.Hour()+1, 0, 0, 0, time.UTC)
+		case s.minute&(1<<uint(w.Minute())) == 0:
+			w = time.Date(y, m, d, w.Hour(), w.Minute()+1, 0, 0, time.UTC)
+		case s.second&(1<<uint(w.Second())) == 0:
+			w = w.Add(time.Second)
+		default:
+			if t := s.resolve(w); t.After(after) {
+				return t
+			}
+			w = w.Add(time.Second)
+		}
+	}
+	return time.Time{}
+}
+
+// allHours is the hour field of a schedule that runs every hour.
+const allHours = 1<<24 - 1
+
+// nextInstant is Next for schedules that run every hour, walking real time
+// rather than wall clock time.
+func (s *Schedule) nextInstant(after time.Time) time.Time {
+	t := after.Truncate(time.Second).Add(time.Second)
+	limit := t.AddDate(cronHorizon, 0, 0)
+	for t.Before(limit) {
+		y, m, d := t.Date()
+		switch {
+		case s.month&(1<<uint(m)) == 0:
+			t = time.Date(y, m+1, 1, 0, 0, 0, 0, s.loc)
+		case !s.dayMatches(t):
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, s.loc)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Truncate(time.Minute).Add(time.Minute)
+		case s.second&(1<<uint(t.Second())) == 0:
+			t = t.Add(time.Second)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// wall returns the wall clock reading of t as a UTC time.
+func wall(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// resolve returns the instant the wall clock in s.loc first reads w, or
+// the instant it jumps past w if it never does.
+func (s *Schedule) resolve(w time.Time) time.Time {
+	t := time.Date(w.Year(), w.Month(), w.Day(), w.Hour(), w.Minute(), w.Second(), 0, s.loc)
+	if wall(t).Equal(w) {
+		// If the clock was set back over w, prefer the earlier reading.
+		_, off := t.Zone()
+		if _, before := t.Add(-3 * time.Hour).Zone(); before > off {
+			if e := t.Add(-time.Duration(before-off) * time.Second); wall(e).Equal(w) {
+				return e
+			}
+		}
+		return t
+	}
+	// w falls in a gap: find the first instant whose wall clock is past it.
+	lo, hi := t.Add(-3*time.Hour), t
+	if !wall(hi).After(w) {
+		hi = t.Add(3 * time.Hour)
+	}
+	for hi.Sub(lo) > time.Second {
+		mid := lo.Add(hi.Sub(lo) / 2).Truncate(time.Second)
+		if wall(mid).After(w) {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi
+}
@@ -0,0 +1,129 @@
+package times
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	utc := func(y int, m time.Month, d, h, mi, s int) time.Time {
+		return time.Date(y, m, d, h, mi, s, 0, time.UTC)
+	}
+	// Wednesday.
+	from := utc(2024, time.May, 15, 10, 17, 30)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"TZ=UTC * * * * *", utc(2024, time.May, 15, 10, 18, 0)},
+		{"TZ=UTC */15 * * * *", utc(2024, time.May, 15, 10, 30, 0)},
+		{"TZ=UTC */10 * * * * *", utc(2024, time.May, 15, 10, 17, 40)},
+		{"TZ=UTC 30 7 * * MON-FRI", utc(2024, time.May, 16, 7, 30, 0)},
+		{"TZ=UTC 0 9 * * sat,sun", utc(2024, time.May, 18, 9, 0, 0)},
+		{"TZ=UTC 0 0 * * 7", utc(2024, time.May, 19, 0, 0, 0)},
+		{"CRON_TZ=UTC @daily", utc(2024, time.May, 16, 0, 0, 0)},
+		{"TZ=UTC @hourly", utc(2024, time.May, 15, 11, 0, 0)},
+		{"TZ=UTC @monthly", utc(2024, time.June, 1, 0, 0, 0)},
+		{"TZ=UTC @yearly", utc(2025, time.January, 1, 0, 0, 0)},
+		{"TZ=UTC @weekly", utc(2024, time.May, 19, 0, 0, 0)},
+		{"TZ=UTC 0 12 L * *", utc(2024, time.May, 31, 12, 0, 0)},
+		{"TZ=UTC 0 12 L-2 * *", utc(2024, time.May, 29, 12, 0, 0)},
+		{"TZ=UTC 0 12 L 2 *", utc(2025, time.February, 28, 12, 0, 0)},
+		// June 15th 2024 is a Saturday; the nearest weekday is Friday 14th.
+		{"TZ=UTC 0 0 15W 6 *", utc(2024, time.June, 14, 0, 0, 0)},
+		// June 1st 2024 is a Saturday; 1W does not leave the month.
+		{"TZ=UTC 0 0 1W 6 *", utc(2024, time.June, 3, 0, 0, 0)},
+		// June 30th 2024 is a Sunday.
+		{"TZ=UTC 0 0 LW 6 *", utc(2024, time.June, 28, 0, 0, 0)},
+		{"TZ=UTC 0 0 * * 5L", utc(2024, time.May, 31, 0, 0, 0)},
+		{"TZ=UTC 0 0 * * FRI#3", utc(2024, time.May, 17, 0, 0, 0)},
+		{"TZ=UTC 0 0 * * 1#1", utc(2024, time.June, 3, 0, 0, 0)},
+		// Both day fields restricted: the 1st or any Monday.
+		{"TZ=UTC 0 0 1 * MON", utc(2024, time.May, 20, 0, 0, 0)},
+		{"TZ=UTC 0 0 29 2 *", utc(2028, time.February, 29, 0, 0, 0)},
+		{"TZ=UTC 0 0 30 2 *", time.Time{}},
+		{"@every 90s", from.Add(90 * time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron: %v", err)
+			}
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronNextTimeZone(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	s := MustParseCron("TZ=America/Los_Angeles 30 7 * * MON-FRI")
+	from := time.Date(2024, time.May, 17, 20, 0, 0, 0, time.UTC) // Friday 13:00 in LA
+	got := s.Next(from)
+	want := time.Date(2024, time.May, 20, 7, 30, 0, 0, la)
+	if !got.Equal(want) || got.Location().String() != la.String() {
+		t.Errorf("Next = %v, want %v", got, want)
+	}
+}
+
+func TestCronNextDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	at := func(m time.Month, d, h, mi int) time.Time {
+		return time.Date(2024, m, d, h, mi, 0, 0, ny)
+	}
+
+	// 02:30 does not exist on March 10th: the job fires when the clock
+	// jumps from 02:00 EST to 03:00 EDT, then normally the next day.
+	s := MustParseCron("TZ=America/New_York 30 2 * * *")
+	got := s.Next(at(time.March, 9, 12, 0))
+	if want := time.Date(2024, time.March, 10, 7, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("spring forward: Next = %v, want %v", got, want)
+	}
+	if got, want := s.Next(got), at(time.March, 11, 2, 30); !got.Equal(want) {
+		t.Errorf("after spring forward: Next = %v, want %v", got, want)
+	}
+
+	// 01:30 happens twice on November 3rd: the job fires on the first.
+	s = MustParseCron("TZ=America/New_York 30 1 * * *")
+	first := s.Next(at(time.November, 2, 12, 0))
+	if want := time.Date(2024, time.November, 3, 5, 30, 0, 0, time.UTC); !first.Equal(want) {
+		t.Errorf("fall back: Next = %v, want %v", first, want)
+	}
+	if got, want := s.Next(first), at(time.November, 4, 1, 30); !got.Equal(want) {
+		t.Errorf("after fall back: Next = %v, want %v", got, want)
+	}
+
+	// Hourly jobs keep firing every real hour through both transitions.
+	s = MustParseCron("TZ=America/New_York 0 * * * *")
+	for _, start := range []time.Time{at(time.March, 10, 0, 0), at(time.November, 3, 0, 0)} {
+		prev := s.Next(start)
+		for range 4 {
+			next := s.Next(prev)
+			if d := next.Sub(prev); d != time.Hour {
+				t.Errorf("hourly run at %v is %v after %v, want 1h", next, d, prev)
+			}
+			prev = next
+		}
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"", "* * * *", "* * * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *",
+		"* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "* * * * MON#6",
+		"TZ=Nowhere/Special * * * * *", "@every -1s", "@often",
+	} {
+		if _, err := ParseCron(expr); !errors.Is(err, ErrInvalidCron) {
+			t.Errorf("ParseCron(%q) err = %v, want ErrInvalidCron", expr, err)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package times
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// UnitName is how a locale names one unit.
+type UnitName struct {
+	One, Other, Short string
+}
+
+// Locale holds the words HumanizeWith and HumanizeBetween use. Units are
+// ordered years, months, weeks, days, hours, minutes, seconds. Future and
+// Past are format strings taking the humanized duration.
+type Locale struct {
+	Units        [7]UnitName
+	Separator    string
+	Future, Past string
+	Now          string
+}
+
+var (
+	// LocaleEnglish is the default locale.
+	LocaleEnglish = Locale{
+		Units: [7]UnitName{
+			{"year", "years", "y"},
+			{"month", "months", "mo"},
+			{"week", "weeks", "w"},
+			{"day", "days", "d"},
+			{"hour", "hours", "h"},
+			{"minute", "minutes", "m"},
+			{"second", "seconds", "s"},
+		},
+		Separator: " ",
+		Future:    "in %s",
+		Past:      "%s ago",
+		Now:       "just now",
+	}
+	// LocaleSpanish is Spanish.
+	LocaleSpanish = Locale{
+		Units: [7]UnitName{
+			{"año", "años", "a"},
+			{"mes", "meses", "me"},
+			{"semana", "semanas", "sem"},
+			{"día", "días", "d"},
+			{"hora", "horas", "h"},
+			{"minuto", "minutos", "min"},
+			{"segundo", "segundos", "s"},
+		},
+		Separator: " ",
+		Future:    "dentro de %s",
+		Past:      "hace %s",
+		Now:       "ahora mismo",
+	}
+)
+
+// unitSeconds is the nominal length of each unit, used to split durations
+// and to round.
+var unitSeconds = [7]int64{365 * 86400, 30 * 86400, 7 * 86400, 86400, 3600, 60, 1}
+
+// HumanizeWith describes d in words using years (365 days), months (30
+// days), weeks, days, hours, minutes and seconds. Unlike Humanize it
+// never returns an empty string: under a second it gives "0 seconds", or
+// the locale's "just now" with WithRelative, where a positive d is in the
+// future.
+func HumanizeWith(d time.Duration, opts ...HumanizeOption) string {
+	o := newHumanizeOptions(opts)
+	secs := int64(d / time.Second)
+	neg := secs < 0
+	if neg {
+		secs = -secs
+	}
+	var amounts [7]int64
+	for i, u := range unitSeconds {
+		amounts[i] = secs / u
+		secs %= u
+	}
+	return o.format(amounts, neg)
+}
+
+// HumanizeBetween describes the time from from to to, counting years,
+// months and days on the calendar: the 31st plus a month is the end of a
+// shorter month, and a day across a DST change is still a day. With
+// WithRelative a to before from is in the past.
+func HumanizeBetween(from, to time.Time, opts ...HumanizeOption) string {
+	return newHumanizeOptions(opts).between(from, to)
+}
+
+func (o humanizeOptions) between(from, to time.Time) string {
+	neg := to.Before(from)
+	if neg {
+		from, to = to, from
+	}
+	to = to.In(from.Location())
+	months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+	for months > 0 && addMonths(from, months).After(to) {
+		months--
+	}
+	cursor := addMonths(from, months)
+	days := int(to.Sub(cursor).Hours() / 24)
+	for days > 0 && cursor.AddDate(0, 0, days).After(to) {
+		days--
+	}
+	for !cursor.AddDate(0, 0, days+1).After(to) {
+		days++
+	}
+	rest := int64(to.Sub(cursor.AddDate(0, 0, days)) / time.Second)
+	amounts := [7]int64{
+		int64(months / 12), int64(months % 12), int64(days / 7), int64(days % 7),
+		rest / 3600, rest % 3600 / 60, rest % 60,
+	}
+	return o.format(amounts, neg)
+}
+
+// addMonths adds n months to t, clamping the day to the end of the target
+// month: January 31st plus one month is the end of February, not March.
+func addMonths(t time.Time, n int) time.Time {
+	y, m, d := t.Date()
+	last := time.Date(y, m+time.Month(n)+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	return time.Date(y, m+time.Month(n), min(d, last), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+// unitCarry is how many of a unit make one of the next larger unit when
+// rounding carries; weeks do not add up to months.
+var unitCarry = [7]int64{0, 12, 0, 7, 24, 60, 60}
+
+func (o humanizeOptions) format(amounts [7]int64, neg bool) string {
+	first := -1
+	for i, a := range amounts {
+		if a != 0 {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		if o.relative {
+			return o.locale.Now
+		}
+		return o.unit(0, len(amounts)-1)
+	}
+	last := len(amounts) - 1
+	if o.maxUnits > 0 {
+		last = min(last, first+o.maxUnits-1)
+	}
+	if o.round && last < len(amounts)-1 {
+		var below int64
+		for j := last + 1; j < len(amounts); j++ {
+			below += amounts[j] * unitSeconds[j]
+		}
+		if 2*below >= unitSeconds[last] {
+			amounts[last]++
+			for i := last; i > 0 && unitCarry[i] > 0 && amounts[i] >= unitCarry[i]; i-- {
+				amounts[i] -= unitCarry[i]
+				amounts[i-1]++
+				first = min(first, i-1)
+			}
+		}
+	}
+	var parts []string
+	for i := first; i <= last; i++ {
+		if amounts[i] != 0 {
+			parts = append(parts, o.unit(amounts[i], i))
+		}
+	}
+	s := strings.Join(parts, o.locale.Separator)
+	switch {
+	case !o.relative:
+		return s
+	case neg:
+		return fmt.Sprintf(o.locale.Past, s)
+	default:
+		return fmt.Sprintf(o.locale.Future, s)
+	}
+}
+
+func (o humanizeOptions) unit(n int64, i int) string {
+	name := o.locale.Units[i]
+	if o.abbrev {
+		return fmt.Sprintf("%d%s", n, name.Short)
+	}
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, name.One)
+	}
+	return fmt.Sprintf("%d %s", n, name.Other)
+}
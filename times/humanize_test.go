@@ -0,0 +1,66 @@
+package times
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHumanizeWith(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		d    time.Duration
+		opts []HumanizeOption
+		want string
+	}{
+		{0, nil, "0 seconds"},
+		{500 * time.Millisecond, []HumanizeOption{WithRelative()}, "just now"},
+		{3*day + 4*time.Hour + 2*time.Minute + 9*time.Second, nil, "3 days 4 hours 2 minutes 9 seconds"},
+		{3*day + 4*time.Hour + 2*time.Minute, []HumanizeOption{WithMaxUnits(2), WithAbbreviations()}, "3d 4h"},
+		{10 * day, nil, "1 week 3 days"},
+		{400 * day, []HumanizeOption{WithMaxUnits(2)}, "1 year 1 month"},
+		{time.Hour + 40*time.Minute, []HumanizeOption{WithMaxUnits(1), WithRounding()}, "2 hours"},
+		{time.Hour + 20*time.Minute, []HumanizeOption{WithMaxUnits(1), WithRounding()}, "1 hour"},
+		{23*time.Hour + 50*time.Minute, []HumanizeOption{WithMaxUnits(1), WithRounding()}, "1 day"},
+		{5 * time.Minute, []HumanizeOption{WithRelative()}, "in 5 minutes"},
+		{-5 * time.Minute, []HumanizeOption{WithRelative()}, "5 minutes ago"},
+		{-2 * time.Hour, []HumanizeOption{WithRelative(), WithLocale(LocaleSpanish)}, "hace 2 horas"},
+		{day + time.Minute, []HumanizeOption{WithRelative(), WithLocale(LocaleSpanish)}, "dentro de 1 día 1 minuto"},
+	}
+	for _, tt := range tests {
+		if got := HumanizeWith(tt.d, tt.opts...); got != tt.want {
+			t.Errorf("HumanizeWith(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestHumanizeBetween(t *testing.T) {
+	from := time.Date(2024, time.January, 31, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		to   time.Time
+		opts []HumanizeOption
+		want string
+	}{
+		{time.Date(2024, time.February, 29, 10, 0, 0, 0, time.UTC), nil, "1 month"},
+		{time.Date(2024, time.March, 31, 10, 0, 0, 0, time.UTC), nil, "2 months"},
+		{time.Date(2025, time.March, 1, 12, 30, 0, 0, time.UTC), nil, "1 year 1 month 1 day 2 hours 30 minutes"},
+		{time.Date(2023, time.December, 31, 10, 0, 0, 0, time.UTC), []HumanizeOption{WithRelative()}, "1 month ago"},
+	}
+	for _, tt := range tests {
+		if got := HumanizeBetween(from, tt.to, tt.opts...); got != tt.want {
+			t.Errorf("HumanizeBetween(%v) = %q, want %q", tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestHumanizeBetweenDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	// The spring-forward day is 23 hours long but still one day.
+	from := time.Date(2024, time.March, 9, 12, 0, 0, 0, ny)
+	to := time.Date(2024, time.March, 11, 12, 0, 0, 0, ny)
+	if got := HumanizeBetween(from, to); got != "2 days" {
+		t.Errorf("got %q, want %q", got, "2 days")
+	}
+}
@@ -0,0 +1,852 @@
+package times
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrInvalidICal is returned for malformed iCalendar data.
+var ErrInvalidICal = errors.New("times: invalid iCalendar data")
+
+const (
+	icalDate        = "20060102"
+	icalDateTime    = "20060102T150405"
+	icalDateTimeUTC = "20060102T150405Z"
+	icalProdID      = "-//heatxsink//x times//EN"
+)
+
+// Event is an iCalendar VEVENT.
+type Event struct {
+	UID         string
+	Stamp       time.Time // DTSTAMP; WriteEvents uses the current time if zero
+	Summary     string
+	Description string
+	Location    string
+
+	// Start and End are the first occurrence. End is exclusive and zero
+	// if the event has neither DTEND nor DURATION.
+	Start  time.Time
+	End    time.Time
+	AllDay bool
+
+	Recurrence
+
+	// zone is set when the event's TZID is defined only by a VTIMEZONE
+	// in the feed rather than by an IANA name.
+	zone *vtimezone
+}
+
+// Occurrence is one instance of a recurring event.
+type Occurrence struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns how long each occurrence lasts. An all-day event
+// without an end lasts one day.
+func (e *Event) Duration() time.Duration {
+	switch {
+	case !e.End.IsZero():
+		return e.End.Sub(e.Start)
+	case e.AllDay:
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+// Occurrences returns the occurrences of e that overlap [from, to), in
+// order. An occurrence without duration overlaps if it starts in the
+// window. Times follow the event's time zone, so a weekly 09:00 meeting
+// stays at 09:00 across daylight saving changes.
+func (e *Event) Occurrences(from, to time.Time) []Occurrence {
+	d := e.Duration()
+	days := int((d + 12*time.Hour) / (24 * time.Hour))
+	var out []Occurrence
+	for _, start := range e.starts(from.Add(-d), to) {
+		o := Occurrence{Start: start, End: start.Add(d)}
+		if e.AllDay {
+			o.End = start.AddDate(0, 0, days)
+		}
+		if o.End.After(from) || (d == 0 && !start.Before(from)) {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+func (e *Event) starts(from, to time.Time) []time.Time {
+	if e.zone == nil {
+		return e.Recurrence.Between(e.Start, from, to)
+	}
+	// Expand on the zone's wall clock, then find the instant of each
+	// occurrence with the offsets the VTIMEZONE gives.
+	const slack = 26 * time.Hour
+	rs := e.Recurrence
+	rs.RDates = walls(rs.RDates)
+	rs.ExDates = walls(rs.ExDates)
+	var out []time.Time
+	for _, w := range rs.Between(wall(e.Start), from.UTC().Add(-slack), to.UTC().Add(slack)) {
+		if t := e.zone.resolve(w); !t.Before(from) && t.Before(to) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func walls(ts []time.Time) []time.Time {
+	out := make([]time.Time, len(ts))
+	for i, t := range ts {
+		out[i] = wall(t)
+	}
+	return out
+}
+
+// icalProp is a content line: NAME;PARAM=value:VALUE.
+type icalProp struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// icalComponent is a BEGIN/END block.
+type icalComponent struct {
+	name     string
+	props    []icalProp
+	lines    []string // unfolded source lines, kept to copy VTIMEZONEs
+	children []*icalComponent
+}
+
+func (c *icalComponent) prop(name string) (icalProp, bool) {
+	for _, p := range c.props {
+		if p.name == name {
+			return p, true
+		}
+	}
+	return icalProp{}, false
+}
+
+// readICal parses an iCalendar stream into its top-level components.
+func readICal(r io.Reader) ([]*icalComponent, error) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	var top []*icalComponent
+	var stack []*icalComponent
+	for _, line := range lines {
+		p, err := parseICalLine(line)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range stack {
+			c.lines = append(c.lines, line)
+		}
+		switch p.name {
+		case "BEGIN":
+			c := &icalComponent{name: strings.ToUpper(p.value), lines: []string{line}}
+			if len(stack) == 0 {
+				top = append(top, c)
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, c)
+			}
+			stack = append(stack, c)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].name != strings.ToUpper(p.value) {
+				return nil, fmt.Errorf("%w: unexpected END:%s", ErrInvalidICal, p.value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) > 0 {
+				c := stack[len(stack)-1]
+				c.props = append(c.props, p)
+			}
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("%w: missing END:%s", ErrInvalidICal, stack[len(stack)-1].name)
+	}
+	return top, nil
+}
+
+// parseICalLine splits a content line into its name, parameters and
+// value. Parameter values may be quoted.
+func parseICalLine(line string) (icalProp, error) {
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return icalProp{}, fmt.Errorf("%w: line %q", ErrInvalidICal, line)
+	}
+	p := icalProp{name: strings.ToUpper(line[:i])}
+	rest := line[i:]
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return icalProp{}, fmt.Errorf("%w: line %q", ErrInvalidICal, line)
+		}
+		key := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+		var val string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return icalProp{}, fmt.Errorf("%w: line %q", ErrInvalidICal, line)
+			}
+			val, rest = rest[1:end+1], rest[end+2:]
+		} else {
+			end := strings.IndexAny(rest, ";:")
+			if end < 0 {
+				return icalProp{}, fmt.Errorf("%w: line %q", ErrInvalidICal, line)
+			}
+			val, rest = rest[:end], rest[end:]
+		}
+		if p.params == nil {
+			p.params = map[string]string{}
+		}
+		p.params[key] = val
+	}
+	if !strings.HasPrefix(rest, ":") {
+		return icalProp{}, fmt.Errorf("%w: line %q", ErrInvalidICal, line)
+	}
+	p.value = rest[1:]
+	return p, nil
+}
+
+// icalTime parses a DATE or DATE-TIME value. A trailing Z means UTC;
+// other date-times and dates are read in loc.
+func icalTime(value string, params map[string]string, loc *time.Location) (t time.Time, allDay bool, err error) {
+	switch {
+	case params["VALUE"] == "DATE" || len(value) == len(icalDate):
+		t, err = time.ParseInLocation(icalDate, value, loc)
+		allDay = true
+	case strings.HasSuffix(value, "Z"):
+		t, err = time.Parse(icalDateTimeUTC, value)
+	default:
+		t, err = time.ParseInLocation(icalDateTime, value, loc)
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%w: time %q", ErrInvalidICal, value)
+	}
+	return t, allDay, nil
+}
+
+var (
+	icalUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, `;`, `\,`, `,`, `\n`, "\n", `\N`, "\n")
+	icalEscaper   = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, "\r\n", `\n`, "\n", `\n`)
+)
+
+func icalUnescape(s string) string {
+	return icalUnescaper.Replace(s)
+}
+
+// parseICalDuration parses an RFC 5545 duration such as "PT1H30M",
+// "P1D" or "-P2W".
+func parseICalDuration(s string) (time.Duration, error) {
+	invalid := fmt.Errorf("%w: duration %q", ErrInvalidICal, s)
+	sign := time.Duration(1)
+	rest := s
+	switch {
+	case strings.HasPrefix(rest, "-"):
+		sign, rest = -1, rest[1:]
+	case strings.HasPrefix(rest, "+"):
+		rest = rest[1:]
+	}
+	if !strings.HasPrefix(rest, "P") || len(rest) < 3 {
+		return 0, invalid
+	}
+	rest = rest[1:]
+	var d time.Duration
+	inTime := false
+	for rest != "" {
+		if rest[0] == 'T' {
+			inTime, rest = true, rest[1:]
+			continue
+		}
+		i := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+		if i <= 0 {
+			return 0, invalid
+		}
+		n, _ := strconv.Atoi(rest[:i])
+		var u time.Duration
+		switch unit := rest[i]; {
+		case unit == 'W' && !inTime:
+			u = 7 * 24 * time.Hour
+		case unit == 'D' && !inTime:
+			u = 24 * time.Hour
+		case unit == 'H' && inTime:
+			u = time.Hour
+		case unit == 'M' && inTime:
+			u = time.Minute
+		case unit == 'S' && inTime:
+			u = time.Second
+		default:
+			return 0, invalid
+		}
+		d += time.Duration(n) * u
+		rest = rest[i+1:]
+	}
+	return sign * d, nil
+}
+
+// ReadEvents reads the VEVENTs of an iCalendar (RFC 5545) stream. TZIDs
+// are looked up as IANA names first, then in the stream's VTIMEZONEs.
+// Floating times, all-day dates and unknown TZIDs are read in loc.
+// RECURRENCE-ID overrides and other components are ignored.
+func ReadEvents(r io.Reader, loc *time.Location) ([]*Event, error) {
+	top, err := readICal(r)
+	if err != nil {
+		return nil, err
+	}
+	rd := eventReader{loc: loc, zones: map[string]*vtimezone{}}
+	var components []*icalComponent
+	for _, c := range top {
+		if c.name == "VCALENDAR" {
+			components = append(components, c.children...)
+		} else {
+			components = append(components, c)
+		}
+	}
+	for _, c := range components {
+		if c.name == "VTIMEZONE" {
+			z, err := parseVTimezone(c)
+			if err != nil {
+				return nil, err
+			}
+			rd.zones[z.id] = z
+		}
+	}
+	var events []*Event
+	for _, c := range components {
+		if c.name != "VEVENT" {
+			continue
+		}
+		if _, ok := c.prop("RECURRENCE-ID"); ok {
+			continue
+		}
+		e, err := rd.event(c)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+type eventReader struct {
+	loc   *time.Location
+	zones map[string]*vtimezone
+}
+
+// time parses a DATE or DATE-TIME property value, resolving its TZID.
+// It returns the VTIMEZONE used, if any.
+func (rd eventReader) time(value string, params map[string]string) (time.Time, bool, *vtimezone, error) {
+	tzid := params["TZID"]
+	if tzid == "" {
+		t, allDay, err := icalTime(value, params, rd.loc)
+		return t, allDay, nil, err
+	}
+	if loc, err := time.LoadLocation(tzid); err == nil {
+		t, allDay, err := icalTime(value, params, loc)
+		return t, allDay, nil, err
+	}
+	z, ok := rd.zones[tzid]
+	if !ok {
+		t, allDay, err := icalTime(value, params, rd.loc)
+		return t, allDay, nil, err
+	}
+	w, allDay, err := icalTime(value, params, time.UTC)
+	if err != nil {
+		return time.Time{}, false, nil, err
+	}
+	return z.resolve(w), allDay, z, nil
+}
+
+func (rd eventReader) times(p icalProp) ([]time.Time, error) {
+	var out []time.Time
+	for _, v := range strings.Split(p.value, ",") {
+		if p.params["VALUE"] == "PERIOD" {
+			v, _, _ = strings.Cut(v, "/")
+		}
+		t, _, _, err := rd.time(v, p.params)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func (rd eventReader) event(c *icalComponent) (*Event, error) {
+	e := &Event{}
+	var duration string
+	for _, p := range c.props {
+		var err error
+		switch p.name {
+		case "UID":
+			e.UID = p.value
+		case "DTSTAMP":
+			e.Stamp, _, _, err = rd.time(p.value, p.params)
+		case "SUMMARY":
+			e.Summary = icalUnescape(p.value)
+		case "DESCRIPTION":
+			e.Description = icalUnescape(p.value)
+		case "LOCATION":
+			e.Location = icalUnescape(p.value)
+		case "DTSTART":
+			e.Start, e.AllDay, e.zone, err = rd.time(p.value, p.params)
+		case "DTEND":
+			e.End, _, _, err = rd.time(p.value, p.params)
+		case "DURATION":
+			duration = p.value
+		case "RRULE", "EXRULE":
+			var rule RRule
+			if rule, err = ParseRRule(p.value, rd.loc); err == nil {
+				if p.name == "RRULE" {
+					e.RRules = append(e.RRules, rule)
+				} else {
+					e.ExRules = append(e.ExRules, rule)
+				}
+			}
+		case "RDATE", "EXDATE":
+			var ts []time.Time
+			if ts, err = rd.times(p); err == nil {
+				if p.name == "RDATE" {
+					e.RDates = append(e.RDates, ts...)
+				} else {
+					e.ExDates = append(e.ExDates, ts...)
+				}
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("times: event %q: %s: %w", e.UID, p.name, err)
+		}
+	}
+	if e.Start.IsZero() {
+		return nil, fmt.Errorf("%w: event %q has no DTSTART", ErrInvalidICal, e.UID)
+	}
+	if duration != "" && e.End.IsZero() {
+		d, err := parseICalDuration(duration)
+		if err != nil {
+			return nil, err
+		}
+		e.End = e.Start.Add(d)
+		if e.AllDay {
+			e.End = e.Start.AddDate(0, 0, int(d/(24*time.Hour)))
+		}
+	}
+	return e, nil
+}
+
+// vtimezone is a time zone defined by a VTIMEZONE component, used when
+// its TZID is not an IANA name.
+type vtimezone struct {
+	id          string
+	observances []tzObservance
+	lines       []string
+}
+
+// tzObservance is a STANDARD or DAYLIGHT block: from its onsets the
+// offset changes from `from` to `to` seconds east of UTC.
+type tzObservance struct {
+	name     string
+	start    time.Time // wall clock, as UTC
+	from, to int
+	rules    []RRule
+	rdates   []time.Time
+}
+
+func parseVTimezone(c *icalComponent) (*vtimezone, error) {
+	id, ok := c.prop("TZID")
+	if !ok {
+		return nil, fmt.Errorf("%w: VTIMEZONE without TZID", ErrInvalidICal)
+	}
+	z := &vtimezone{id: id.value, lines: c.lines}
+	for _, sub := range c.children {
+		if sub.name != "STANDARD" && sub.name != "DAYLIGHT" {
+			continue
+		}
+		o := tzObservance{name: sub.name}
+		for _, p := range sub.props {
+			var err error
+			switch p.name {
+			case "TZNAME":
+				o.name = p.value
+			case "DTSTART":
+				o.start, _, err = icalTime(p.value, nil, time.UTC)
+			case "TZOFFSETFROM":
+				o.from, err = parseUTCOffset(p.value)
+			case "TZOFFSETTO":
+				o.to, err = parseUTCOffset(p.value)
+			case "RRULE":
+				var rule RRule
+				if rule, err = ParseRRule(p.value, time.UTC); err == nil {
+					o.rules = append(o.rules, rule)
+				}
+			case "RDATE":
+				for _, v := range strings.Split(p.value, ",") {
+					var t time.Time
+					if t, _, err = icalTime(v, nil, time.UTC); err != nil {
+						break
+					}
+					o.rdates = append(o.rdates, t)
+				}
+			}
+			if err != nil {
+				return nil, fmt.Errorf("times: VTIMEZONE %q: %w", z.id, err)
+			}
+		}
+		if o.start.IsZero() {
+			return nil, fmt.Errorf("%w: VTIMEZONE %q observance without DTSTART", ErrInvalidICal, z.id)
+		}
+		z.observances = append(z.observances, o)
+	}
+	if len(z.observances) == 0 {
+		return nil, fmt.Errorf("%w: VTIMEZONE %q has no observances", ErrInvalidICal, z.id)
+	}
+	return z, nil
+}
+
+// parseUTCOffset parses "+0100" or "-053000" into seconds.
+func parseUTCOffset(s string) (int, error) {
+	if len(s) != 5 && len(s) != 7 || (s[0] != '+' && s[0] != '-') {
+		return 0, fmt.Errorf("%w: offset %q", ErrInvalidICal, s)
+	}
+	h, err1 := strconv.Atoi(s[1:3])
+	m, err2 := strconv.Atoi(s[3:5])
+	sec := 0
+	var err3 error
+	if len(s) == 7 {
+		sec, err3 = strconv.Atoi(s[5:7])
+	}
+	if err := errors.Join(err1, err2, err3); err != nil {
+		return 0, fmt.Errorf("%w: offset %q", ErrInvalidICal, s)
+	}
+	off := h*3600 + m*60 + sec
+	if s[0] == '-' {
+		off = -off
+	}
+	return off, nil
+}
+
+// offsetAt returns the offset and name in effect at instant t.
+func (z *vtimezone) offsetAt(t time.Time) (int, string) {
+	var best time.Time
+	offset, name := z.observances[0].from, z.observances[0].name
+	for _, o := range z.observances {
+		// Onsets are on the wall clock before the change.
+		w := t.UTC().Add(time.Duration(o.from) * time.Second)
+		onsets := append([]time.Time{o.start}, o.rdates...)
+		for _, r := range o.rules {
+			onsets = append(onsets, r.Between(o.start, w.AddDate(-1, 0, -1), w.Add(time.Second))...)
+		}
+		for _, on := range onsets {
+			at := on.Add(-time.Duration(o.from) * time.Second)
+			if !at.After(t) && at.After(best) {
+				best, offset, name = at, o.to, o.name
+			}
+		}
+	}
+	return offset, name
+}
+
+// resolve returns the instant the zone's wall clock first reads w, given
+// as UTC, or for a time skipped by a change, w read with the offset before
+// the change.
+func (z *vtimezone) resolve(w time.Time) time.Time {
+	var offsets []int
+	for _, o := range z.observances {
+		offsets = append(offsets, o.from, o.to)
+	}
+	// Larger offsets give earlier instants.
+	slices.Sort(offsets)
+	slices.Reverse(offsets)
+	for _, off := range slices.Compact(offsets) {
+		t := w.Add(-time.Duration(off) * time.Second)
+		if got, name := z.offsetAt(t); got == off {
+			return t.In(time.FixedZone(name, off))
+		}
+	}
+	off, _ := z.offsetAt(w.Add(-14 * time.Hour))
+	t := w.Add(-time.Duration(off) * time.Second)
+	now, name := z.offsetAt(t)
+	return t.In(time.FixedZone(name, now))
+}
+
+// WriteEvents writes events as an iCalendar stream with CRLF line endings
+// and lines folded at 75 octets. Times in UTC, time.Local and fixed zones
+// are written in UTC, others with their IANA name as TZID. Every TZID gets
+// a VTIMEZONE: zones read from one are written back as they were, and
+// IANA zones are generated from their transitions between the year before
+// the earliest time written and ten years past the later of the latest
+// time and now, so open-ended rules keep their offsets. DTSTAMP, when an
+// event has no Stamp, and now are read from WithClock if given.
+func WriteEvents(w io.Writer, events []*Event, opts ...ClockOption) error {
+	clock := clockOf(opts)
+	var b strings.Builder
+	line := func(s string) {
+		// Continuation lines start with a space, which counts.
+		for limit := 75; len(s) > limit; limit = 74 {
+			i := limit
+			for i > 0 && !utf8.RuneStart(s[i]) {
+				i--
+			}
+			b.WriteString(s[:i])
+			b.WriteString("\r\n ")
+			s = s[i:]
+		}
+		b.WriteString(s)
+		b.WriteString("\r\n")
+	}
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:" + icalProdID)
+	var zones []*vtimezone
+	for _, e := range events {
+		if e.zone != nil && !slices.Contains(zones, e.zone) {
+			zones = append(zones, e.zone)
+			for _, l := range e.zone.lines {
+				line(l)
+			}
+		}
+	}
+	for _, z := range ianaZones(events, clock.Now()) {
+		for _, l := range z.lines() {
+			line(l)
+		}
+	}
+	for _, e := range events {
+		stamp := e.Stamp
+		if stamp.IsZero() {
+			stamp = clock.Now()
+		}
+		line("BEGIN:VEVENT")
+		line("UID:" + e.uid())
+		line("DTSTAMP:" + stamp.UTC().Format(icalDateTimeUTC))
+		line(e.timeProp("DTSTART", e.Start))
+		if !e.End.IsZero() {
+			line(e.timeProp("DTEND", e.End))
+		}
+		for _, p := range []struct{ name, value string }{
+			{"SUMMARY", e.Summary}, {"DESCRIPTION", e.Description}, {"LOCATION", e.Location},
+		} {
+			if p.value != "" {
+				line(p.name + ":" + icalEscaper.Replace(p.value))
+			}
+		}
+		for _, r := range e.RRules {
+			line("RRULE:" + r.format(e.AllDay))
+		}
+		for _, r := range e.ExRules {
+			line("EXRULE:" + r.format(e.AllDay))
+		}
+		for _, t := range e.RDates {
+			line(e.timeProp("RDATE", t))
+		}
+		for _, t := range e.ExDates {
+			line(e.timeProp("EXDATE", t))
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// uid returns e.UID, or one derived from the event for events without.
+func (e *Event) uid() string {
+	if e.UID != "" {
+		return e.UID
+	}
+	sum := sha256.Sum256([]byte(e.Start.UTC().Format(icalDateTimeUTC) + "\x00" + e.Summary))
+	return hex.EncodeToString(sum[:16]) + "@x.heatxsink"
+}
+
+// timeProp formats a DATE or DATE-TIME property in the event's zone.
+func (e *Event) timeProp(name string, t time.Time) string {
+	switch {
+	case e.AllDay:
+		return name + ";VALUE=DATE:" + t.Format(icalDate)
+	case e.zone != nil:
+		return name + ";TZID=" + e.zone.id + ":" + t.Format(icalDateTime)
+	}
+	if isIANA(t.Location()) {
+		return name + ";TZID=" + t.Location().String() + ":" + t.Format(icalDateTime)
+	}
+	return name + ":" + t.UTC().Format(icalDateTimeUTC)
+}
+
+// isIANA reports whether times in loc are written with its name as TZID.
+func isIANA(loc *time.Location) bool {
+	if loc == time.UTC || loc == time.Local {
+		return false
+	}
+	_, err := time.LoadLocation(loc.String())
+	return err == nil
+}
+
+// ianaZone is an IANA time zone WriteEvents writes a VTIMEZONE for,
+// covering from to to.
+type ianaZone struct {
+	loc      *time.Location
+	from, to time.Time
+}
+
+// ianaZones returns the IANA zones the DATE-TIME properties of events
+// use, in order of first use.
+func ianaZones(events []*Event, now time.Time) []*ianaZone {
+	var zones []*ianaZone
+	byName := map[string]*ianaZone{}
+	add := func(t time.Time) {
+		if t.IsZero() || !isIANA(t.Location()) {
+			return
+		}
+		z, ok := byName[t.Location().String()]
+		if !ok {
+			z = &ianaZone{loc: t.Location(), from: t, to: t}
+			byName[t.Location().String()] = z
+			zones = append(zones, z)
+		}
+		if t.Before(z.from) {
+			z.from = t
+		}
+		if t.After(z.to) {
+			z.to = t
+		}
+	}
+	for _, e := range events {
+		if e.AllDay || e.zone != nil {
+			continue
+		}
+		add(e.Start)
+		add(e.End)
+		if z := byName[e.Start.Location().String()]; z != nil {
+			for _, r := range e.RRules {
+				if r.Until.After(z.to) {
+					z.to = r.Until
+				}
+			}
+		}
+		for _, t := range slices.Concat(e.RDates, e.ExDates) {
+			add(t)
+		}
+	}
+	for _, z := range zones {
+		z.from = time.Date(z.from.In(z.loc).Year()-1, time.January, 1, 0, 0, 0, 0, z.loc)
+		last := z.to
+		if now.After(last) {
+			last = now
+		}
+		z.to = time.Date(last.In(z.loc).Year()+10, time.January, 1, 0, 0, 0, 0, z.loc)
+	}
+	return zones
+}
+
+// lines renders z as a VTIMEZONE. The first observance gives the offset
+// in effect at z.from; each later one starts at a transition, with the
+// further transitions of the same kind as RDATEs.
+func (z *ianaZone) lines() []string {
+	type observance struct {
+		dst      bool
+		name     string
+		from, to int
+	}
+	var order []observance
+	onsets := map[observance][]string{}
+	onset := func(o observance, at time.Time) {
+		if _, ok := onsets[o]; !ok {
+			order = append(order, o)
+		}
+		// DTSTART and RDATE are on the wall clock before the change.
+		onsets[o] = append(onsets[o], at.Add(time.Duration(o.from)*time.Second).UTC().Format(icalDateTime))
+	}
+	at := z.from.In(z.loc)
+	name, off := at.Zone()
+	onset(observance{at.IsDST(), name, off, off}, at)
+	for _, t := range zoneTransitions(z.loc, z.from, z.to) {
+		t = t.In(z.loc)
+		next, to := t.Zone()
+		onset(observance{t.IsDST(), next, off, to}, t)
+		off = to
+	}
+	out := []string{"BEGIN:VTIMEZONE", "TZID:" + z.loc.String()}
+	for _, o := range order {
+		kind := "STANDARD"
+		if o.dst {
+			kind = "DAYLIGHT"
+		}
+		out = append(out, "BEGIN:"+kind, "DTSTART:"+onsets[o][0])
+		for _, r := range onsets[o][1:] {
+			out = append(out, "RDATE:"+r)
+		}
+		out = append(out,
+			"TZOFFSETFROM:"+formatUTCOffset(o.from),
+			"TZOFFSETTO:"+formatUTCOffset(o.to),
+			"TZNAME:"+o.name,
+			"END:"+kind)
+	}
+	return append(out, "END:VTIMEZONE")
+}
+
+// zoneTransitions returns the instants in [from, to) at which the offset
+// or abbreviation of loc changes. It samples daily and bisects to the
+// second, as no zone changes twice in a day.
+func zoneTransitions(loc *time.Location, from, to time.Time) []time.Time {
+	zone := func(unix int64) string {
+		name, off := time.Unix(unix, 0).In(loc).Zone()
+		return name + strconv.Itoa(off)
+	}
+	const day = 24 * 60 * 60
+	var out []time.Time
+	for lo := from.Unix(); lo < to.Unix(); lo += day {
+		hi := lo + day
+		if zone(lo) == zone(hi) {
+			continue
+		}
+		before := zone(lo)
+		a, b := lo, hi
+		for b-a > 1 {
+			mid := a + (b-a)/2
+			if zone(mid) == before {
+				a = mid
+			} else {
+				b = mid
+			}
+		}
+		out = append(out, time.Unix(b, 0))
+	}
+	return out
+}
+
+// formatUTCOffset formats seconds east of UTC as "+0100" or "-053000".
+func formatUTCOffset(off int) string {
+	sign := '+'
+	if off < 0 {
+		sign, off = '-', -off
+	}
+	s := fmt.Sprintf("%c%02d%02d", sign, off/3600, off/60%60)
+	if off%60 != 0 {
+		s += fmt.Sprintf("%02d", off%60)
+	}
+	return s
+}
@@ -0,0 +1,252 @@
+package times
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ics joins lines with CRLF.
+func ics(lines ...string) string {
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+// A VTIMEZONE in the style Outlook exports, with a non-IANA TZID.
+var easternVTimezone = []string{
+	"BEGIN:VTIMEZONE",
+	"TZID:Eastern Standard Time",
+	"BEGIN:STANDARD",
+	"DTSTART:16010101T020000",
+	"TZOFFSETFROM:-0400",
+	"TZOFFSETTO:-0500",
+	"RRULE:FREQ=YEARLY;BYDAY=1SU;BYMONTH=11",
+	"END:STANDARD",
+	"BEGIN:DAYLIGHT",
+	"DTSTART:16010101T020000",
+	"TZOFFSETFROM:-0500",
+	"TZOFFSETTO:-0400",
+	"RRULE:FREQ=YEARLY;BYDAY=2SU;BYMONTH=3",
+	"END:DAYLIGHT",
+	"END:VTIMEZONE",
+}
+
+func utcTimes(occ []Occurrence) []string {
+	var out []string
+	for _, o := range occ {
+		out = append(out, o.Start.UTC().Format(icalDateTimeUTC)+"/"+o.End.UTC().Format(icalDateTimeUTC))
+	}
+	return out
+}
+
+func TestReadEventsVTimezone(t *testing.T) {
+	doc := ics(append(append([]string{"BEGIN:VCALENDAR", "VERSION:2.0"}, easternVTimezone...),
+		"BEGIN:VEVENT",
+		"UID:standup@example.com",
+		"DTSTART;TZID=Eastern Standard Time:20241028T090000",
+		"DURATION:PT30M",
+		"RRULE:FREQ=WEEKLY;COUNT=3",
+		"SUMMARY:Standup",
+		"END:VEVENT",
+		"END:VCALENDAR")...)
+	events, err := ReadEvents(strings.NewReader(doc), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Summary != "Standup" {
+		t.Fatalf("events = %+v", events)
+	}
+	from := time.Date(2024, time.October, 1, 0, 0, 0, 0, time.UTC)
+	got := utcTimes(events[0].Occurrences(from, from.AddDate(0, 2, 0)))
+	// 09:00 in New York: EDT before November 3rd, EST after.
+	want := []string{
+		"20241028T130000Z/20241028T133000Z",
+		"20241104T140000Z/20241104T143000Z",
+		"20241111T140000Z/20241111T143000Z",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Occurrences = %v, want %v", got, want)
+	}
+}
+
+func TestReadEventsIANA(t *testing.T) {
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	doc := ics(
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:review",
+		"DTSTART;TZID=America/New_York:20240304T090000",
+		"DTEND;TZID=America/New_York:20240304T100000",
+		"RRULE:FREQ=WEEKLY;COUNT=3",
+		"EXDATE;TZID=America/New_York:20240311T090000",
+		"RDATE:20240401T130000Z,20240402T130000Z",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:offsite",
+		"DTSTART;VALUE=DATE:20240101",
+		"DURATION:P2D",
+		"SUMMARY;LANGUAGE=en:Offsite\\; planning\\, day one",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"UID:ignored",
+		"END:VTODO",
+		"END:VCALENDAR")
+	events, err := ReadEvents(strings.NewReader(doc), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	got := utcTimes(events[0].Occurrences(from, from.AddDate(1, 0, 0)))
+	want := []string{
+		"20240304T140000Z/20240304T150000Z",
+		"20240318T130000Z/20240318T140000Z",
+		"20240401T130000Z/20240401T140000Z",
+		"20240402T130000Z/20240402T140000Z",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Occurrences = %v, want %v", got, want)
+	}
+
+	offsite := events[1]
+	if !offsite.AllDay || offsite.Summary != "Offsite; planning, day one" || offsite.Duration() != 48*time.Hour {
+		t.Errorf("offsite = %+v", offsite)
+	}
+	// An occurrence overlapping the start of the window is included.
+	if occ := offsite.Occurrences(from.Add(36*time.Hour), from.AddDate(0, 1, 0)); len(occ) != 1 {
+		t.Errorf("Occurrences = %v, want the one spanning the window start", occ)
+	}
+}
+
+func TestWriteEventsRoundTrip(t *testing.T) {
+	doc := ics(append(append([]string{"BEGIN:VCALENDAR", "VERSION:2.0"}, easternVTimezone...),
+		"BEGIN:VEVENT",
+		"UID:standup@example.com",
+		"DTSTART;TZID=Eastern Standard Time:20241028T090000",
+		"DTEND;TZID=Eastern Standard Time:20241028T093000",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,WE",
+		"EXDATE;TZID=Eastern Standard Time:20241030T090000",
+		"END:VEVENT",
+		"END:VCALENDAR")...)
+	events, err := ReadEvents(strings.NewReader(doc), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events = append(events, &Event{
+		Summary:     "Launch",
+		Description: strings.Repeat("A long description, with commas; semicolons and ünïcödé. ", 4) + "\nSecond line",
+		Start:       time.Date(2024, time.December, 2, 17, 0, 0, 0, time.UTC),
+		End:         time.Date(2024, time.December, 2, 18, 0, 0, 0, time.UTC),
+		Recurrence: Recurrence{
+			RRules: []RRule{{Freq: Monthly, Count: 2, WeekStart: time.Monday}},
+		},
+	}, &Event{
+		UID:    "holiday",
+		Start:  time.Date(2024, time.December, 25, 0, 0, 0, 0, time.UTC),
+		AllDay: true,
+		Recurrence: Recurrence{
+			RRules: []RRule{{Freq: Yearly, Until: time.Date(2030, time.December, 25, 0, 0, 0, 0, time.UTC), WeekStart: time.Monday}},
+		},
+	})
+
+	var buf bytes.Buffer
+	clock := NewFakeClock(time.Date(2024, time.October, 1, 12, 0, 0, 0, time.UTC))
+	if err := WriteEvents(&buf, events, WithClock(clock)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, l := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(l) > 75 {
+			t.Errorf("line longer than 75 octets: %q", l)
+		}
+	}
+	for _, s := range []string{"TZID:Eastern Standard Time", "DTSTAMP:20241001T120000Z", "RRULE:FREQ=YEARLY;UNTIL=20301225\r\n", "DTSTART;VALUE=DATE:20241225"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output lacks %q:\n%s", s, out)
+		}
+	}
+
+	back, err := ReadEvents(strings.NewReader(out), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(back) != len(events) {
+		t.Fatalf("read back %d events, want %d", len(back), len(events))
+	}
+	if back[1].Description != events[1].Description || back[1].UID == "" {
+		t.Errorf("Description = %q, UID = %q", back[1].Description, back[1].UID)
+	}
+	from := time.Date(2024, time.October, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 3, 0)
+	for i := range events {
+		if got, want := utcTimes(back[i].Occurrences(from, to)), utcTimes(events[i].Occurrences(from, to)); strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("event %d occurrences = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestWriteEventsIANAVTimezone(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	events := []*Event{{
+		UID:        "standup",
+		Start:      time.Date(2024, time.March, 4, 9, 0, 0, 0, ny),
+		End:        time.Date(2024, time.March, 4, 9, 30, 0, 0, ny),
+		Recurrence: Recurrence{RRules: []RRule{{Freq: Weekly, WeekStart: time.Monday}}},
+	}}
+	var buf bytes.Buffer
+	clock := NewFakeClock(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC))
+	if err := WriteEvents(&buf, events, WithClock(clock)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		"BEGIN:VTIMEZONE\r\nTZID:America/New_York\r\n",
+		"BEGIN:STANDARD\r\nDTSTART:20230101T000000\r\n",
+		"BEGIN:DAYLIGHT\r\nDTSTART:20230312T020000\r\nRDATE:20240310T020000\r\n",
+		"TZOFFSETFROM:-0500\r\nTZOFFSETTO:-0400\r\nTZNAME:EDT\r\n",
+		"DTSTART;TZID=America/New_York:20240304T090000",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output lacks %q:\n%s", s, out)
+		}
+	}
+	// Read under another name, the generated VTIMEZONE alone must give
+	// the same instants as the IANA zone, across both changes.
+	custom, err := ReadEvents(strings.NewReader(strings.ReplaceAll(out, "America/New_York", "Eastern")), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(1, 0, 0)
+	if got, want := utcTimes(custom[0].Occurrences(from, to)), utcTimes(events[0].Occurrences(from, to)); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("occurrences with the generated VTIMEZONE = %v, want %v", got, want)
+	}
+}
+
+func TestReadEventsErrors(t *testing.T) {
+	for _, doc := range []string{
+		ics("BEGIN:VCALENDAR", "BEGIN:VEVENT", "DTSTART:20240101T000000Z", "END:VCALENDAR"),
+		ics("BEGIN:VEVENT", "DTSTART:20240101T000000Z"),
+		ics("BEGIN:VEVENT", "no colon here", "END:VEVENT"),
+		ics("BEGIN:VEVENT", "SUMMARY:No start", "END:VEVENT"),
+		ics("BEGIN:VEVENT", "DTSTART:20240101T000000Z", "DURATION:1 hour", "END:VEVENT"),
+		ics("BEGIN:VEVENT", "DTSTART;TZID=\"unterminated:20240101T000000", "END:VEVENT"),
+		ics("BEGIN:VTIMEZONE", "TZID:Nowhere", "END:VTIMEZONE"),
+	} {
+		if _, err := ReadEvents(strings.NewReader(doc), time.UTC); !errors.Is(err, ErrInvalidICal) {
+			t.Errorf("ReadEvents(%q) err = %v, want ErrInvalidICal", doc, err)
+		}
+	}
+	doc := ics("BEGIN:VEVENT", "DTSTART:20240101T000000Z", "RRULE:FREQ=SOMETIMES", "END:VEVENT")
+	if _, err := ReadEvents(strings.NewReader(doc), time.UTC); !errors.Is(err, ErrInvalidRRule) {
+		t.Errorf("ReadEvents with a bad RRULE err = %v, want ErrInvalidRRule", err)
+	}
+}
@@ -0,0 +1,63 @@
+package times
+
+// HumanizeOption configures HumanizeWith, HumanizeBetween and DateSince.
+type HumanizeOption func(*humanizeOptions)
+
+type humanizeOptions struct {
+	maxUnits int
+	round    bool
+	abbrev   bool
+	relative bool
+	locale   Locale
+}
+
+func newHumanizeOptions(opts []HumanizeOption) humanizeOptions {
+	o := humanizeOptions{locale: LocaleEnglish}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// applyDateSince lets every HumanizeOption be passed to DateSince.
+func (f HumanizeOption) applyDateSince(o *dateSinceOptions) { f(&o.humanizeOptions) }
+
+// WithMaxUnits limits output to the n largest non-zero units, so
+// "3 days 4 hours 2 minutes" becomes "3 days 4 hours" with n = 2. The
+// default, 0, shows every unit.
+func WithMaxUnits(n int) HumanizeOption {
+	return func(o *humanizeOptions) {
+		o.maxUnits = n
+	}
+}
+
+// WithRounding rounds the last unit shown to the nearest value instead of
+// truncating it: "1 hour 40 minutes" with one unit is "2 hours".
+func WithRounding() HumanizeOption {
+	return func(o *humanizeOptions) {
+		o.round = true
+	}
+}
+
+// WithAbbreviations uses short unit names: "3d 4h".
+func WithAbbreviations() HumanizeOption {
+	return func(o *humanizeOptions) {
+		o.abbrev = true
+	}
+}
+
+// WithRelative phrases the result as relative to now: "in 5 minutes" for
+// the future, "5 minutes ago" for the past and "just now" under a second.
+func WithRelative() HumanizeOption {
+	return func(o *humanizeOptions) {
+		o.relative = true
+	}
+}
+
+// WithLocale selects the language of the output. The default is
+// LocaleEnglish.
+func WithLocale(l Locale) HumanizeOption {
+	return func(o *humanizeOptions) {
+		o.locale = l
+	}
+}
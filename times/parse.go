@@ -0,0 +1,347 @@
+package times
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Precision is the granularity of a parsed time: "tomorrow" names a whole
+// day, "in 2 hours" an hour, "2024-05-01T10:00:00" a second.
+type Precision int
+
+const (
+	PrecisionSecond Precision = iota
+	PrecisionMinute
+	PrecisionHour
+	PrecisionDay
+	PrecisionWeek
+	PrecisionMonth
+	PrecisionYear
+)
+
+func (p Precision) String() string {
+	switch p {
+	case PrecisionMinute:
+		return "minute"
+	case PrecisionHour:
+		return "hour"
+	case PrecisionDay:
+		return "day"
+	case PrecisionWeek:
+		return "week"
+	case PrecisionMonth:
+		return "month"
+	case PrecisionYear:
+		return "year"
+	default:
+		return "second"
+	}
+}
+
+// ErrUnrecognized is returned by ParseHuman for input it cannot read.
+var ErrUnrecognized = errors.New("times: unrecognized date")
+
+// humanLayouts are the absolute formats ParseHuman tries first.
+var humanLayouts = []struct {
+	layout    string
+	precision Precision
+}{
+	{time.RFC3339Nano, PrecisionSecond},
+	{AlmostRFC3339, PrecisionSecond},
+	{"2006-01-02 15:04:05", PrecisionSecond},
+	{"2006-01-02 15:04", PrecisionMinute},
+	{"2006-01-02", PrecisionDay},
+	{Friendly, PrecisionMinute},
+	{DateTimeDirectory, PrecisionSecond},
+	{DateDirectory, PrecisionDay},
+	{"2006-01", PrecisionMonth},
+}
+
+var (
+	isoWeekRe  = regexp.MustCompile(`^(\d{4})-?w(\d{2})(?:-?([1-7]))?$`)
+	clockRe    = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(?::(\d{2}))?(am|pm)?$`)
+	amountRe   = regexp.MustCompile(`^(?:\d+[a-z]+)+$`)
+	amountPart = regexp.MustCompile(`(\d+)([a-z]+)`)
+)
+
+// ParseHuman parses an absolute or relative date the way people write
+// them, relative to now, in loc (now's location when loc is nil):
+//
+//   - absolute layouts such as RFC 3339, AlmostRFC3339, "2006-01-02 15:04",
+//     Friendly and DateDirectory
+//   - ISO week dates: "2024-W05" (its Monday) and "2024-W05-3"
+//   - "now", "today", "tomorrow", "yesterday", optionally with a time of
+//     day: "tomorrow 9am", "today at 17:30", "noon", "midnight"
+//   - weekdays: "friday" (today or the next one), "next friday", "last
+//     friday 5pm"
+//   - offsets: "in 3 days", "2h ago", "an hour ago", "1 week 2 days ago",
+//     "in 1h30m"
+//   - periods: "this week", "next month", "last year", which resolve to
+//     the start of the period
+//
+// The returned Precision is the granularity the input implies.
+func ParseHuman(s string, now time.Time, loc *time.Location) (time.Time, Precision, error) {
+	if loc == nil {
+		loc = now.Location()
+	}
+	now = now.In(loc)
+	s = strings.TrimSpace(s)
+	for _, l := range humanLayouts {
+		if t, err := time.ParseInLocation(l.layout, s, loc); err == nil {
+			return t, l.precision, nil
+		}
+	}
+
+	fields := strings.Fields(strings.ToLower(strings.ReplaceAll(s, ",", " ")))
+	words := fields[:0]
+	for _, f := range fields {
+		if f != "at" && f != "and" {
+			words = append(words, f)
+		}
+	}
+	if len(words) == 0 {
+		return time.Time{}, 0, fmt.Errorf("%w: %q", ErrUnrecognized, s)
+	}
+	if m := isoWeekRe.FindStringSubmatch(words[0]); m != nil && len(words) == 1 {
+		return isoWeek(m, loc, s)
+	}
+
+	var t time.Time
+	var p Precision
+	var ok bool
+	switch {
+	case words[0] == "in" && len(words) > 1:
+		t, p, ok = offset(now, words[1:], 1)
+	case len(words) > 1 && words[len(words)-1] == "ago":
+		t, p, ok = offset(now, words[:len(words)-1], -1)
+	default:
+		t, p, ok = dayAndClock(now, words)
+	}
+	if !ok {
+		return time.Time{}, 0, fmt.Errorf("%w: %q", ErrUnrecognized, s)
+	}
+	return t, p, nil
+}
+
+func isoWeek(m []string, loc *time.Location, s string) (time.Time, Precision, error) {
+	year, _ := strconv.Atoi(m[1])
+	week, _ := strconv.Atoi(m[2])
+	if week < 1 || week > 53 {
+		return time.Time{}, 0, fmt.Errorf("%w: %q", ErrUnrecognized, s)
+	}
+	monday := ISOWeekStart(year, week, loc)
+	if _, w := monday.ISOWeek(); w != week {
+		return time.Time{}, 0, fmt.Errorf("%w: %q", ErrUnrecognized, s)
+	}
+	if m[3] == "" {
+		return monday, PrecisionWeek, nil
+	}
+	day, _ := strconv.Atoi(m[3])
+	return monday.AddDate(0, 0, day-1), PrecisionDay, nil
+}
+
+// unit is one offset unit: calendar parts are applied with AddDate, the
+// rest as a duration.
+type unit struct {
+	years, months, days int
+	d                   time.Duration
+	precision           Precision
+}
+
+var units = func() map[string]unit {
+	units := map[string]unit{}
+	add := func(u unit, names ...string) {
+		for _, n := range names {
+			units[n] = u
+		}
+	}
+	add(unit{d: time.Second, precision: PrecisionSecond}, "s", "sec", "secs", "second", "seconds")
+	add(unit{d: time.Minute, precision: PrecisionMinute}, "m", "min", "mins", "minute", "minutes")
+	add(unit{d: time.Hour, precision: PrecisionHour}, "h", "hr", "hrs", "hour", "hours")
+	add(unit{days: 1, precision: PrecisionDay}, "d", "day", "days")
+	add(unit{days: 7, precision: PrecisionWeek}, "w", "wk", "wks", "week", "weeks")
+	add(unit{months: 1, precision: PrecisionMonth}, "mo", "mon", "month", "months")
+	add(unit{years: 1, precision: PrecisionYear}, "y", "yr", "yrs", "year", "years")
+	return units
+}()
+
+// offset applies amounts such as "3 days", "an hour" or "1h30m" to now in
+// the direction of sign.
+func offset(now time.Time, words []string, sign int) (time.Time, Precision, bool) {
+	var years, months, days int
+	var d time.Duration
+	p := PrecisionYear
+	apply := func(n int, name string) bool {
+		u, ok := units[name]
+		if !ok {
+			return false
+		}
+		years += n * u.years
+		months += n * u.months
+		days += n * u.days
+		d += time.Duration(n) * u.d
+		p = min(p, u.precision)
+		return true
+	}
+	found := false
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		if amountRe.MatchString(w) {
+			for _, m := range amountPart.FindAllStringSubmatch(w, -1) {
+				n, err := strconv.Atoi(m[1])
+				if err != nil || !apply(n, m[2]) {
+					return time.Time{}, 0, false
+				}
+			}
+			found = true
+			continue
+		}
+		n, err := strconv.Atoi(w)
+		if w == "a" || w == "an" {
+			n, err = 1, nil
+		}
+		if err != nil || i+1 == len(words) || !apply(n, words[i+1]) {
+			return time.Time{}, 0, false
+		}
+		found = true
+		i++
+	}
+	if !found {
+		return time.Time{}, 0, false
+	}
+	return now.AddDate(sign*years, sign*months, sign*days).Add(time.Duration(sign) * d), p, true
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// dayAndClock reads an optional day ("tomorrow", "next friday") followed by
+// an optional time of day, or a whole period ("next month").
+func dayAndClock(now time.Time, words []string) (time.Time, Precision, bool) {
+	if len(words) == 1 && words[0] == "now" {
+		return now, PrecisionSecond, true
+	}
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	day, n := midnight, 0
+	switch {
+	case words[0] == "today" || words[0] == "tonight":
+		n = 1
+	case words[0] == "tomorrow":
+		day, n = midnight.AddDate(0, 0, 1), 1
+	case words[0] == "yesterday":
+		day, n = midnight.AddDate(0, 0, -1), 1
+	case len(words) >= 2 && (words[0] == "next" || words[0] == "last" || words[0] == "this"):
+		if t, p, ok := period(midnight, words[0], words[1]); ok {
+			return t, p, len(words) == 2
+		}
+		wd, ok := weekdays[words[1]]
+		if !ok {
+			return time.Time{}, 0, false
+		}
+		day, n = weekday(midnight, wd, words[0]), 2
+	default:
+		if wd, ok := weekdays[words[0]]; ok {
+			day, n = weekday(midnight, wd, "this"), 1
+		}
+	}
+	rest := words[n:]
+	if len(rest) == 0 {
+		if n == 0 {
+			return time.Time{}, 0, false
+		}
+		return day, PrecisionDay, true
+	}
+	h, m, sec, p, ok := clock(strings.Join(rest, ""))
+	if !ok {
+		return time.Time{}, 0, false
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), h, m, sec, 0, day.Location()), p, true
+}
+
+// weekday returns the next wd after today for "next", the previous one
+// before today for "last", and today or the next one otherwise.
+func weekday(today time.Time, wd time.Weekday, which string) time.Time {
+	diff := int(wd - today.Weekday())
+	switch which {
+	case "next":
+		if diff <= 0 {
+			diff += 7
+		}
+	case "last":
+		if diff >= 0 {
+			diff -= 7
+		}
+	default:
+		if diff < 0 {
+			diff += 7
+		}
+	}
+	return today.AddDate(0, 0, diff)
+}
+
+// period returns the start of this, next or last week (from Monday), month
+// or year.
+func period(today time.Time, which, name string) (time.Time, Precision, bool) {
+	step := map[string]int{"this": 0, "next": 1, "last": -1}[which]
+	loc := today.Location()
+	switch name {
+	case "week":
+		monday := today.AddDate(0, 0, -int((today.Weekday()+6)%7))
+		return monday.AddDate(0, 0, 7*step), PrecisionWeek, true
+	case "month":
+		return time.Date(today.Year(), today.Month()+time.Month(step), 1, 0, 0, 0, 0, loc), PrecisionMonth, true
+	case "year":
+		return time.Date(today.Year()+step, time.January, 1, 0, 0, 0, 0, loc), PrecisionYear, true
+	}
+	return time.Time{}, 0, false
+}
+
+// clock parses a time of day: "9am", "9:30pm", "17:30", "17:30:15",
+// "noon" or "midnight".
+func clock(s string) (hour, minute, second int, p Precision, ok bool) {
+	switch s {
+	case "noon", "midday":
+		return 12, 0, 0, PrecisionMinute, true
+	case "midnight":
+		return 0, 0, 0, PrecisionMinute, true
+	}
+	m := clockRe.FindStringSubmatch(s)
+	if m == nil || (m[2] == "" && m[4] == "") {
+		return 0, 0, 0, 0, false
+	}
+	hour, _ = strconv.Atoi(m[1])
+	minute, _ = strconv.Atoi(m[2])
+	second, _ = strconv.Atoi(m[3])
+	p = PrecisionHour
+	if m[2] != "" {
+		p = PrecisionMinute
+	}
+	if m[3] != "" {
+		p = PrecisionSecond
+	}
+	switch m[4] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, 0, 0, false
+		}
+		hour %= 12
+		if m[4] == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 || second > 59 {
+		return 0, 0, 0, 0, false
+	}
+	return hour, minute, second, p, true
+}
@@ -0,0 +1,83 @@
+package times
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseHuman(t *testing.T) {
+	// Wednesday.
+	now := time.Date(2024, time.May, 15, 14, 30, 0, 0, time.UTC)
+	day := func(m time.Month, d, h, mi int) time.Time {
+		return time.Date(2024, m, d, h, mi, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		in        string
+		want      time.Time
+		precision Precision
+	}{
+		{"now", now, PrecisionSecond},
+		{"today", day(time.May, 15, 0, 0), PrecisionDay},
+		{"tomorrow 9am", day(time.May, 16, 9, 0), PrecisionHour},
+		{"Tomorrow at 9:15pm", day(time.May, 16, 21, 15), PrecisionMinute},
+		{"yesterday noon", day(time.May, 14, 12, 0), PrecisionMinute},
+		{"17:30", day(time.May, 15, 17, 30), PrecisionMinute},
+		{"9 am", day(time.May, 15, 9, 0), PrecisionHour},
+		{"in 3 days", day(time.May, 18, 14, 30), PrecisionDay},
+		{"2h ago", day(time.May, 15, 12, 30), PrecisionHour},
+		{"an hour ago", day(time.May, 15, 13, 30), PrecisionHour},
+		{"in 1h30m", day(time.May, 15, 16, 0), PrecisionMinute},
+		{"1 week and 2 days ago", day(time.May, 6, 14, 30), PrecisionDay},
+		{"in 2 months", day(time.July, 15, 14, 30), PrecisionMonth},
+		{"friday", day(time.May, 17, 0, 0), PrecisionDay},
+		{"wednesday", day(time.May, 15, 0, 0), PrecisionDay},
+		{"last friday", day(time.May, 10, 0, 0), PrecisionDay},
+		{"next wednesday", day(time.May, 22, 0, 0), PrecisionDay},
+		{"last wed 5pm", day(time.May, 8, 17, 0), PrecisionHour},
+		{"this week", day(time.May, 13, 0, 0), PrecisionWeek},
+		{"next month", day(time.June, 1, 0, 0), PrecisionMonth},
+		{"last year", time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC), PrecisionYear},
+		{"2024-W20", day(time.May, 13, 0, 0), PrecisionWeek},
+		{"2020-W53-5", time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC), PrecisionDay},
+		{"2024-06-01", day(time.June, 1, 0, 0), PrecisionDay},
+		{"2024-06-01T08:00:00", day(time.June, 1, 8, 0), PrecisionSecond},
+		{"2024-06-01 08:05", day(time.June, 1, 8, 5), PrecisionMinute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, p, err := ParseHuman(tt.in, now, time.UTC)
+			if err != nil {
+				t.Fatalf("ParseHuman: %v", err)
+			}
+			if !got.Equal(tt.want) || p != tt.precision {
+				t.Errorf("got %v (%v), want %v (%v)", got, p, tt.want, tt.precision)
+			}
+		})
+	}
+}
+
+func TestParseHumanLocation(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	// 02:00 UTC is still the previous evening in New York.
+	now := time.Date(2024, time.May, 15, 2, 0, 0, 0, time.UTC)
+	got, _, err := ParseHuman("tomorrow 9am", now, ny)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, time.May, 15, 9, 0, 0, 0, ny); !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestParseHumanErrors(t *testing.T) {
+	now := time.Date(2024, time.May, 15, 14, 30, 0, 0, time.UTC)
+	for _, in := range []string{"", "soon", "in", "3 ago", "in 3 fortnights", "25:00", "13pm", "next tuesday 9", "2024-W54", "this month 9am"} {
+		if _, _, err := ParseHuman(in, now, nil); !errors.Is(err, ErrUnrecognized) {
+			t.Errorf("ParseHuman(%q) err = %v, want ErrUnrecognized", in, err)
+		}
+	}
+}
@@ -0,0 +1,593 @@
+package times
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidRRule is returned for recurrence rules that do not follow
+// RFC 5545.
+var ErrInvalidRRule = errors.New("times: invalid recurrence rule")
+
+// Frequency is the FREQ of a recurrence rule.
+type Frequency int
+
+const (
+	Secondly Frequency = iota
+	Minutely
+	Hourly
+	Daily
+	Weekly
+	Monthly
+	Yearly
+)
+
+var frequencyNames = [...]string{"SECONDLY", "MINUTELY", "HOURLY", "DAILY", "WEEKLY", "MONTHLY", "YEARLY"}
+
+func (f Frequency) String() string {
+	if f < 0 || int(f) >= len(frequencyNames) {
+		return strconv.Itoa(int(f))
+	}
+	return frequencyNames[f]
+}
+
+// unit returns the length of a Secondly, Minutely or Hourly period.
+func (f Frequency) unit() time.Duration {
+	switch f {
+	case Secondly:
+		return time.Second
+	case Minutely:
+		return time.Minute
+	}
+	return time.Hour
+}
+
+var icalWeekdays = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// WeekdayNum is a BYDAY entry: a weekday, and for MONTHLY and YEARLY
+// rules optionally its Nth occurrence in the month or year, counted from
+// the end when N is negative. N is 0 for every occurrence.
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int
+}
+
+func (w WeekdayNum) String() string {
+	if w.N == 0 {
+		return icalWeekdays[w.Weekday]
+	}
+	return strconv.Itoa(w.N) + icalWeekdays[w.Weekday]
+}
+
+// RRule is an RFC 5545 recurrence rule.
+type RRule struct {
+	Freq     Frequency
+	Interval int // 0 means 1
+	Count    int // 0 means unlimited
+	Until    time.Time
+
+	BySecond   []int
+	ByMinute   []int
+	ByHour     []int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByYearDay  []int
+	ByWeekNo   []int
+	ByMonth    []int
+	BySetPos   []int
+
+	// WeekStart is the first day of the week for WEEKLY rules and
+	// BYWEEKNO. ParseRRule defaults it to Monday as RFC 5545 does; rules
+	// built in code must set it.
+	WeekStart time.Weekday
+}
+
+// ParseRRule parses the value of an RRULE or EXRULE property, with or
+// without the "RRULE:" prefix. A floating UNTIL is read in loc.
+func ParseRRule(s string, loc *time.Location) (RRule, error) {
+	r := RRule{WeekStart: time.Monday}
+	value := s
+	if name, rest, ok := strings.Cut(s, ":"); ok && (strings.EqualFold(name, "RRULE") || strings.EqualFold(name, "EXRULE")) {
+		value = rest
+	}
+	invalid := func(format string, args ...any) (RRule, error) {
+		return RRule{}, fmt.Errorf("%w %q: %s", ErrInvalidRRule, s, fmt.Sprintf(format, args...))
+	}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		key = strings.ToUpper(key)
+		if !ok || val == "" {
+			return invalid("malformed part %q", part)
+		}
+		if seen[key] {
+			return invalid("repeated %s", key)
+		}
+		seen[key] = true
+		var err error
+		switch key {
+		case "FREQ":
+			i := slices.Index(frequencyNames[:], strings.ToUpper(val))
+			if i < 0 {
+				return invalid("unknown FREQ %q", val)
+			}
+			r.Freq = Frequency(i)
+		case "INTERVAL":
+			if r.Interval, err = strconv.Atoi(val); err != nil || r.Interval < 1 {
+				return invalid("bad INTERVAL %q", val)
+			}
+		case "COUNT":
+			if r.Count, err = strconv.Atoi(val); err != nil || r.Count < 1 {
+				return invalid("bad COUNT %q", val)
+			}
+		case "UNTIL":
+			if r.Until, _, err = icalTime(val, nil, loc); err != nil {
+				return invalid("bad UNTIL %q", val)
+			}
+		case "BYSECOND":
+			r.BySecond, err = rruleInts(val, 0, 60, false)
+		case "BYMINUTE":
+			r.ByMinute, err = rruleInts(val, 0, 59, false)
+		case "BYHOUR":
+			r.ByHour, err = rruleInts(val, 0, 23, false)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = rruleInts(val, 1, 31, true)
+		case "BYYEARDAY":
+			r.ByYearDay, err = rruleInts(val, 1, 366, true)
+		case "BYWEEKNO":
+			r.ByWeekNo, err = rruleInts(val, 1, 53, true)
+		case "BYMONTH":
+			r.ByMonth, err = rruleInts(val, 1, 12, false)
+		case "BYSETPOS":
+			r.BySetPos, err = rruleInts(val, 1, 366, true)
+		case "BYDAY":
+			r.ByDay, err = rruleDays(val)
+		case "WKST":
+			i := slices.Index(icalWeekdays[:], strings.ToUpper(val))
+			if i < 0 {
+				return invalid("bad WKST %q", val)
+			}
+			r.WeekStart = time.Weekday(i)
+		default:
+			if !strings.HasPrefix(key, "X-") {
+				return invalid("unknown part %s", key)
+			}
+		}
+		if err != nil {
+			return invalid("bad %s: %v", key, err)
+		}
+	}
+	switch {
+	case !seen["FREQ"]:
+		return invalid("missing FREQ")
+	case seen["COUNT"] && seen["UNTIL"]:
+		return invalid("both COUNT and UNTIL")
+	}
+	return r, nil
+}
+
+// rruleInts parses a comma-separated list of integers in [lo, hi], or
+// also in [-hi, -lo] when signed.
+func rruleInts(s string, lo, hi int, signed bool) ([]int, error) {
+	var out []int
+	for _, f := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, err
+		}
+		abs := n
+		if signed && n < 0 {
+			abs = -n
+		}
+		if abs < lo || abs > hi {
+			return nil, fmt.Errorf("%d out of range", n)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func rruleDays(s string) ([]WeekdayNum, error) {
+	var out []WeekdayNum
+	for _, f := range strings.Split(strings.ToUpper(s), ",") {
+		if len(f) < 2 {
+			return nil, fmt.Errorf("bad day %q", f)
+		}
+		wd := slices.Index(icalWeekdays[:], f[len(f)-2:])
+		if wd < 0 {
+			return nil, fmt.Errorf("bad day %q", f)
+		}
+		w := WeekdayNum{Weekday: time.Weekday(wd)}
+		if num := f[:len(f)-2]; num != "" {
+			n, err := strconv.Atoi(num)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("bad day %q", f)
+			}
+			w.N = n
+		}
+		out = append(out, w)
+	}
+	return out, nil
+}
+
+// String formats r as an RRULE value, without the "RRULE:" prefix. UNTIL
+// is written in UTC.
+func (r RRule) String() string {
+	return r.format(false)
+}
+
+// format formats r, writing UNTIL as a DATE for rules of all-day events.
+func (r RRule) format(allDay bool) string {
+	parts := []string{"FREQ=" + r.Freq.String()}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		if allDay {
+			parts = append(parts, "UNTIL="+r.Until.Format(icalDate))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format(icalDateTimeUTC))
+		}
+	}
+	ints := func(name string, v []int) {
+		if len(v) == 0 {
+			return
+		}
+		s := make([]string, len(v))
+		for i, n := range v {
+			s[i] = strconv.Itoa(n)
+		}
+		parts = append(parts, name+"="+strings.Join(s, ","))
+	}
+	ints("BYSECOND", r.BySecond)
+	ints("BYMINUTE", r.ByMinute)
+	ints("BYHOUR", r.ByHour)
+	if len(r.ByDay) > 0 {
+		s := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			s[i] = d.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(s, ","))
+	}
+	ints("BYMONTHDAY", r.ByMonthDay)
+	ints("BYYEARDAY", r.ByYearDay)
+	ints("BYWEEKNO", r.ByWeekNo)
+	ints("BYMONTH", r.ByMonth)
+	ints("BYSETPOS", r.BySetPos)
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+icalWeekdays[r.WeekStart])
+	}
+	return strings.Join(parts, ";")
+}
+
+// Between returns the occurrences of r starting at dtstart that fall in
+// [from, to), in order. Times of day follow dtstart's wall clock in its
+// location, except for SECONDLY, MINUTELY and HOURLY rules, which step in
+// elapsed time.
+func (r RRule) Between(dtstart, from, to time.Time) []time.Time {
+	var out []time.Time
+	r.each(dtstart, from, to, func(t time.Time) {
+		if !t.Before(from) {
+			out = append(out, t)
+		}
+	})
+	return out
+}
+
+// each calls fn with every occurrence from dtstart up to to. Occurrences
+// before from may be skipped unless they count towards COUNT.
+func (r RRule) each(dtstart, from, to time.Time, fn func(time.Time)) {
+	r = r.withDefaults(dtstart)
+	interval := max(r.Interval, 1)
+	n := 0
+	if r.Count == 0 {
+		n = max(0, r.unitsBetween(dtstart, from)/interval-1)
+	}
+	count := 0
+	for ; ; n++ {
+		lo, set := r.period(dtstart, n*interval)
+		if !lo.Before(to) || (!r.Until.IsZero() && lo.After(r.Until)) {
+			return
+		}
+		for _, t := range set {
+			if t.Before(dtstart) {
+				continue
+			}
+			if !t.Before(to) || (!r.Until.IsZero() && t.After(r.Until)) {
+				return
+			}
+			fn(t)
+			if count++; r.Count > 0 && count >= r.Count {
+				return
+			}
+		}
+	}
+}
+
+// withDefaults fills in the parts RFC 5545 takes from DTSTART when a rule
+// leaves them out, such as the day of the month of a MONTHLY rule.
+func (r RRule) withDefaults(dtstart time.Time) RRule {
+	noDays := len(r.ByWeekNo) == 0 && len(r.ByYearDay) == 0 && len(r.ByMonthDay) == 0 && len(r.ByDay) == 0
+	switch {
+	case r.Freq == Yearly && noDays:
+		r.ByMonthDay = []int{dtstart.Day()}
+		if len(r.ByMonth) == 0 {
+			r.ByMonth = []int{int(dtstart.Month())}
+		}
+	case r.Freq == Monthly && noDays:
+		r.ByMonthDay = []int{dtstart.Day()}
+	case r.Freq == Weekly && len(r.ByDay) == 0:
+		r.ByDay = []WeekdayNum{{Weekday: dtstart.Weekday()}}
+	}
+	if r.Freq >= Daily {
+		if len(r.ByHour) == 0 {
+			r.ByHour = []int{dtstart.Hour()}
+		}
+		if len(r.ByMinute) == 0 {
+			r.ByMinute = []int{dtstart.Minute()}
+		}
+	}
+	if r.Freq >= Minutely && len(r.BySecond) == 0 {
+		r.BySecond = []int{dtstart.Second()}
+	}
+	if r.Freq == Hourly && len(r.ByMinute) == 0 {
+		r.ByMinute = []int{dtstart.Minute()}
+	}
+	return r
+}
+
+// unitsBetween counts whole periods of r's frequency from dtstart to t,
+// so expansion can start near a window far from dtstart.
+func (r RRule) unitsBetween(dtstart, t time.Time) int {
+	if !t.After(dtstart) {
+		return 0
+	}
+	t = t.In(dtstart.Location())
+	days := int(wall(t).Sub(wall(dtstart)).Hours() / 24)
+	switch r.Freq {
+	case Yearly:
+		return t.Year() - dtstart.Year()
+	case Monthly:
+		return (t.Year()-dtstart.Year())*12 + int(t.Month()-dtstart.Month())
+	case Weekly:
+		return days / 7
+	case Daily:
+		return days
+	}
+	return int(t.Sub(dtstart) / r.Freq.unit())
+}
+
+// period returns the start and the sorted occurrences of the period k
+// units after the one holding dtstart, before BYSETPOS and the DTSTART,
+// UNTIL and COUNT limits are applied.
+func (r RRule) period(dtstart time.Time, k int) (time.Time, []time.Time) {
+	if r.Freq < Daily {
+		return r.subDaily(dtstart, k)
+	}
+	loc := dtstart.Location()
+	y, m, d := dtstart.Date()
+	var first, end time.Time // UTC midnights bounding the period's days
+	switch r.Freq {
+	case Yearly:
+		first = time.Date(y+k, time.January, 1, 0, 0, 0, 0, time.UTC)
+		end = first.AddDate(1, 0, 0)
+	case Monthly:
+		first = time.Date(y, m+time.Month(k), 1, 0, 0, 0, 0, time.UTC)
+		end = first.AddDate(0, 1, 0)
+	case Weekly:
+		back := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		first = time.Date(y, m, d-back+7*k, 0, 0, 0, 0, time.UTC)
+		end = first.AddDate(0, 0, 7)
+	default:
+		first = time.Date(y, m, d+k, 0, 0, 0, 0, time.UTC)
+		end = first.AddDate(0, 0, 1)
+	}
+	var set []time.Time
+	for day := first; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if !r.dayMatches(day) {
+			continue
+		}
+		for _, h := range sortedInts(r.ByHour) {
+			for _, mi := range sortedInts(r.ByMinute) {
+				for _, s := range sortedInts(r.BySecond) {
+					set = append(set, localTime(day.Year(), day.Month(), day.Day(), h, mi, s, loc))
+				}
+			}
+		}
+	}
+	return time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc), r.setPos(set)
+}
+
+// localTime returns the instant loc's wall clock reads the given time.
+// A time skipped by a daylight saving change is read with the offset
+// before the change, as RFC 5545 section 3.3.5 requires, so 02:30 on a
+// spring-forward day becomes 03:30.
+func localTime(year int, month time.Month, day, hour, minute, sec int, loc *time.Location) time.Time {
+	t := time.Date(year, month, day, hour, minute, sec, 0, loc)
+	w := time.Date(year, month, day, hour, minute, sec, 0, time.UTC)
+	if wall(t).Equal(w) {
+		return t
+	}
+	// The clock jumped forward over w, so the offset before the gap is
+	// the smaller of the two around it.
+	_, before := t.Add(-3 * time.Hour).Zone()
+	if _, after := t.Add(3 * time.Hour).Zone(); after < before {
+		before = after
+	}
+	return w.Add(-time.Duration(before) * time.Second).In(loc)
+}
+
+// subDaily returns the period k units after dtstart for SECONDLY,
+// MINUTELY and HOURLY rules. Their periods step in elapsed time, so an
+// hourly rule keeps its pace across daylight saving changes.
+func (r RRule) subDaily(dtstart time.Time, k int) (time.Time, []time.Time) {
+	base := dtstart.Add(time.Duration(k) * r.Freq.unit())
+	seconds := []int{base.Second()}
+	minutes := []int{base.Minute()}
+	if r.Freq >= Minutely {
+		seconds = sortedInts(r.BySecond)
+	}
+	if r.Freq == Hourly {
+		minutes = sortedInts(r.ByMinute)
+	}
+	lo := base.Add(-time.Duration(base.Nanosecond()) - time.Duration(base.Second())*time.Second)
+	if r.Freq == Hourly {
+		lo = lo.Add(-time.Duration(base.Minute()) * time.Minute)
+	}
+	var set []time.Time
+	for _, mi := range minutes {
+		for _, s := range seconds {
+			t := lo.Add(time.Duration(s) * time.Second)
+			if r.Freq == Hourly {
+				t = t.Add(time.Duration(mi) * time.Minute)
+			}
+			if r.timeMatches(t) && r.dayMatches(wall(t)) {
+				set = append(set, t)
+			}
+		}
+	}
+	return lo, r.setPos(set)
+}
+
+// timeMatches applies BYHOUR, BYMINUTE and BYSECOND as limits.
+func (r RRule) timeMatches(t time.Time) bool {
+	return matchAny(r.ByHour, t.Hour()) && matchAny(r.ByMinute, t.Minute()) && matchAny(r.BySecond, t.Second())
+}
+
+func matchAny(list []int, v int) bool {
+	return len(list) == 0 || slices.Contains(list, v)
+}
+
+// dayMatches applies the BYMONTH, BYWEEKNO, BYYEARDAY, BYMONTHDAY and
+// BYDAY parts to day, a UTC midnight.
+func (r RRule) dayMatches(day time.Time) bool {
+	y, m, d := day.Date()
+	if !matchAny(r.ByMonth, int(m)) {
+		return false
+	}
+	monthDays := time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	yearDays := time.Date(y, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+	if len(r.ByWeekNo) > 0 {
+		wy, wk := weekNumber(day, r.WeekStart)
+		last := weeksInYear(wy, r.WeekStart)
+		if !slices.ContainsFunc(r.ByWeekNo, func(n int) bool { return n == wk || n == wk-last-1 }) {
+			return false
+		}
+	}
+	if len(r.ByYearDay) > 0 {
+		yd := day.YearDay()
+		if !slices.ContainsFunc(r.ByYearDay, func(n int) bool { return n == yd || n == yd-yearDays-1 }) {
+			return false
+		}
+	}
+	if len(r.ByMonthDay) > 0 && !slices.ContainsFunc(r.ByMonthDay, func(n int) bool { return n == d || n == d-monthDays-1 }) {
+		return false
+	}
+	if len(r.ByDay) > 0 {
+		// Ordinals count within the month for MONTHLY rules and YEARLY
+		// rules with BYMONTH, and within the year for other YEARLY rules.
+		nth, fromEnd := (d-1)/7+1, -((monthDays-d)/7 + 1)
+		if r.Freq == Yearly && len(r.ByMonth) == 0 {
+			yd := day.YearDay()
+			nth, fromEnd = (yd-1)/7+1, -((yearDays-yd)/7 + 1)
+		}
+		ordinals := r.Freq == Monthly || r.Freq == Yearly
+		if !slices.ContainsFunc(r.ByDay, func(w WeekdayNum) bool {
+			return w.Weekday == day.Weekday() && (w.N == 0 || !ordinals || w.N == nth || w.N == fromEnd)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// setPos applies BYSETPOS to a period's occurrences after sorting them
+// and dropping duplicates.
+func (r RRule) setPos(set []time.Time) []time.Time {
+	slices.SortFunc(set, func(a, b time.Time) int { return a.Compare(b) })
+	set = slices.CompactFunc(set, time.Time.Equal)
+	if len(r.BySetPos) == 0 {
+		return set
+	}
+	var out []time.Time
+	for i, t := range set {
+		if slices.ContainsFunc(r.BySetPos, func(p int) bool { return p == i+1 || p == i-len(set) }) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func sortedInts(v []int) []int {
+	return slices.Sorted(slices.Values(v))
+}
+
+// weekNumber returns the year and number of day's week, with weeks
+// starting on wkst and week 1 being the first with at least four days in
+// the year. With wkst Monday this is the ISO week.
+func weekNumber(day time.Time, wkst time.Weekday) (year, week int) {
+	// The week's fourth day decides which year it belongs to.
+	start := day.AddDate(0, 0, -((int(day.Weekday()) - int(wkst) + 7) % 7))
+	year = start.AddDate(0, 0, 3).Year()
+	first := weekOneStart(year, wkst)
+	return year, int(start.Sub(first).Hours()/24)/7 + 1
+}
+
+func weekOneStart(year int, wkst time.Weekday) time.Time {
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	return jan4.AddDate(0, 0, -((int(jan4.Weekday()) - int(wkst) + 7) % 7))
+}
+
+func weeksInYear(year int, wkst time.Weekday) int {
+	return int(weekOneStart(year+1, wkst).Sub(weekOneStart(year, wkst)).Hours()/24) / 7
+}
+
+// Recurrence is an RFC 5545 recurrence set: the occurrences of RRULEs and
+// RDATEs, less those of EXRULEs and EXDATEs.
+type Recurrence struct {
+	RRules  []RRule
+	ExRules []RRule
+	RDates  []time.Time
+	ExDates []time.Time
+}
+
+// IsZero reports whether the set has no rules or dates, so that only
+// DTSTART occurs.
+func (rs Recurrence) IsZero() bool {
+	return len(rs.RRules) == 0 && len(rs.ExRules) == 0 && len(rs.RDates) == 0 && len(rs.ExDates) == 0
+}
+
+// Between returns the occurrences of the set starting at dtstart that fall
+// in [from, to), in order. As RFC 5545 says, dtstart is always the first
+// occurrence, unless excluded.
+func (rs Recurrence) Between(dtstart, from, to time.Time) []time.Time {
+	in := func(t time.Time) bool { return !t.Before(from) && t.Before(to) }
+	var out []time.Time
+	if in(dtstart) {
+		out = append(out, dtstart)
+	}
+	for _, r := range rs.RRules {
+		out = append(out, r.Between(dtstart, from, to)...)
+	}
+	for _, t := range rs.RDates {
+		if in(t) {
+			out = append(out, t)
+		}
+	}
+	excluded := slices.Clone(rs.ExDates)
+	for _, r := range rs.ExRules {
+		excluded = append(excluded, r.Between(dtstart, from, to)...)
+	}
+	out = slices.DeleteFunc(out, func(t time.Time) bool {
+		return slices.ContainsFunc(excluded, t.Equal)
+	})
+	slices.SortFunc(out, func(a, b time.Time) int { return a.Compare(b) })
+	return slices.CompactFunc(out, time.Time.Equal)
+}
@@ -0,0 +1,190 @@
+package times
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestRRuleBetween checks the examples of RFC 5545 section 3.8.5.3.
+func TestRRuleBetween(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	tests := []struct {
+		name    string
+		dtstart string
+		rule    string
+		want    []string // the first occurrences, in New York
+	}{
+		{"daily count", "19970902T090000", "FREQ=DAILY;COUNT=10", []string{
+			"19970902T090000", "19970903T090000", "19970904T090000", "19970905T090000", "19970906T090000",
+			"19970907T090000", "19970908T090000", "19970909T090000", "19970910T090000", "19970911T090000"}},
+		{"every 10 days", "19970902T090000", "FREQ=DAILY;INTERVAL=10;COUNT=5", []string{
+			"19970902T090000", "19970912T090000", "19970922T090000", "19971002T090000", "19971012T090000"}},
+		// The wall clock stays at 09:00 across the end of daylight saving.
+		{"weekly", "19970902T090000", "FREQ=WEEKLY;COUNT=10", []string{
+			"19970902T090000", "19970909T090000", "19970916T090000", "19970923T090000", "19970930T090000",
+			"19971007T090000", "19971014T090000", "19971021T090000", "19971028T090000", "19971104T090000"}},
+		{"weekly tue thu", "19970902T090000", "FREQ=WEEKLY;UNTIL=19971007T000000Z;WKST=SU;BYDAY=TU,TH", []string{
+			"19970902T090000", "19970904T090000", "19970909T090000", "19970911T090000", "19970916T090000",
+			"19970918T090000", "19970923T090000", "19970925T090000", "19970930T090000", "19971002T090000"}},
+		{"first friday", "19970905T090000", "FREQ=MONTHLY;COUNT=10;BYDAY=1FR", []string{
+			"19970905T090000", "19971003T090000", "19971107T090000", "19971205T090000", "19980102T090000",
+			"19980206T090000", "19980306T090000", "19980403T090000", "19980501T090000", "19980605T090000"}},
+		{"second to last monday", "19970922T090000", "FREQ=MONTHLY;COUNT=6;BYDAY=-2MO", []string{
+			"19970922T090000", "19971020T090000", "19971117T090000", "19971222T090000", "19980119T090000",
+			"19980216T090000"}},
+		{"third to last day", "19970928T090000", "FREQ=MONTHLY;BYMONTHDAY=-3", []string{
+			"19970928T090000", "19971029T090000", "19971128T090000", "19971229T090000", "19980129T090000",
+			"19980226T090000"}},
+		{"june and july", "19970610T090000", "FREQ=YEARLY;COUNT=10;BYMONTH=6,7", []string{
+			"19970610T090000", "19970710T090000", "19980610T090000", "19980710T090000", "19990610T090000",
+			"19990710T090000", "20000610T090000", "20000710T090000", "20010610T090000", "20010710T090000"}},
+		{"20th monday", "19970519T090000", "FREQ=YEARLY;BYDAY=20MO", []string{
+			"19970519T090000", "19980518T090000", "19990517T090000"}},
+		{"week 20 monday", "19970512T090000", "FREQ=YEARLY;BYWEEKNO=20;BYDAY=MO", []string{
+			"19970512T090000", "19980511T090000", "19990517T090000"}},
+		{"friday 13th", "19970902T090000", "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13", []string{
+			"19980213T090000", "19980313T090000", "19981113T090000", "19990813T090000", "20001013T090000"}},
+		{"election day", "19961105T090000", "FREQ=YEARLY;INTERVAL=4;BYMONTH=11;BYDAY=TU;BYMONTHDAY=2,3,4,5,6,7,8", []string{
+			"19961105T090000", "20001107T090000", "20041102T090000"}},
+		{"third tue wed thu", "19970904T090000", "FREQ=MONTHLY;COUNT=3;BYDAY=TU,WE,TH;BYSETPOS=3", []string{
+			"19970904T090000", "19971007T090000", "19971106T090000"}},
+		{"second to last weekday", "19970929T090000", "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-2", []string{
+			"19970929T090000", "19971030T090000", "19971127T090000", "19971230T090000", "19980129T090000",
+			"19980226T090000", "19980330T090000"}},
+		{"every 15 minutes", "19970902T090000", "FREQ=MINUTELY;INTERVAL=15;COUNT=6", []string{
+			"19970902T090000", "19970902T091500", "19970902T093000", "19970902T094500", "19970902T100000",
+			"19970902T101500"}},
+		{"every 20 minutes in office hours", "19970902T090000", "FREQ=DAILY;BYHOUR=9,10,11,12,13,14,15,16;BYMINUTE=0,20,40", []string{
+			"19970902T090000", "19970902T092000", "19970902T094000", "19970902T100000"}},
+		{"wkst monday", "19970805T090000", "FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=MO", []string{
+			"19970805T090000", "19970810T090000", "19970819T090000", "19970824T090000"}},
+		{"wkst sunday", "19970805T090000", "FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=SU", []string{
+			"19970805T090000", "19970817T090000", "19970819T090000", "19970831T090000"}},
+		{"skips invalid dates", "20070115T090000", "FREQ=MONTHLY;BYMONTHDAY=15,30;COUNT=5", []string{
+			"20070115T090000", "20070130T090000", "20070215T090000", "20070315T090000", "20070330T090000"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dtstart, err := time.ParseInLocation(icalDateTime, tt.dtstart, ny)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := ParseRRule(tt.rule, ny)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rs := Recurrence{RRules: []RRule{r}}
+			if tt.name == "friday 13th" {
+				rs.ExDates = []time.Time{dtstart}
+			}
+			got := rs.Between(dtstart, dtstart, dtstart.AddDate(10, 0, 0))
+			if len(got) < len(tt.want) {
+				t.Fatalf("got %d occurrences, want at least %d: %v", len(got), len(tt.want), got)
+			}
+			for i, w := range tt.want {
+				if s := got[i].In(ny).Format(icalDateTime); s != w {
+					t.Errorf("occurrence %d = %s, want %s", i, s, w)
+				}
+			}
+			if r.Count > 0 && len(got) != r.Count {
+				t.Errorf("got %d occurrences, want COUNT=%d", len(got), r.Count)
+			}
+		})
+	}
+}
+
+func TestRRuleWindow(t *testing.T) {
+	dtstart := time.Date(2000, time.January, 1, 8, 0, 0, 0, time.UTC)
+	r, err := ParseRRule("RRULE:FREQ=DAILY", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	got := r.Between(dtstart, from, from.AddDate(0, 0, 3))
+	want := []time.Time{from.Add(8 * time.Hour), from.Add(32 * time.Hour), from.Add(56 * time.Hour)}
+	if !slices.EqualFunc(got, want, time.Time.Equal) {
+		t.Errorf("Between = %v, want %v", got, want)
+	}
+
+	// COUNT is counted from DTSTART, not from the window.
+	r.Count = 3
+	if got := r.Between(dtstart, from, from.AddDate(0, 0, 3)); len(got) != 0 {
+		t.Errorf("Between with an exhausted COUNT = %v", got)
+	}
+}
+
+func TestRRuleHourlyDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	dtstart := time.Date(2024, time.November, 3, 0, 0, 0, 0, ny)
+	r := RRule{Freq: Hourly, Count: 4, WeekStart: time.Monday}
+	got := r.Between(dtstart, dtstart, dtstart.AddDate(0, 0, 1))
+	for i := 1; i < len(got); i++ {
+		if d := got[i].Sub(got[i-1]); d != time.Hour {
+			t.Errorf("occurrence %d is %v after the previous", i, d)
+		}
+	}
+}
+
+func TestRRuleDailyDSTGap(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	dtstart := time.Date(2024, time.March, 9, 2, 30, 0, 0, ny)
+	r := RRule{Freq: Daily, Count: 3, WeekStart: time.Monday}
+	got := r.Between(dtstart, dtstart, dtstart.AddDate(0, 0, 3))
+	// 02:30 does not exist on 10 March; it is read with the EST offset.
+	want := []time.Time{
+		dtstart,
+		time.Date(2024, time.March, 10, 7, 30, 0, 0, time.UTC),
+		time.Date(2024, time.March, 11, 2, 30, 0, 0, ny),
+	}
+	if !slices.EqualFunc(got, want, time.Time.Equal) {
+		t.Errorf("Between = %v, want %v", got, want)
+	}
+	if len(got) > 1 {
+		if h, m, _ := got[1].In(ny).Clock(); h != 3 || m != 30 {
+			t.Errorf("occurrence in the gap = %v, want 03:30 EDT", got[1].In(ny))
+		}
+	}
+}
+
+func TestRRuleString(t *testing.T) {
+	for _, s := range []string{
+		"FREQ=DAILY",
+		"FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=SU",
+		"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-2",
+		"FREQ=YEARLY;UNTIL=20251231T235959Z;BYDAY=-1SU;BYMONTH=10",
+		"FREQ=MINUTELY;BYSECOND=0,30;BYMINUTE=5;BYHOUR=9",
+	} {
+		r, err := ParseRRule(s, time.UTC)
+		if err != nil {
+			t.Errorf("ParseRRule(%q): %v", s, err)
+			continue
+		}
+		if got := r.String(); got != s {
+			t.Errorf("String = %q, want %q", got, s)
+		}
+	}
+}
+
+func TestParseRRuleErrors(t *testing.T) {
+	for _, s := range []string{
+		"", "COUNT=3", "FREQ=FORTNIGHTLY", "FREQ=DAILY;COUNT=0", "FREQ=DAILY;INTERVAL=-1",
+		"FREQ=DAILY;COUNT=2;UNTIL=20240101", "FREQ=DAILY;BYHOUR=24", "FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=MONTHLY;BYDAY=6XX", "FREQ=MONTHLY;BYDAY=54MO", "FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;WKST=XX", "FREQ=DAILY;UNTIL=tomorrow", "FREQ=DAILY;SOMETIMES=1",
+	} {
+		if _, err := ParseRRule(s, time.UTC); !errors.Is(err, ErrInvalidRRule) {
+			t.Errorf("ParseRRule(%q) err = %v, want ErrInvalidRRule", s, err)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package times
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// ErrDuplicateJob is returned by Scheduler.Add for a name already in use.
+var ErrDuplicateJob = errors.New("times: duplicate job name")
+
+// Job is work run by a Scheduler. Its context is canceled when the
+// scheduler stops.
+type Job func(ctx context.Context) error
+
+// OverlapPolicy decides what happens when a job is due while its previous
+// run is still going.
+type OverlapPolicy int
+
+const (
+	// OverlapSkip drops the new run. It is the default.
+	OverlapSkip OverlapPolicy = iota
+	// OverlapAllow starts the new run alongside the old one.
+	OverlapAllow
+	// OverlapQueue starts the new run as soon as the old one returns.
+	// Runs due meanwhile collapse into one.
+	OverlapQueue
+)
+
+// JobOption configures a job added to a Scheduler.
+type JobOption func(*entry)
+
+// WithOverlap sets what happens when a run is due while the previous one
+// is still going.
+func WithOverlap(p OverlapPolicy) JobOption {
+	return func(e *entry) {
+		e.overlap = p
+	}
+}
+
+// WithJitter delays each run by a random duration up to d, so jobs
+// scheduled on many machines for the same time do not all fire at once.
+// Keep it small relative to the schedule's interval.
+func WithJitter(d time.Duration) JobOption {
+	return func(e *entry) {
+		e.jitter = d
+	}
+}
+
+// SchedulerOption configures NewScheduler: WithErrorHandler and
+// WithClock.
+type SchedulerOption interface {
+	applyScheduler(*Scheduler)
+}
+
+type schedulerOption func(*Scheduler)
+
+func (f schedulerOption) applyScheduler(s *Scheduler) { f(s) }
+
+// WithErrorHandler sets a function called with the name and error of every
+// failed Scheduler job. By default errors are dropped.
+func WithErrorHandler(fn func(name string, err error)) SchedulerOption {
+	return schedulerOption(func(s *Scheduler) {
+		s.onError = fn
+	})
+}
+
+type entry struct {
+	name     string
+	schedule *Schedule
+	job      Job
+	overlap  OverlapPolicy
+	jitter   time.Duration
+
+	next    time.Time // scheduled time of the next run
+	fireAt  time.Time // next plus jitter
+	running int
+	queued  bool
+}
+
+// Scheduler runs jobs on cron schedules.
+type Scheduler struct {
+	onError func(name string, err error)
+	clock   Clock
+	rand    func() float64
+
+	mu      sync.Mutex
+	entries []*entry
+	wake    chan struct{}
+}
+
+// NewScheduler returns a scheduler with no jobs.
+func NewScheduler(opts ...SchedulerOption) *Scheduler {
+	s := &Scheduler{
+		clock: RealClock(),
+		rand:  rand.Float64,
+		wake:  make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt.applyScheduler(s)
+	}
+	return s
+}
+
+// Add parses spec with ParseCron and adds job under name.
+func (s *Scheduler) Add(name, spec string, job Job, opts ...JobOption) error {
+	sched, err := ParseCron(spec)
+	if err != nil {
+		return err
+	}
+	return s.AddSchedule(name, sched, job, opts...)
+}
+
+// AddSchedule adds job under name to run on sched. Jobs may be added
+// while the scheduler is running.
+func (s *Scheduler) AddSchedule(name string, sched *Schedule, job Job, opts ...JobOption) error {
+	e := &entry{name: name, schedule: sched, job: job}
+	for _, opt := range opts {
+		opt(e)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, other := range s.entries {
+		if other.name == name {
+			return fmt.Errorf("%w: %q", ErrDuplicateJob, name)
+		}
+	}
+	s.entries = append(s.entries, e)
+	s.poke()
+	return nil
+}
+
+// Remove stops scheduling the job called name. A run in progress is left
+// to finish.
+func (s *Scheduler) Remove(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, e := range s.entries {
+		if e.name == name {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			break
+		}
+	}
+	s.poke()
+}
+
+// Next returns when the job called name is next due, without jitter, and
+// false if there is no such job or it will not run again.
+func (s *Scheduler) Next(name string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.entries {
+		if e.name == name {
+			if e.next.IsZero() {
+				e.next = e.schedule.Next(s.clock.Now())
+			}
+			return e.next, !e.next.IsZero()
+		}
+	}
+	return time.Time{}, false
+}
+
+func (s *Scheduler) poke() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run runs due jobs until ctx is done, then waits for running jobs to
+// return and returns ctx.Err().
+func (s *Scheduler) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	timer := s.clock.NewTimer(idleWait)
+	defer timer.Stop()
+	for {
+		wait := s.dispatch(ctx, &wg)
+		timer.Reset(wait)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C():
+		case <-s.wake:
+		}
+	}
+}
+
+// idleWait is how long Run sleeps when no job is due at all.
+const idleWait = time.Hour
+
+// dispatch starts every due job and returns how long to wait for the next.
+func (s *Scheduler) dispatch(ctx context.Context, wg *sync.WaitGroup) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock.Now()
+	wait := idleWait
+	for _, e := range s.entries {
+		if e.next.IsZero() {
+			e.next = e.schedule.Next(now)
+		}
+		if e.next.IsZero() {
+			continue
+		}
+		if e.fireAt.IsZero() {
+			e.fireAt = e.next.Add(time.Duration(s.rand() * float64(e.jitter)))
+		}
+		if !e.fireAt.After(now) {
+			s.start(ctx, wg, e)
+			e.next = e.schedule.Next(now)
+			e.fireAt = time.Time{}
+			if e.next.IsZero() {
+				continue
+			}
+			e.fireAt = e.next.Add(time.Duration(s.rand() * float64(e.jitter)))
+		}
+		wait = min(wait, e.fireAt.Sub(now))
+	}
+	return wait
+}
+
+// start runs e unless its overlap policy says otherwise. s.mu is held.
+func (s *Scheduler) start(ctx context.Context, wg *sync.WaitGroup, e *entry) {
+	if e.running > 0 {
+		switch e.overlap {
+		case OverlapSkip:
+			return
+		case OverlapQueue:
+			e.queued = true
+			return
+		}
+	}
+	e.running++
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			if err := e.job(ctx); err != nil && s.onError != nil {
+				s.onError(e.name, err)
+			}
+			s.mu.Lock()
+			if e.queued && ctx.Err() == nil {
+				e.queued = false
+				s.mu.Unlock()
+				continue
+			}
+			e.queued = false
+			e.running--
+			s.mu.Unlock()
+			return
+		}
+	}()
+}
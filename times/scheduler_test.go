@@ -0,0 +1,158 @@
+package times
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// runFor runs s until d has passed and returns what Run returned.
+func runFor(t *testing.T, s *Scheduler, d time.Duration) error {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(t.Context(), d)
+	defer cancel()
+	return s.Run(ctx)
+}
+
+func TestSchedulerRunsJobs(t *testing.T) {
+	var mu sync.Mutex
+	var failures []string
+	s := NewScheduler(WithErrorHandler(func(name string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		failures = append(failures, name+": "+err.Error())
+	}))
+	var ok, failing atomic.Int32
+	if err := s.Add("ok", "@every 10ms", func(context.Context) error {
+		ok.Add(1)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add("failing", "@every 10ms", func(context.Context) error {
+		failing.Add(1)
+		return errors.New("boom")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runFor(t, s, 105*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run = %v, want DeadlineExceeded", err)
+	}
+	if n := ok.Load(); n < 5 || n > 11 {
+		t.Errorf("ok ran %d times, want about 10", n)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(failures) == 0 || failures[0] != "failing: boom" {
+		t.Errorf("failures = %v", failures)
+	}
+}
+
+func TestSchedulerOverlap(t *testing.T) {
+	tests := []struct {
+		policy  OverlapPolicy
+		maxRuns int32
+		minRuns int32
+		maxConc int32
+	}{
+		{OverlapSkip, 3, 2, 1},
+		{OverlapQueue, 4, 3, 1},
+		{OverlapAllow, 20, 5, 10},
+	}
+	for _, tt := range tests {
+		var runs, running, peak atomic.Int32
+		s := NewScheduler()
+		err := s.Add("slow", "@every 10ms", func(ctx context.Context) error {
+			runs.Add(1)
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			select {
+			case <-time.After(45 * time.Millisecond):
+			case <-ctx.Done():
+			}
+			return nil
+		}, WithOverlap(tt.policy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = runFor(t, s, 115*time.Millisecond)
+		if n := runs.Load(); n < tt.minRuns || n > tt.maxRuns {
+			t.Errorf("policy %d: %d runs, want %d-%d", tt.policy, n, tt.minRuns, tt.maxRuns)
+		}
+		if p := peak.Load(); p > tt.maxConc || (tt.policy == OverlapAllow && p < 2) {
+			t.Errorf("policy %d: %d concurrent runs", tt.policy, p)
+		}
+	}
+}
+
+func TestSchedulerJitter(t *testing.T) {
+	s := NewScheduler()
+	s.rand = func() float64 { return 0.5 }
+	start := time.Now()
+	fired := make(chan time.Time, 1)
+	if err := s.Add("j", "@every 10ms", func(context.Context) error {
+		select {
+		case fired <- time.Now():
+		default:
+		}
+		return nil
+	}, WithJitter(40*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	_ = runFor(t, s, 60*time.Millisecond)
+	select {
+	case at := <-fired:
+		if d := at.Sub(start); d < 30*time.Millisecond {
+			t.Errorf("first run after %v, want at least 10ms + 20ms jitter", d)
+		}
+	default:
+		t.Error("job never ran")
+	}
+}
+
+func TestSchedulerAddRemove(t *testing.T) {
+	s := NewScheduler()
+	noop := func(context.Context) error { return nil }
+	if err := s.Add("a", "TZ=UTC 0 0 * * *", noop); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add("a", "@hourly", noop); !errors.Is(err, ErrDuplicateJob) {
+		t.Errorf("duplicate Add = %v, want ErrDuplicateJob", err)
+	}
+	if err := s.Add("b", "not cron", noop); !errors.Is(err, ErrInvalidCron) {
+		t.Errorf("bad spec Add = %v, want ErrInvalidCron", err)
+	}
+	next, ok := s.Next("a")
+	if !ok || next.Hour() != 0 || next.Minute() != 0 || !next.After(time.Now()) {
+		t.Errorf("Next(a) = %v, %v", next, ok)
+	}
+	s.Remove("a")
+	if _, ok := s.Next("a"); ok {
+		t.Error("Next after Remove should report false")
+	}
+}
+
+func TestSchedulerStopsRunningJobs(t *testing.T) {
+	s := NewScheduler()
+	var stopped atomic.Bool
+	if err := s.Add("long", "@every 5ms", func(ctx context.Context) error {
+		<-ctx.Done()
+		stopped.Store(true)
+		return ctx.Err()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	_ = runFor(t, s, 30*time.Millisecond)
+	if !stopped.Load() {
+		t.Error("Run returned before the running job saw cancellation")
+	}
+}
@@ -28,22 +28,42 @@ func ConvertToLocation(layout string, value string, location string) (time.Time,
 	return t.In(loc), nil
 }
 
-func IsWithinDays(utc time.Time, days int) bool {
-	start := time.Now().UTC()
-	end := time.Now().UTC().AddDate(0, 0, days)
+// IsWithinDays reports whether utc is after now and before the same time
+// days from now, reading now from WithClock if given.
+func IsWithinDays(utc time.Time, days int, opts ...ClockOption) bool {
+	start := clockOf(opts).Now().UTC()
+	end := start.AddDate(0, 0, days)
 	if utc.After(start) && utc.Before(end) {
 		return true
 	}
 	return false
 }
 
-func DateSince(year int, month time.Month, day int, location *time.Location) string {
+// DateSinceOption configures DateSince: the HumanizeWith options, and
+// WithClock.
+type DateSinceOption interface {
+	applyDateSince(*dateSinceOptions)
+}
+
+type dateSinceOptions struct {
+	humanizeOptions
+	clock Clock
+}
+
+// DateSince describes the time from midnight of the given date to now
+// with HumanizeBetween, so years and months follow the calendar.
+func DateSince(year int, month time.Month, day int, location *time.Location, opts ...DateSinceOption) string {
+	o := dateSinceOptions{humanizeOptions: newHumanizeOptions(nil), clock: RealClock()}
+	for _, opt := range opts {
+		opt.applyDateSince(&o)
+	}
 	start := time.Date(year, month, day, 0, 0, 0, 0, location)
-	now := time.Now().In(location)
-	since := now.Sub(start)
-	return Humanize(since)
+	return o.humanizeOptions.between(start, o.clock.Now().In(location))
 }
 
+// Humanize describes duration in days, hours, minutes and seconds, and
+// returns "" under a second. HumanizeWith offers larger units, rounding,
+// abbreviations, relative wording and locales.
 func Humanize(duration time.Duration) string {
 	days := int64(duration.Hours() / 24)
 	hours := int64(math.Mod(duration.Hours(), 24))
@@ -0,0 +1,406 @@
+package webhook
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CloudEventsSpecVersion is the CloudEvents version produced and accepted.
+const CloudEventsSpecVersion = "1.0"
+
+const (
+	contentTypeCloudEvents  = "application/cloudevents+json"
+	cloudEventsHeaderPrefix = "Ce-"
+)
+
+// ErrNotCloudEvent is returned by ParseEvent for requests that carry
+// neither a structured nor a binary mode CloudEvent.
+var ErrNotCloudEvent = errors.New("webhook: request is not a CloudEvent")
+
+// ContentMode selects how a CloudEvent is laid out on the wire.
+type ContentMode int
+
+const (
+	// ModeStructured sends the whole event, attributes and data, as a
+	// single application/cloudevents+json document.
+	ModeStructured ContentMode = iota
+	// ModeBinary sends the data as the body and every attribute as a
+	// ce-<name> header, percent-encoded where the HTTP binding requires.
+	ModeBinary
+)
+
+// Event is a CloudEvents 1.0 envelope. Data holds the encoded payload,
+// in the format named by DataContentType.
+type Event struct {
+	SpecVersion     string
+	ID              string
+	Source          string
+	Type            string
+	Subject         string
+	Time            time.Time
+	DataContentType string
+	DataSchema      string
+	Data            []byte
+	// Extensions holds any further attributes. Binary mode sends them as
+	// strings; ParseEvent returns binary mode extensions as strings too.
+	Extensions map[string]interface{}
+}
+
+// DataAs decodes the event data as JSON into v.
+func (e *Event) DataAs(v interface{}) error {
+	if !isJSONContentType(e.DataContentType) {
+		return fmt.Errorf("webhook: cannot decode %s data as JSON", e.DataContentType)
+	}
+	if err := json.Unmarshal(e.Data, v); err != nil {
+		return fmt.Errorf("webhook: decode event data: %w", err)
+	}
+	return nil
+}
+
+// Validate reports missing required attributes.
+func (e *Event) Validate() error {
+	var missing []string
+	if e.SpecVersion == "" {
+		missing = append(missing, "specversion")
+	}
+	if e.ID == "" {
+		missing = append(missing, "id")
+	}
+	if e.Source == "" {
+		missing = append(missing, "source")
+	}
+	if e.Type == "" {
+		missing = append(missing, "type")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("webhook: event missing %s", strings.Join(missing, ", "))
+	}
+	if e.SpecVersion != CloudEventsSpecVersion {
+		return fmt.Errorf("webhook: unsupported specversion %q", e.SpecVersion)
+	}
+	return nil
+}
+
+var reservedAttributes = map[string]bool{
+	"specversion": true, "id": true, "source": true, "type": true, "subject": true,
+	"time": true, "datacontenttype": true, "dataschema": true, "data": true, "data_base64": true,
+}
+
+// MarshalJSON renders the structured mode representation. JSON data is
+// embedded as-is; anything else goes into data_base64. Empty data is
+// left out.
+func (e Event) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{}, len(e.Extensions)+9)
+	for k, v := range e.Extensions {
+		m[k] = v
+	}
+	m["specversion"] = e.SpecVersion
+	m["id"] = e.ID
+	m["source"] = e.Source
+	m["type"] = e.Type
+	if e.Subject != "" {
+		m["subject"] = e.Subject
+	}
+	if !e.Time.IsZero() {
+		m["time"] = e.Time.Format(time.RFC3339Nano)
+	}
+	if e.DataContentType != "" {
+		m["datacontenttype"] = e.DataContentType
+	}
+	if e.DataSchema != "" {
+		m["dataschema"] = e.DataSchema
+	}
+	if len(e.Data) > 0 {
+		if isJSONContentType(e.DataContentType) {
+			m["data"] = json.RawMessage(e.Data)
+		} else {
+			m["data_base64"] = base64.StdEncoding.EncodeToString(e.Data)
+		}
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON parses the structured mode representation.
+func (e *Event) UnmarshalJSON(b []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	str := func(key string) (string, error) {
+		var s string
+		if raw, ok := m[key]; ok {
+			if err := json.Unmarshal(raw, &s); err != nil {
+				return "", fmt.Errorf("webhook: event attribute %s: %w", key, err)
+			}
+		}
+		return s, nil
+	}
+	var out Event
+	var err error
+	for key, dst := range map[string]*string{
+		"specversion": &out.SpecVersion, "id": &out.ID, "source": &out.Source, "type": &out.Type,
+		"subject": &out.Subject, "datacontenttype": &out.DataContentType, "dataschema": &out.DataSchema,
+	} {
+		if *dst, err = str(key); err != nil {
+			return err
+		}
+	}
+	ts, err := str("time")
+	if err != nil {
+		return err
+	}
+	if ts != "" {
+		if out.Time, err = time.Parse(time.RFC3339Nano, ts); err != nil {
+			return fmt.Errorf("webhook: event attribute time: %w", err)
+		}
+	}
+	if raw, ok := m["data_base64"]; ok {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return fmt.Errorf("webhook: event attribute data_base64: %w", err)
+		}
+		if out.Data, err = base64.StdEncoding.DecodeString(s); err != nil {
+			return fmt.Errorf("webhook: event attribute data_base64: %w", err)
+		}
+	} else if raw, ok := m["data"]; ok {
+		out.Data = raw
+		// Non-JSON data carried in "data" is a JSON string holding the text.
+		var s string
+		if !isJSONContentType(out.DataContentType) && json.Unmarshal(raw, &s) == nil {
+			out.Data = []byte(s)
+		}
+	}
+	for k, raw := range m {
+		if reservedAttributes[k] {
+			continue
+		}
+		var v interface{}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return err
+		}
+		if out.Extensions == nil {
+			out.Extensions = map[string]interface{}{}
+		}
+		out.Extensions[k] = v
+	}
+	*e = out
+	return nil
+}
+
+// WithCloudEvent wraps the encoded body in a CloudEvent built from
+// template. SpecVersion, ID and Time are filled in when empty, and
+// DataContentType defaults to the request's content type. Source and
+// Type are required.
+func WithCloudEvent(template Event, mode ContentMode) Option {
+	return func(r *request) {
+		e := template
+		r.event = &e
+		r.eventMode = mode
+	}
+}
+
+// SendEvent sends e to url as a CloudEvent in the given mode. e.Data is
+// sent as-is; use Send with WithCloudEvent to encode a Go value.
+func SendEvent(ctx context.Context, client *http.Client, url string, e Event, mode ContentMode, opts ...Option) error {
+	if e.DataContentType == "" {
+		e.DataContentType = contentTypeJSON
+	}
+	// Clip so the appends never write into the caller's backing array.
+	opts = append(slices.Clip(opts), WithCloudEvent(e, mode), WithRaw(e.DataContentType))
+	return Send(ctx, client, url, e.Data, opts...)
+}
+
+// wrapEvent applies the request's CloudEvent to an encoded body.
+func (r *request) wrapEvent(body []byte, now time.Time) ([]byte, error) {
+	e := r.event
+	if e.SpecVersion == "" {
+		e.SpecVersion = CloudEventsSpecVersion
+	}
+	if e.ID == "" {
+		e.ID = newEventID()
+	}
+	if e.Time.IsZero() {
+		e.Time = now.UTC()
+	}
+	if e.DataContentType == "" {
+		e.DataContentType = r.contentType
+	}
+	e.Data = body
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	if r.eventMode == ModeStructured {
+		r.contentType = contentTypeCloudEvents
+		return json.Marshal(e)
+	}
+	r.contentType = e.DataContentType
+	for name, value := range e.attributes() {
+		r.header.Set(cloudEventsHeaderPrefix+name, encodeHeaderValue(value))
+	}
+	return body, nil
+}
+
+// attributes returns every attribute except data as strings, as used by
+// binary mode.
+func (e *Event) attributes() map[string]string {
+	attrs := map[string]string{
+		"specversion": e.SpecVersion,
+		"id":          e.ID,
+		"source":      e.Source,
+		"type":        e.Type,
+	}
+	if e.Subject != "" {
+		attrs["subject"] = e.Subject
+	}
+	if !e.Time.IsZero() {
+		attrs["time"] = e.Time.Format(time.RFC3339Nano)
+	}
+	if e.DataSchema != "" {
+		attrs["dataschema"] = e.DataSchema
+	}
+	for k, v := range e.Extensions {
+		attrs[k] = fmt.Sprint(v)
+	}
+	return attrs
+}
+
+// ParseEvent reads a structured or binary mode CloudEvent from r. The
+// body is limited to 1 MiB.
+func ParseEvent(r *http.Request) (*Event, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, defaultMaxBodyBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("webhook: read body: %w", err)
+	}
+	if len(body) > defaultMaxBodyBytes {
+		return nil, errors.New("webhook: event body too large")
+	}
+	return parseEvent(r.Header, body)
+}
+
+func parseEvent(h http.Header, body []byte) (*Event, error) {
+	mediaType, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	if mediaType == contentTypeCloudEvents {
+		var e Event
+		if err := json.Unmarshal(body, &e); err != nil {
+			return nil, fmt.Errorf("webhook: decode event: %w", err)
+		}
+		if err := e.Validate(); err != nil {
+			return nil, err
+		}
+		return &e, nil
+	}
+	if h.Get(cloudEventsHeaderPrefix+"Specversion") == "" {
+		return nil, ErrNotCloudEvent
+	}
+	e := &Event{DataContentType: h.Get("Content-Type"), Data: body}
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		canonical := textproto.CanonicalMIMEHeaderKey(k)
+		if !strings.HasPrefix(canonical, cloudEventsHeaderPrefix) {
+			continue
+		}
+		name := strings.ToLower(strings.TrimPrefix(canonical, cloudEventsHeaderPrefix))
+		value, err := decodeHeaderValue(h.Get(k))
+		if err != nil {
+			return nil, fmt.Errorf("webhook: event attribute %s: %w", name, err)
+		}
+		switch name {
+		case "specversion":
+			e.SpecVersion = value
+		case "id":
+			e.ID = value
+		case "source":
+			e.Source = value
+		case "type":
+			e.Type = value
+		case "subject":
+			e.Subject = value
+		case "dataschema":
+			e.DataSchema = value
+		case "time":
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return nil, fmt.Errorf("webhook: event attribute time: %w", err)
+			}
+			e.Time = t
+		default:
+			if e.Extensions == nil {
+				e.Extensions = map[string]interface{}{}
+			}
+			e.Extensions[name] = value
+		}
+	}
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// encodeHeaderValue percent-encodes the UTF-8 bytes of s that the
+// CloudEvents HTTP binding does not allow in a header value: space, '"',
+// '%' and anything outside printable ASCII.
+func encodeHeaderValue(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f || c == '"' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// decodeHeaderValue undoes encodeHeaderValue. The result must be UTF-8.
+func decodeHeaderValue(s string) (string, error) {
+	if !strings.Contains(s, "%") {
+		return s, nil
+	}
+	out, err := url.PathUnescape(s)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.ValidString(out) {
+		return "", fmt.Errorf("invalid UTF-8 in %q", s)
+	}
+	return out, nil
+}
+
+// isJSONContentType reports whether ct is empty (the CloudEvents default
+// of application/json) or a JSON media type.
+func isJSONContentType(ct string) bool {
+	if ct == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	return mediaType == contentTypeJSON || strings.HasSuffix(mediaType, "+json")
+}
+
+func newEventID() string {
+	var b [16]byte
+	_, _ = crand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
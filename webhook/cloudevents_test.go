@@ -0,0 +1,195 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// captureEvents returns a server that parses every request with ParseEvent
+// and stores the last result.
+func captureEvents(t *testing.T) (*httptest.Server, func() (*Event, *http.Request)) {
+	t.Helper()
+	var last *Event
+	var lastReq *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e, err := ParseEvent(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		last, lastReq = e, r
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() (*Event, *http.Request) { return last, lastReq }
+}
+
+func TestCloudEventStructured(t *testing.T) {
+	server, last := captureEvents(t)
+	template := Event{Source: "/orders", Type: "com.example.order.created", Subject: "42",
+		Extensions: map[string]interface{}{"tenant": "acme"}}
+
+	err := Send(context.Background(), server.Client(), server.URL, testPayload{Message: "hi", ID: 42},
+		WithCloudEvent(template, ModeStructured))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, r := last()
+	if ct := r.Header.Get("Content-Type"); ct != "application/cloudevents+json" {
+		t.Errorf("Content-Type = %s", ct)
+	}
+	if e.SpecVersion != "1.0" || e.ID == "" || e.Time.IsZero() || e.Subject != "42" {
+		t.Errorf("attributes = %+v", e)
+	}
+	if e.DataContentType != "application/json" || e.Extensions["tenant"] != "acme" {
+		t.Errorf("datacontenttype/extensions = %q %v", e.DataContentType, e.Extensions)
+	}
+	var got testPayload
+	if err := e.DataAs(&got); err != nil || got.ID != 42 {
+		t.Errorf("DataAs = %+v, %v", got, err)
+	}
+}
+
+func TestCloudEventBinary(t *testing.T) {
+	server, last := captureEvents(t)
+	template := Event{ID: "evt-1", Source: "/orders", Type: "order.created",
+		Time: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC), Extensions: map[string]interface{}{"traceparent": "00-abc"}}
+
+	err := Send(context.Background(), server.Client(), server.URL, testPayload{Message: "bin"},
+		WithCloudEvent(template, ModeBinary))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, r := last()
+	if r.Header.Get("Ce-Id") != "evt-1" || r.Header.Get("Ce-Specversion") != "1.0" {
+		t.Errorf("ce headers = %v", r.Header)
+	}
+	if r.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Content-Type = %s", r.Header.Get("Content-Type"))
+	}
+	if !e.Time.Equal(template.Time) || e.Extensions["traceparent"] != "00-abc" {
+		t.Errorf("event = %+v", e)
+	}
+	var got testPayload
+	if err := e.DataAs(&got); err != nil || got.Message != "bin" {
+		t.Errorf("DataAs = %+v, %v", got, err)
+	}
+}
+
+func TestCloudEventBinaryPercentEncoding(t *testing.T) {
+	server, last := captureEvents(t)
+	template := Event{Source: "/orders", Type: "order.created", Subject: "Crème brûlée 100% \"fresh\"",
+		Extensions: map[string]interface{}{"city": "Zürich"}}
+	if err := SendEvent(context.Background(), server.Client(), server.URL, template, ModeBinary); err != nil {
+		t.Fatal(err)
+	}
+	e, r := last()
+	if got, want := r.Header.Get("Ce-Subject"), "Cr%C3%A8me%20br%C3%BBl%C3%A9e%20100%25%20%22fresh%22"; got != want {
+		t.Errorf("ce-subject = %q, want %q", got, want)
+	}
+	if e.Subject != template.Subject || e.Extensions["city"] != "Zürich" {
+		t.Errorf("decoded subject %q, city %v", e.Subject, e.Extensions["city"])
+	}
+}
+
+func TestSendWithContextEvent(t *testing.T) {
+	server, last := captureEvents(t)
+	e := Event{SpecVersion: "1.0", ID: "1", Source: "s", Type: "t", Data: []byte(`{"id":3}`)}
+	if err := SendWithContext(context.Background(), server.Client(), server.URL, nil, e); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := last()
+	if got.ID != "1" || string(got.Data) != `{"id":3}` {
+		t.Errorf("event = %+v", got)
+	}
+}
+
+func TestSendEventNonJSONData(t *testing.T) {
+	server, last := captureEvents(t)
+	for _, mode := range []ContentMode{ModeStructured, ModeBinary} {
+		e := Event{Source: "s", Type: "t", DataContentType: "text/plain", Data: []byte("hello")}
+		if err := SendEvent(context.Background(), server.Client(), server.URL, e, mode); err != nil {
+			t.Fatal(err)
+		}
+		got, _ := last()
+		if string(got.Data) != "hello" || got.DataContentType != "text/plain" {
+			t.Errorf("mode %d: data = %q as %q", mode, got.Data, got.DataContentType)
+		}
+		if err := got.DataAs(new(string)); err == nil {
+			t.Errorf("mode %d: DataAs on text/plain should fail", mode)
+		}
+	}
+}
+
+func TestSendEventKeepsCallerOptions(t *testing.T) {
+	server, _ := captureEvents(t)
+	opts := make([]Option, 1, 3)
+	opts[0] = WithHeaders(map[string]string{"X-A": "1"})
+	spare := opts[:3]
+	sentinel := Option(func(*request) {})
+	spare[1], spare[2] = sentinel, sentinel
+	e := Event{Source: "s", Type: "t", Data: []byte(`{}`)}
+	if err := SendEvent(context.Background(), server.Client(), server.URL, e, ModeBinary, opts...); err != nil {
+		t.Fatal(err)
+	}
+	// Funcs cannot be compared, but their code pointers can.
+	if fmt.Sprintf("%p", spare[1]) != fmt.Sprintf("%p", sentinel) || fmt.Sprintf("%p", spare[2]) != fmt.Sprintf("%p", sentinel) {
+		t.Error("SendEvent wrote into the spare capacity of the caller's options")
+	}
+}
+
+func TestEventJSONRoundTrip(t *testing.T) {
+	in := Event{SpecVersion: "1.0", ID: "a", Source: "s", Type: "t",
+		DataContentType: "application/octet-stream", Data: []byte{0, 1, 2}}
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"data_base64":"AAEC"`) {
+		t.Errorf("binary data not base64 encoded: %s", b)
+	}
+	var out Event
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	if string(out.Data) != string(in.Data) || out.Extensions != nil {
+		t.Errorf("round trip = %+v", out)
+	}
+}
+
+func TestEventMarshalEmptyData(t *testing.T) {
+	e := Event{SpecVersion: "1.0", ID: "a", Source: "s", Type: "t", Data: []byte{}}
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("Marshal with empty data: %v", err)
+	}
+	if strings.Contains(string(b), `"data`) {
+		t.Errorf("empty data written: %s", b)
+	}
+}
+
+func TestParseEventRejects(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	r.Header.Set("Content-Type", "application/json")
+	if _, err := ParseEvent(r); !errors.Is(err, ErrNotCloudEvent) {
+		t.Errorf("plain JSON: err = %v, want ErrNotCloudEvent", err)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"specversion":"1.0","id":"1"}`))
+	r.Header.Set("Content-Type", "application/cloudevents+json; charset=utf-8")
+	if _, err := ParseEvent(r); err == nil || !strings.Contains(err.Error(), "source, type") {
+		t.Errorf("missing attributes: err = %v", err)
+	}
+
+	if err := Send(context.Background(), http.DefaultClient, "http://127.0.0.1:0", testPayload{},
+		WithCloudEvent(Event{Source: "s"}, ModeStructured)); err == nil || !strings.Contains(err.Error(), "type") {
+		t.Errorf("send without type: err = %v", err)
+	}
+}
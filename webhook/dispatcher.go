@@ -0,0 +1,194 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/heatxsink/x/exp/http/clients"
+	"github.com/heatxsink/x/exp/http/throttled"
+)
+
+// Endpoint describes one destination a Dispatcher delivers to.
+type Endpoint struct {
+	Name    string
+	URL     string
+	Headers map[string]string
+	// Timeout bounds each delivery, including time spent waiting on the
+	// rate limiter. Zero means 10 seconds.
+	Timeout time.Duration
+	// RateEvery and RateBurst configure an exp/http/throttled transport:
+	// one request per RateEvery, with bursts of up to RateBurst. A zero
+	// RateEvery disables limiting.
+	RateEvery time.Duration
+	RateBurst int
+	// FailureThreshold consecutive failures open the endpoint's circuit
+	// breaker for Cooldown. Zero disables the breaker.
+	FailureThreshold int
+	Cooldown         time.Duration
+	// Options apply to every delivery, e.g. WithSigner or WithMsgPack.
+	Options []Option
+}
+
+// Result is the outcome of delivering one event to one endpoint.
+type Result struct {
+	Endpoint string
+	Err      error
+	Duration time.Duration
+}
+
+// Results is the per-endpoint outcome of a Dispatch, in registration
+// order.
+type Results []Result
+
+// Err joins the errors of every failed endpoint, each prefixed with the
+// endpoint name, or returns nil when all succeeded.
+func (rs Results) Err() error {
+	var errs []error
+	for _, r := range rs {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.Endpoint, r.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type endpoint struct {
+	Endpoint
+	client  *http.Client
+	breaker *CircuitBreaker
+}
+
+// Dispatcher fans one event out to many named endpoints concurrently,
+// each with its own headers, timeout, rate limit and circuit breaker.
+// The zero value is ready to use and sends with http.DefaultTransport.
+type Dispatcher struct {
+	base      http.RoundTripper
+	mu        sync.RWMutex
+	endpoints []*endpoint
+}
+
+// NewDispatcher returns a Dispatcher with the given endpoints registered.
+// It panics on a duplicate or empty name, which is a programmer error.
+func NewDispatcher(endpoints ...Endpoint) *Dispatcher {
+	d := &Dispatcher{base: clients.Default().Transport}
+	for _, e := range endpoints {
+		if err := d.Add(e); err != nil {
+			panic(err)
+		}
+	}
+	return d
+}
+
+// Add registers e. Names must be unique and non-empty.
+func (d *Dispatcher) Add(e Endpoint) error {
+	if e.Name == "" {
+		return errors.New("webhook: endpoint name is empty")
+	}
+	timeout := e.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	transport := d.base
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if e.RateEvery > 0 {
+		burst := e.RateBurst
+		if burst < 1 {
+			burst = 1
+		}
+		transport = throttled.NewThrottledTransport(e.RateEvery, burst, transport)
+	}
+	ep := &endpoint{
+		Endpoint: e,
+		client:   &http.Client{Transport: transport, Timeout: timeout},
+		breaker:  NewCircuitBreaker(e.FailureThreshold, e.Cooldown),
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, existing := range d.endpoints {
+		if existing.Name == e.Name {
+			return fmt.Errorf("webhook: endpoint %q already registered", e.Name)
+		}
+	}
+	d.endpoints = append(d.endpoints, ep)
+	return nil
+}
+
+// Remove unregisters the named endpoint. It is a no-op for unknown names.
+func (d *Dispatcher) Remove(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, ep := range d.endpoints {
+		if ep.Name == name {
+			d.endpoints = append(d.endpoints[:i], d.endpoints[i+1:]...)
+			return
+		}
+	}
+}
+
+// State reports the circuit breaker state of the named endpoint.
+func (d *Dispatcher) State(name string) (BreakerState, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, ep := range d.endpoints {
+		if ep.Name == name {
+			return ep.breaker.State(), true
+		}
+	}
+	return BreakerClosed, false
+}
+
+// Dispatch delivers event to every endpoint concurrently and waits for
+// all of them. Endpoints whose breaker is open fail fast with
+// ErrCircuitOpen. Transport errors and retryable statuses (429, 5xx)
+// count towards opening a breaker; other 4xx responses and caller
+// cancellation do not.
+func (d *Dispatcher) Dispatch(ctx context.Context, event interface{}) Results {
+	d.mu.RLock()
+	endpoints := make([]*endpoint, len(d.endpoints))
+	copy(endpoints, d.endpoints)
+	d.mu.RUnlock()
+
+	results := make(Results, len(endpoints))
+	var wg sync.WaitGroup
+	for i, ep := range endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := ep.deliver(ctx, event)
+			results[i] = Result{Endpoint: ep.Name, Err: err, Duration: time.Since(start)}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func (ep *endpoint) deliver(ctx context.Context, event interface{}) error {
+	if err := ep.breaker.Allow(); err != nil {
+		return err
+	}
+	opts := make([]Option, 0, len(ep.Options)+1)
+	opts = append(opts, WithHeaders(ep.Headers))
+	opts = append(opts, ep.Options...)
+	err := Send(ctx, ep.client, ep.URL, event, opts...)
+	var herr *HTTPError
+	switch {
+	case err == nil:
+		ep.breaker.Success()
+	case ctx.Err() != nil:
+		// The caller gave up; that says nothing about the endpoint. Release
+		// a half-open probe slot without changing state.
+		ep.breaker.release()
+	case errors.As(err, &herr) && !herr.Retryable():
+		ep.breaker.Success()
+	default:
+		ep.breaker.Failure()
+	}
+	return err
+}
@@ -0,0 +1,176 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := NewCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Allow #%d: %v", i, err)
+		}
+		b.Failure()
+	}
+	if b.State() != BreakerOpen {
+		t.Fatalf("state = %v, want open", b.State())
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Allow while open = %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if b.State() != BreakerHalfOpen {
+		t.Fatalf("state = %v, want half-open", b.State())
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("second concurrent probe = %v, want ErrCircuitOpen", err)
+	}
+	b.Failure()
+	if b.State() != BreakerOpen {
+		t.Fatalf("failed probe: state = %v, want open", b.State())
+	}
+
+	now = now.Add(time.Minute)
+	_ = b.Allow()
+	b.Success()
+	if b.State() != BreakerClosed {
+		t.Errorf("successful probe: state = %v, want closed", b.State())
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	b := NewCircuitBreaker(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatal(err)
+		}
+		b.Failure()
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	var okHeader atomic.Value
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		okHeader.Store(r.Header.Get("X-Token"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ok.Close()
+	var failHits atomic.Int32
+	fail := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		failHits.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer fail.Close()
+
+	d := NewDispatcher(
+		Endpoint{Name: "ok", URL: ok.URL, Headers: map[string]string{"X-Token": "t"}},
+		Endpoint{Name: "fail", URL: fail.URL, FailureThreshold: 2, Cooldown: time.Hour},
+	)
+	ctx := context.Background()
+
+	results := d.Dispatch(ctx, testPayload{Message: "hi"})
+	if len(results) != 2 || results[0].Endpoint != "ok" || results[1].Endpoint != "fail" {
+		t.Fatalf("results = %+v", results)
+	}
+	if results[0].Err != nil {
+		t.Errorf("ok endpoint: %v", results[0].Err)
+	}
+	var herr *HTTPError
+	if !errors.As(results[1].Err, &herr) || herr.StatusCode != 500 {
+		t.Errorf("fail endpoint: %v", results[1].Err)
+	}
+	if okHeader.Load() != "t" {
+		t.Errorf("X-Token = %v", okHeader.Load())
+	}
+	if err := results.Err(); err == nil {
+		t.Error("Results.Err() = nil, want failure")
+	}
+
+	_ = d.Dispatch(ctx, testPayload{})
+	if s, _ := d.State("fail"); s != BreakerOpen {
+		t.Fatalf("breaker = %v, want open", s)
+	}
+	results = d.Dispatch(ctx, testPayload{})
+	if !errors.Is(results[1].Err, ErrCircuitOpen) {
+		t.Errorf("open breaker err = %v", results[1].Err)
+	}
+	if failHits.Load() != 2 {
+		t.Errorf("fail hits = %d, want 2", failHits.Load())
+	}
+
+	d.Remove("fail")
+	if results := d.Dispatch(ctx, testPayload{}); len(results) != 1 || results.Err() != nil {
+		t.Errorf("after Remove: %+v", results)
+	}
+}
+
+func TestDispatcherClientErrorsDoNotTrip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	d := NewDispatcher(Endpoint{Name: "a", URL: server.URL, FailureThreshold: 1, Cooldown: time.Hour})
+	for i := 0; i < 3; i++ {
+		_ = d.Dispatch(context.Background(), testPayload{})
+	}
+	if s, _ := d.State("a"); s != BreakerClosed {
+		t.Errorf("breaker = %v after 4xx, want closed", s)
+	}
+}
+
+func TestDispatcherRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	d := NewDispatcher(Endpoint{Name: "slow", URL: server.URL, RateEvery: 100 * time.Millisecond, RateBurst: 1})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := d.Dispatch(context.Background(), testPayload{}).Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("3 rate-limited sends took %v, want >= 200ms", elapsed)
+	}
+}
+
+func TestDispatcherZeroValue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var d Dispatcher
+	if err := d.Add(Endpoint{Name: "a", URL: server.URL, RateEvery: time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Dispatch(context.Background(), testPayload{}).Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDispatcherAddValidates(t *testing.T) {
+	d := NewDispatcher(Endpoint{Name: "a", URL: "http://127.0.0.1:0"})
+	if err := d.Add(Endpoint{Name: "a"}); err == nil {
+		t.Error("duplicate name accepted")
+	}
+	if err := d.Add(Endpoint{}); err == nil {
+		t.Error("empty name accepted")
+	}
+}
@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HTTPError is returned for any non-2xx response. Use errors.As to get at
+// the status, body and headers:
+//
+//	var herr *webhook.HTTPError
+//	if errors.As(err, &herr) && herr.StatusCode == http.StatusConflict { ... }
+type HTTPError struct {
+	StatusCode int
+	Body       []byte
+	Header     http.Header
+	// RetryAfter is the delay requested by a Retry-After header, or zero
+	// when the header is absent, malformed or already in the past.
+	RetryAfter time.Duration
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP status code: %d HTTP body: %s", e.StatusCode, string(e.Body))
+}
+
+// Retryable reports whether the status indicates a transient condition:
+// 429 Too Many Requests or any 5xx. Other 4xx are the sender's fault and
+// will fail the same way again.
+func (e *HTTPError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+func statusError(statusCode int, header http.Header, body []byte) error {
+	if statusCode >= 200 && statusCode < 300 {
+		return nil
+	}
+	return &HTTPError{
+		StatusCode: statusCode,
+		Body:       body,
+		Header:     header,
+		RetryAfter: parseRetryAfter(header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// parseRetryAfter accepts either delay-seconds or an HTTP-date and returns
+// zero when the header is absent, malformed or already in the past.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,451 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// marshalMsgPack is a small MessagePack encoder covering the shapes
+// webhook payloads use: scalars, strings, byte slices, slices, maps,
+// structs and time.Time (as the -1 timestamp extension). Structs follow
+// encoding/json: fields of embedded structs are promoted, and values
+// implementing json.Marshaler, such as json.RawMessage, are written as
+// the JSON they produce. Map keys are sorted so the output is
+// deterministic, which keeps signatures stable.
+func marshalMsgPack(data interface{}) ([]byte, error) {
+	var e msgpackEncoder
+	if err := e.encode(reflect.ValueOf(data)); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+type msgpackEncoder struct {
+	buf   []byte
+	depth int
+}
+
+// maxMsgPackDepth bounds nesting, so a cyclic value fails instead of
+// overflowing the stack.
+const maxMsgPackDepth = 1000
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func (e *msgpackEncoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.buf = append(e.buf, 0xc0)
+		return nil
+	}
+	if e.depth++; e.depth > maxMsgPackDepth {
+		return fmt.Errorf("webhook: msgpack: %s nested too deeply, is it cyclic?", v.Type())
+	}
+	defer func() { e.depth-- }()
+	if v.Type() == timeType {
+		t, _ := v.Interface().(time.Time)
+		e.encodeTime(t)
+		return nil
+	}
+	if v.Kind() != reflect.Pointer && v.Kind() != reflect.Interface && v.Type().Implements(jsonMarshalerType) {
+		return e.encodeJSON(v)
+	}
+	if v.Kind() != reflect.Pointer && v.Kind() != reflect.Interface && v.Type().Implements(textMarshalerType) {
+		tm, _ := v.Interface().(encoding.TextMarshaler)
+		text, err := tm.MarshalText()
+		if err != nil {
+			return err
+		}
+		e.encodeString(string(text))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		return e.encode(v.Elem())
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf = append(e.buf, 0xc3)
+		} else {
+			e.buf = append(e.buf, 0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.encodeInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.encodeUint(v.Uint())
+	case reflect.Float32:
+		e.buf = append(e.buf, 0xca)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		e.buf = append(e.buf, 0xcb)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, math.Float64bits(v.Float()))
+	case reflect.String:
+		e.encodeString(v.String())
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if v.Kind() == reflect.Slice && v.IsNil() {
+				e.buf = append(e.buf, 0xc0)
+				return nil
+			}
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			e.encodeBinary(b)
+			return nil
+		}
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		e.encodeHeader(v.Len(), 0x90, 0xdc, 0xdd)
+		for i := range v.Len() {
+			if err := e.encode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keyLess(keys[i], keys[j]) })
+		e.encodeHeader(len(keys), 0x80, 0xde, 0xdf)
+		for _, k := range keys {
+			if err := e.encode(k); err != nil {
+				return err
+			}
+			if err := e.encode(v.MapIndex(k)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		return e.encodeStruct(v)
+	default:
+		return fmt.Errorf("webhook: msgpack: unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// keyLess orders map keys: by value within a kind, by kind otherwise.
+func keyLess(a, b reflect.Value) bool {
+	for a.Kind() == reflect.Interface && !a.IsNil() {
+		a = a.Elem()
+	}
+	for b.Kind() == reflect.Interface && !b.IsNil() {
+		b = b.Elem()
+	}
+	if a.Kind() != b.Kind() {
+		return a.Kind() < b.Kind()
+	}
+	switch a.Kind() {
+	case reflect.String:
+		return a.String() < b.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	case reflect.Interface:
+		return false
+	default:
+		return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+	}
+}
+
+// encodeJSON writes the output of a json.Marshaler as the MessagePack
+// form of the same value.
+func (e *msgpackEncoder) encodeJSON(v reflect.Value) error {
+	m, _ := v.Interface().(json.Marshaler)
+	b, err := m.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var x interface{}
+	if err := dec.Decode(&x); err != nil {
+		return fmt.Errorf("webhook: msgpack: %s: %w", v.Type(), err)
+	}
+	return e.encodeDecoded(x)
+}
+
+// encodeDecoded writes a value decoded from JSON with UseNumber.
+func (e *msgpackEncoder) encodeDecoded(x interface{}) error {
+	switch x := x.(type) {
+	case json.Number:
+		if n, err := x.Int64(); err == nil {
+			e.encodeInt(n)
+			return nil
+		}
+		f, err := x.Float64()
+		if err != nil {
+			return fmt.Errorf("webhook: msgpack: number %s: %w", x, err)
+		}
+		return e.encode(reflect.ValueOf(f))
+	case []interface{}:
+		e.encodeHeader(len(x), 0x90, 0xdc, 0xdd)
+		for _, elem := range x {
+			if err := e.encodeDecoded(elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		e.encodeHeader(len(keys), 0x80, 0xde, 0xdf)
+		for _, k := range keys {
+			e.encodeString(k)
+			if err := e.encodeDecoded(x[k]); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		// nil, bool and string.
+		return e.encode(reflect.ValueOf(x))
+	}
+}
+
+type msgpackField struct {
+	name      string
+	index     []int
+	depth     int
+	tagged    bool
+	omitEmpty bool
+}
+
+func (e *msgpackEncoder) encodeStruct(v reflect.Value) error {
+	type entry struct {
+		name  string
+		value reflect.Value
+	}
+	var fields []entry
+	for _, f := range msgpackFields(v.Type()) {
+		fv, err := v.FieldByIndexErr(f.index)
+		if err != nil {
+			// Behind a nil embedded pointer.
+			continue
+		}
+		if f.omitEmpty && fv.IsZero() {
+			continue
+		}
+		fields = append(fields, entry{f.name, fv})
+	}
+	e.encodeHeader(len(fields), 0x80, 0xde, 0xdf)
+	for _, f := range fields {
+		e.encodeString(f.name)
+		if err := e.encode(f.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// msgpackFields returns the fields of struct type t in encoding/json
+// order, with those of untagged embedded structs promoted. Of several
+// fields with one name, the shallowest wins, then the only tagged one;
+// otherwise all are dropped.
+func msgpackFields(t reflect.Type) []msgpackField {
+	var all []msgpackField
+	collectMsgPackFields(t, nil, 0, map[reflect.Type]bool{t: true}, &all)
+	byName := map[string][]msgpackField{}
+	for _, f := range all {
+		byName[f.name] = append(byName[f.name], f)
+	}
+	out := make([]msgpackField, 0, len(all))
+	for _, f := range all {
+		if d, ok := dominantField(byName[f.name]); ok && slices.Equal(d.index, f.index) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func dominantField(fields []msgpackField) (msgpackField, bool) {
+	depth := fields[0].depth
+	for _, f := range fields {
+		depth = min(depth, f.depth)
+	}
+	var shallow, tagged []msgpackField
+	for _, f := range fields {
+		if f.depth != depth {
+			continue
+		}
+		shallow = append(shallow, f)
+		if f.tagged {
+			tagged = append(tagged, f)
+		}
+	}
+	switch {
+	case len(shallow) == 1:
+		return shallow[0], true
+	case len(tagged) == 1:
+		return tagged[0], true
+	default:
+		return msgpackField{}, false
+	}
+}
+
+// collectMsgPackFields appends the fields of t, whose index path is
+// index, to out. visiting holds the embedded types on the path, so a
+// struct embedding a pointer to itself terminates.
+func collectMsgPackFields(t reflect.Type, index []int, depth int, visiting map[reflect.Type]bool, out *[]msgpackField) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, omitEmpty, skip := msgpackFieldName(f)
+		if skip {
+			continue
+		}
+		path := append(slices.Clip(index), i)
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if !visiting[ft] {
+					visiting[ft] = true
+					collectMsgPackFields(ft, path, depth+1, visiting, out)
+					delete(visiting, ft)
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		field := msgpackField{name: name, index: path, depth: depth, tagged: name != "", omitEmpty: omitEmpty}
+		if !field.tagged {
+			field.name = f.Name
+		}
+		*out = append(*out, field)
+	}
+}
+
+// msgpackFieldName returns the name given by the msgpack tag, or else the
+// json tag, which is empty for an untagged field, and whether the field
+// is omitted when empty or always skipped.
+func msgpackFieldName(f reflect.StructField) (string, bool, bool) {
+	tag, ok := f.Tag.Lookup("msgpack")
+	if !ok {
+		tag = f.Tag.Get("json")
+	}
+	if tag == "-" {
+		return "", false, true
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	return name, strings.Contains(opts, "omitempty"), false
+}
+func (e *msgpackEncoder) encodeInt(n int64) {
+	switch {
+	case n >= 0:
+		e.encodeUint(uint64(n))
+	case n >= -32:
+		e.buf = append(e.buf, byte(int8(n)))
+	case n >= math.MinInt8:
+		e.buf = append(e.buf, 0xd0, byte(int8(n)))
+	case n >= math.MinInt16:
+		e.buf = append(e.buf, 0xd1)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(int16(n)))
+	case n >= math.MinInt32:
+		e.buf = append(e.buf, 0xd2)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(int32(n)))
+	default:
+		e.buf = append(e.buf, 0xd3)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(n))
+	}
+}
+
+func (e *msgpackEncoder) encodeUint(n uint64) {
+	switch {
+	case n <= 0x7f:
+		e.buf = append(e.buf, byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xcc, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xcd)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	case n <= math.MaxUint32:
+		e.buf = append(e.buf, 0xce)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	default:
+		e.buf = append(e.buf, 0xcf)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, n)
+	}
+}
+
+func (e *msgpackEncoder) encodeString(s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		e.buf = append(e.buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xda)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xdb)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n)) // #nosec G115 -- payloads over 4 GiB are not webhooks
+	}
+	e.buf = append(e.buf, s...)
+}
+
+func (e *msgpackEncoder) encodeBinary(b []byte) {
+	n := len(b)
+	switch {
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xc5)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xc6)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n)) // #nosec G115 -- payloads over 4 GiB are not webhooks
+	}
+	e.buf = append(e.buf, b...)
+}
+
+// encodeHeader writes an array or map length using the fix, 16-bit or
+// 32-bit form.
+func (e *msgpackEncoder) encodeHeader(n int, fix, b16, b32 byte) {
+	switch {
+	case n < 16:
+		e.buf = append(e.buf, fix|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, b16)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, b32)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n)) // #nosec G115 -- bounded by payload size
+	}
+}
+
+// encodeTime writes the timestamp extension in its 96-bit form, which
+// covers every time.Time without range checks.
+func (e *msgpackEncoder) encodeTime(t time.Time) {
+	e.buf = append(e.buf, 0xc7, 12, 0xff)
+	e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(t.Nanosecond())) // #nosec G115 -- always < 1e9
+	e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(t.Unix()))
+}
@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarshalMsgPack(t *testing.T) {
+	tests := []struct {
+		name string
+		in   interface{}
+		want []byte
+	}{
+		{"nil", nil, []byte{0xc0}},
+		{"true", true, []byte{0xc3}},
+		{"fixint", 5, []byte{0x05}},
+		{"negative fixint", -3, []byte{0xfd}},
+		{"int8", -100, []byte{0xd0, 0x9c}},
+		{"uint8", 200, []byte{0xcc, 0xc8}},
+		{"uint16", 1000, []byte{0xcd, 0x03, 0xe8}},
+		{"int32", -70000, []byte{0xd2, 0xff, 0xfe, 0xee, 0x90}},
+		{"float64", 1.5, []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{"fixstr", "hi", []byte{0xa2, 'h', 'i'}},
+		{"bin", []byte{1, 2}, []byte{0xc4, 0x02, 0x01, 0x02}},
+		{"array", []int{1, 2}, []byte{0x92, 0x01, 0x02}},
+		{"sorted map", map[string]int{"b": 2, "a": 1}, []byte{0x82, 0xa1, 'a', 0x01, 0xa1, 'b', 0x02}},
+		{"sorted int map", map[int]bool{3: true, -1: false, 2: true}, []byte{0x83, 0xff, 0xc2, 0x02, 0xc3, 0x03, 0xc3}},
+		{"raw json", json.RawMessage(`{"b":[1,2.5],"a":null}`), []byte{
+			0x82, 0xa1, 'a', 0xc0, 0xa1, 'b', 0x92, 0x01, 0xcb, 0x40, 0x04, 0, 0, 0, 0, 0, 0}},
+		{"timestamp", time.Unix(1, 2), []byte{0xc7, 12, 0xff, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 1}},
+	}
+	for _, tt := range tests {
+		got, err := marshalMsgPack(tt.in)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("%s: got % x, want % x", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMarshalMsgPackStructTags(t *testing.T) {
+	type payload struct {
+		A      string `msgpack:"alpha"`
+		B      int    `json:"beta"`
+		C      string `json:"gamma,omitempty"`
+		Hidden string `json:"-"`
+		hidden string
+		Plain  bool
+	}
+	got, err := marshalMsgPack(payload{A: "x", B: 1, hidden: "y"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0x83, 0xa5, 'a', 'l', 'p', 'h', 'a', 0xa1, 'x', 0xa4, 'b', 'e', 't', 'a', 0x01, 0xa5, 'P', 'l', 'a', 'i', 'n', 0xc2}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+}
+
+func TestMarshalMsgPackEmbedded(t *testing.T) {
+	type Base struct {
+		ID   int
+		Kind string
+	}
+	type meta struct{ Source string }
+	type event struct {
+		Base
+		*meta
+		Kind string // shadows Base.Kind by depth
+	}
+	got, err := marshalMsgPack(event{Base: Base{ID: 1, Kind: "inner"}, meta: &meta{Source: "s"}, Kind: "outer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Fields keep struct order: ID, Source, Kind.
+	want := []byte{0x83, 0xa2, 'I', 'D', 0x01, 0xa6, 'S', 'o', 'u', 'r', 'c', 'e', 0xa1, 's', 0xa4, 'K', 'i', 'n', 'd', 0xa5, 'o', 'u', 't', 'e', 'r'}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+	// A nil embedded pointer contributes no fields.
+	if got, _ := marshalMsgPack(event{}); got[0] != 0x82 {
+		t.Errorf("with nil embedded pointer: % x", got)
+	}
+}
+
+func TestMarshalMsgPackCycle(t *testing.T) {
+	type node struct{ Next *node }
+	n := &node{}
+	n.Next = n
+	if _, err := marshalMsgPack(n); err == nil {
+		t.Error("expected error for a cyclic value")
+	}
+}
+
+func TestMarshalMsgPackLongString(t *testing.T) {
+	s := strings.Repeat("a", 300)
+	got, err := marshalMsgPack(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0] != 0xda || got[1] != 0x01 || got[2] != 0x2c || len(got) != 303 {
+		t.Errorf("str16 header = % x, len %d", got[:3], len(got))
+	}
+}
+
+func TestMarshalMsgPackUnsupported(t *testing.T) {
+	if _, err := marshalMsgPack(make(chan int)); err == nil {
+		t.Error("expected error for channel")
+	}
+}
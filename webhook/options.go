@@ -0,0 +1,214 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	contentTypeJSON    = "application/json"
+	contentTypeForm    = "application/x-www-form-urlencoded"
+	contentTypeMsgPack = "application/msgpack"
+
+	HeaderIdempotencyKey = "Idempotency-Key"
+)
+
+// Option configures a single Send or SendAndDecode call.
+type Option func(*request)
+
+type request struct {
+	method      string
+	header      http.Header
+	contentType string
+	encode      func(data interface{}) ([]byte, error)
+	signer      *Signer
+	event       *Event
+	eventMode   ContentMode
+}
+
+func newRequest(opts []Option) *request {
+	req := &request{
+		method:      http.MethodPost,
+		header:      http.Header{},
+		contentType: contentTypeJSON,
+		encode:      json.Marshal,
+	}
+	for _, opt := range opts {
+		opt(req)
+	}
+	return req
+}
+
+// WithMethod overrides the HTTP method. Defaults to POST.
+func WithMethod(method string) Option {
+	return func(r *request) { r.method = method }
+}
+
+// WithHeader sets a request header, replacing any previous value.
+func WithHeader(key, value string) Option {
+	return func(r *request) { r.header.Set(key, value) }
+}
+
+// WithHeaders sets every entry of headers on the request.
+func WithHeaders(headers map[string]string) Option {
+	return func(r *request) {
+		for k, v := range headers {
+			r.header.Set(k, v)
+		}
+	}
+}
+
+// WithIdempotencyKey sets the Idempotency-Key header so a receiver can
+// discard duplicates when a retry races a response that was lost in
+// transit.
+func WithIdempotencyKey(key string) Option {
+	return WithHeader(HeaderIdempotencyKey, key)
+}
+
+// WithSigner signs the encoded body with s.
+func WithSigner(s *Signer) Option {
+	return func(r *request) { r.signer = s }
+}
+
+// WithForm sends data as application/x-www-form-urlencoded. data must be
+// a url.Values, map[string]string or map[string][]string.
+func WithForm() Option {
+	return func(r *request) {
+		r.contentType = contentTypeForm
+		r.encode = encodeForm
+	}
+}
+
+// WithMsgPack sends data encoded as MessagePack. Struct fields are named
+// by their msgpack tag, falling back to the json tag and then the Go
+// field name, and embedded structs are flattened as encoding/json does.
+func WithMsgPack() Option {
+	return func(r *request) {
+		r.contentType = contentTypeMsgPack
+		r.encode = marshalMsgPack
+	}
+}
+
+// WithRaw sends data unencoded with the given content type. data must be
+// a []byte, string or io.Reader.
+func WithRaw(contentType string) Option {
+	return func(r *request) {
+		r.contentType = contentType
+		r.encode = encodeRaw
+	}
+}
+
+// Send encodes data (JSON unless an option says otherwise), sends it to
+// url and discards the response body. Any 2xx status is success; anything
+// else is returned as an *HTTPError.
+func Send(ctx context.Context, client *http.Client, url string, data interface{}, opts ...Option) error {
+	_, err := send(ctx, client, url, data, opts)
+	return err
+}
+
+// SendAndDecode is Send followed by decoding a successful response body
+// into out. out may be a *[]byte or *string to receive the raw body;
+// anything else is decoded as JSON. An empty body leaves out untouched.
+func SendAndDecode(ctx context.Context, client *http.Client, url string, data interface{}, out interface{}, opts ...Option) error {
+	content, err := send(ctx, client, url, data, opts)
+	if err != nil {
+		return err
+	}
+	switch v := out.(type) {
+	case *[]byte:
+		*v = content
+		return nil
+	case *string:
+		*v = string(content)
+		return nil
+	}
+	if len(bytes.TrimSpace(content)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(content, out); err != nil {
+		return fmt.Errorf("webhook: decode response: %w", err)
+	}
+	return nil
+}
+
+func send(ctx context.Context, client *http.Client, url string, data interface{}, opts []Option) ([]byte, error) {
+	req := newRequest(opts)
+	b, err := req.encode(data)
+	if err != nil {
+		return nil, err
+	}
+	switch data.(type) {
+	case Event, *Event:
+		// An Event passed as data is already a structured mode envelope.
+		if req.event == nil && req.contentType == contentTypeJSON {
+			req.contentType = contentTypeCloudEvents
+		}
+	}
+	if req.event != nil {
+		if b, err = req.wrapEvent(b, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	request, err := http.NewRequestWithContext(ctx, req.method, url, bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new request: %w", err)
+	}
+	request.Header.Set("Content-Type", req.contentType)
+	request.Header.Set("Accept", contentTypeJSON)
+	for k, v := range req.header {
+		request.Header[k] = v
+	}
+	if req.signer != nil {
+		name, value := req.signer.Sign(b)
+		request.Header.Set(name, value)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := statusError(response.StatusCode, response.Header, content); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+func encodeForm(data interface{}) ([]byte, error) {
+	switch v := data.(type) {
+	case url.Values:
+		return []byte(v.Encode()), nil
+	case map[string][]string:
+		return []byte(url.Values(v).Encode()), nil
+	case map[string]string:
+		values := make(url.Values, len(v))
+		for k, s := range v {
+			values.Set(k, s)
+		}
+		return []byte(values.Encode()), nil
+	default:
+		return nil, fmt.Errorf("webhook: form body must be url.Values or a string map, got %T", data)
+	}
+}
+
+func encodeRaw(data interface{}) ([]byte, error) {
+	switch v := data.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	case io.Reader:
+		return io.ReadAll(v)
+	default:
+		return nil, fmt.Errorf("webhook: raw body must be []byte, string or io.Reader, got %T", data)
+	}
+}
@@ -0,0 +1,159 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestSendAcceptsAny2xx(t *testing.T) {
+	for _, code := range []int{http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(code)
+		}))
+		err := SendWithContext(context.Background(), server.Client(), server.URL, nil, testPayload{})
+		server.Close()
+		if err != nil {
+			t.Errorf("status %d: unexpected error %v", code, err)
+		}
+	}
+}
+
+func TestSendReturnsHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.Header().Set("X-Request-Id", "abc")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte("slow down"))
+	}))
+	defer server.Close()
+
+	err := Send(context.Background(), server.Client(), server.URL, testPayload{})
+	var herr *HTTPError
+	if !errors.As(err, &herr) {
+		t.Fatalf("err = %T %v, want *HTTPError", err, err)
+	}
+	if herr.StatusCode != 429 || string(herr.Body) != "slow down" {
+		t.Errorf("HTTPError = %+v", herr)
+	}
+	if herr.RetryAfter != 7*time.Second {
+		t.Errorf("RetryAfter = %v, want 7s", herr.RetryAfter)
+	}
+	if herr.Header.Get("X-Request-Id") != "abc" {
+		t.Errorf("Header not preserved: %v", herr.Header)
+	}
+	if !herr.Retryable() {
+		t.Error("429 should be retryable")
+	}
+	if (&HTTPError{StatusCode: 404}).Retryable() {
+		t.Error("404 should not be retryable")
+	}
+}
+
+func TestSendJSONReturnsHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer server.Close()
+
+	var herr *HTTPError
+	if err := SendJSON(server.URL, testPayload{}); !errors.As(err, &herr) || herr.StatusCode != 409 {
+		t.Errorf("err = %v, want *HTTPError 409", err)
+	}
+}
+
+func TestSendOptions(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	err := Send(ctx, server.Client(), server.URL, map[string]string{"a": "1", "b": "two words"},
+		WithForm(), WithMethod(http.MethodPut), WithIdempotencyKey("key-1"), WithHeader("X-Extra", "yes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Method != http.MethodPut {
+		t.Errorf("method = %s", got.Method)
+	}
+	if got.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+		t.Errorf("content type = %s", got.Header.Get("Content-Type"))
+	}
+	if got.Header.Get("Idempotency-Key") != "key-1" || got.Header.Get("X-Extra") != "yes" {
+		t.Errorf("headers = %v", got.Header)
+	}
+	if v, _ := url.ParseQuery(string(body)); v.Get("b") != "two words" {
+		t.Errorf("form body = %q", body)
+	}
+
+	if err := Send(ctx, server.Client(), server.URL, "plain text", WithRaw("text/plain")); err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "plain text" || got.Header.Get("Content-Type") != "text/plain" {
+		t.Errorf("raw: %q as %s", body, got.Header.Get("Content-Type"))
+	}
+
+	if err := Send(ctx, server.Client(), server.URL, map[string]int{"n": 1}, WithMsgPack()); err != nil {
+		t.Fatal(err)
+	}
+	if got.Header.Get("Content-Type") != "application/msgpack" || string(body) != "\x81\xa1n\x01" {
+		t.Errorf("msgpack: %x as %s", body, got.Header.Get("Content-Type"))
+	}
+}
+
+func TestSendRejectsUnencodableBodies(t *testing.T) {
+	ctx := context.Background()
+	if err := Send(ctx, http.DefaultClient, "http://127.0.0.1:0", 42, WithForm()); err == nil {
+		t.Error("form with int body should fail")
+	}
+	if err := Send(ctx, http.DefaultClient, "http://127.0.0.1:0", 42, WithRaw("text/plain")); err == nil {
+		t.Error("raw with int body should fail")
+	}
+}
+
+func TestSendAndDecode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/empty" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"message":"created","id":9}`))
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	var out testPayload
+	if err := SendAndDecode(ctx, server.Client(), server.URL, testPayload{}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Message != "created" || out.ID != 9 {
+		t.Errorf("decoded %+v", out)
+	}
+
+	var raw string
+	if err := SendAndDecode(ctx, server.Client(), server.URL, testPayload{}, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw != `{"message":"created","id":9}` {
+		t.Errorf("raw = %q", raw)
+	}
+
+	untouched := testPayload{ID: 5}
+	if err := SendAndDecode(ctx, server.Client(), server.URL+"/empty", testPayload{}, &untouched); err != nil {
+		t.Fatal(err)
+	}
+	if untouched.ID != 5 {
+		t.Errorf("empty body changed out: %+v", untouched)
+	}
+}
@@ -0,0 +1,354 @@
+package webhook
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/heatxsink/x/exp/storage"
+)
+
+const (
+	pendingDir = "pending"
+	deadDir    = "dead"
+
+	defaultMaxAttempts  = 8
+	defaultBaseDelay    = time.Second
+	defaultMaxDelay     = 10 * time.Minute
+	defaultPollInterval = 5 * time.Second
+)
+
+// Delivery is one outbound webhook as persisted by an Outbox.
+type Delivery struct {
+	ID          string            `json:"id"`
+	URL         string            `json:"url"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Body        []byte            `json:"body"`
+	Attempts    int               `json:"attempts"`
+	CreatedAt   time.Time         `json:"created_at"`
+	NextAttempt time.Time         `json:"next_attempt"`
+	LastStatus  int               `json:"last_status,omitempty"`
+	LastError   string            `json:"last_error,omitempty"`
+}
+
+// OutboxOption configures an Outbox.
+type OutboxOption func(*Outbox)
+
+// WithMaxAttempts sets how many delivery attempts are made before a
+// delivery moves to the dead-letter queue. Defaults to 8.
+func WithMaxAttempts(n int) OutboxOption {
+	return func(o *Outbox) { o.maxAttempts = n }
+}
+
+// WithBackoff sets the first retry delay and the cap that exponential
+// growth stops at. Defaults to 1s and 10m.
+func WithBackoff(base, maxDelay time.Duration) OutboxOption {
+	return func(o *Outbox) {
+		o.baseDelay = base
+		o.maxDelay = maxDelay
+	}
+}
+
+// WithPollInterval sets how often Run looks for due deliveries.
+// Defaults to 5s.
+func WithPollInterval(d time.Duration) OutboxOption {
+	return func(o *Outbox) { o.pollInterval = d }
+}
+
+// WithOutboxSigner signs every attempt with s. The signature is computed
+// at send time, so retries carry a fresh timestamp.
+func WithOutboxSigner(s *Signer) OutboxOption {
+	return func(o *Outbox) { o.signer = s }
+}
+
+// Outbox is a durable webhook queue. Enqueue persists a delivery under
+// rootURI before anything is sent, so pending work survives a restart;
+// Run (or ProcessDue) sends whatever is due.
+//
+// rootURI is any exp/storage URI (file:///var/lib/app/outbox,
+// gs://bucket/outbox, mem://test/outbox). Deliveries live under
+// <root>/pending/ and, once they fail permanently or exhaust their
+// attempts, under <root>/dead/.
+//
+// Responses are classified as: 2xx delivered; 429 and 5xx retryable,
+// honoring Retry-After (see HTTPError.Retryable); any other status
+// permanent. Transport errors are retryable. Retry delays grow
+// exponentially from the base delay, capped at the max, with the upper
+// half jittered.
+type Outbox struct {
+	root         string
+	client       *http.Client
+	signer       *Signer
+	maxAttempts  int
+	baseDelay    time.Duration
+	maxDelay     time.Duration
+	pollInterval time.Duration
+	now          func() time.Time
+	jitter       func() float64
+	mu           sync.Mutex
+	inFlight     map[string]bool // IDs being attempted, guarded by mu
+}
+
+// NewOutbox returns an Outbox persisting under rootURI and sending with
+// client.
+func NewOutbox(rootURI string, client *http.Client, opts ...OutboxOption) *Outbox {
+	o := &Outbox{
+		root:         strings.TrimSuffix(rootURI, "/"),
+		client:       client,
+		maxAttempts:  defaultMaxAttempts,
+		baseDelay:    defaultBaseDelay,
+		maxDelay:     defaultMaxDelay,
+		pollInterval: defaultPollInterval,
+		now:          time.Now,
+		jitter:       rand.Float64,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Enqueue marshals data as JSON, persists it as a pending delivery to url
+// and returns its ID. Nothing is sent until the next ProcessDue.
+func (o *Outbox) Enqueue(ctx context.Context, url string, headers map[string]string, data interface{}) (string, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	now := o.now()
+	d := &Delivery{
+		ID:          newDeliveryID(now),
+		URL:         url,
+		Headers:     headers,
+		Body:        b,
+		CreatedAt:   now,
+		NextAttempt: now,
+	}
+	if err := o.put(ctx, pendingDir, d); err != nil {
+		return "", err
+	}
+	return d.ID, nil
+}
+
+// Run calls ProcessDue every poll interval until ctx is done, then returns
+// ctx.Err(). Errors from individual passes are not fatal; the deliveries
+// they concern stay pending.
+func (o *Outbox) Run(ctx context.Context) error {
+	ticker := time.NewTicker(o.pollInterval)
+	defer ticker.Stop()
+	for {
+		_ = o.ProcessDue(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue attempts every pending delivery whose NextAttempt has passed.
+// The attempts run concurrently, so an endpoint that hangs until the
+// client timeout holds up none of the others; a delivery already being
+// attempted by an earlier, unfinished pass is skipped. It returns storage
+// errors; delivery failures are recorded on the delivery itself.
+func (o *Outbox) ProcessDue(ctx context.Context) error {
+	due, err := o.claimDue(ctx)
+	if err != nil {
+		return err
+	}
+	errs := make([]error, len(due))
+	var wg sync.WaitGroup
+	for i, d := range due {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer o.release(d.ID)
+			if err := o.attempt(ctx, d); err != nil {
+				errs[i] = fmt.Errorf("delivery %s: %w", d.ID, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// claimDue lists the due deliveries and marks them in flight.
+func (o *Outbox) claimDue(ctx context.Context) ([]*Delivery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	pending, err := o.list(ctx, pendingDir)
+	if err != nil {
+		return nil, err
+	}
+	now := o.now()
+	var due []*Delivery
+	for i := range pending {
+		d := &pending[i]
+		if d.NextAttempt.After(now) || o.inFlight[d.ID] {
+			continue
+		}
+		if o.inFlight == nil {
+			o.inFlight = map[string]bool{}
+		}
+		o.inFlight[d.ID] = true
+		due = append(due, d)
+	}
+	return due, nil
+}
+
+func (o *Outbox) release(id string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.inFlight, id)
+}
+
+// Pending returns the deliveries still waiting to be sent, oldest first.
+func (o *Outbox) Pending(ctx context.Context) ([]Delivery, error) {
+	return o.list(ctx, pendingDir)
+}
+
+// DeadLetters returns the deliveries that failed permanently or ran out of
+// attempts, oldest first.
+func (o *Outbox) DeadLetters(ctx context.Context) ([]Delivery, error) {
+	return o.list(ctx, deadDir)
+}
+
+// Replay moves the dead letter id back to the pending queue with its
+// attempt count reset, due immediately.
+func (o *Outbox) Replay(ctx context.Context, id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	d, err := o.get(ctx, deadDir, id)
+	if err != nil {
+		return err
+	}
+	d.Attempts = 0
+	d.NextAttempt = o.now()
+	return o.move(ctx, d, deadDir, pendingDir)
+}
+
+func (o *Outbox) attempt(ctx context.Context, d *Delivery) error {
+	err := o.send(ctx, d)
+	if err == nil {
+		return storage.Delete(ctx, o.uri(pendingDir, d.ID))
+	}
+	if ctx.Err() != nil {
+		// Shutting down mid-request is not the endpoint's fault; leave the
+		// delivery as it was.
+		return ctx.Err()
+	}
+	d.Attempts++
+	d.LastStatus = 0
+	d.LastError = err.Error()
+	retry := true
+	delay := o.backoff(d.Attempts)
+	var herr *HTTPError
+	if errors.As(err, &herr) {
+		d.LastStatus = herr.StatusCode
+		retry = herr.Retryable()
+		if herr.RetryAfter > 0 {
+			delay = herr.RetryAfter
+		}
+	}
+	if !retry || d.Attempts >= o.maxAttempts {
+		return o.move(ctx, d, pendingDir, deadDir)
+	}
+	d.NextAttempt = o.now().Add(delay)
+	return o.put(ctx, pendingDir, d)
+}
+
+// send makes one attempt. Every attempt carries the delivery ID as its
+// Idempotency-Key unless the caller supplied one.
+func (o *Outbox) send(ctx context.Context, d *Delivery) error {
+	headers := make(map[string]string, len(d.Headers)+1)
+	maps.Copy(headers, d.Headers)
+	if _, ok := headers[HeaderIdempotencyKey]; !ok {
+		headers[HeaderIdempotencyKey] = d.ID
+	}
+	opts := []Option{WithRaw(contentTypeJSON), WithHeaders(headers)}
+	if o.signer != nil {
+		opts = append(opts, WithSigner(o.signer))
+	}
+	return Send(ctx, o.client, d.URL, d.Body, opts...)
+}
+
+func (o *Outbox) backoff(attempts int) time.Duration {
+	delay := o.maxDelay
+	if shift := attempts - 1; shift < 32 {
+		if d := o.baseDelay << shift; d > 0 && d < o.maxDelay {
+			delay = d
+		}
+	}
+	half := delay / 2
+	return half + time.Duration(o.jitter()*float64(delay-half))
+}
+
+func (o *Outbox) uri(dir, id string) string {
+	return o.root + "/" + dir + "/" + id + ".json"
+}
+
+func (o *Outbox) put(ctx context.Context, dir string, d *Delivery) error {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return storage.PutBytes(ctx, o.uri(dir, d.ID), b, "application/json")
+}
+
+func (o *Outbox) get(ctx context.Context, dir, id string) (*Delivery, error) {
+	b, err := storage.Get(ctx, o.uri(dir, id))
+	if err != nil {
+		return nil, err
+	}
+	var d Delivery
+	if err := json.Unmarshal(b, &d); err != nil {
+		return nil, fmt.Errorf("webhook: decode delivery %s: %w", id, err)
+	}
+	return &d, nil
+}
+
+// move writes d under to before deleting it from from, so a crash in
+// between leaves a duplicate rather than a lost delivery.
+func (o *Outbox) move(ctx context.Context, d *Delivery, from, to string) error {
+	if err := o.put(ctx, to, d); err != nil {
+		return err
+	}
+	return storage.Delete(ctx, o.uri(from, d.ID))
+}
+
+func (o *Outbox) list(ctx context.Context, dir string) ([]Delivery, error) {
+	objs, err := storage.List(ctx, o.root+"/"+dir+"/")
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Delivery, 0, len(objs))
+	for _, obj := range objs {
+		b, err := storage.Get(ctx, obj.URI)
+		if err != nil {
+			return nil, err
+		}
+		var d Delivery
+		if err := json.Unmarshal(b, &d); err != nil {
+			return nil, fmt.Errorf("webhook: decode %s: %w", obj.URI, err)
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// newDeliveryID returns an ID that sorts by creation time, so storage
+// listings come back oldest first.
+func newDeliveryID(now time.Time) string {
+	var b [8]byte
+	_, _ = crand.Read(b[:])
+	return fmt.Sprintf("%020d-%s", now.UnixNano(), hex.EncodeToString(b[:]))
+}
@@ -0,0 +1,270 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testOutbox returns an Outbox rooted in a file:// directory of its own
+// under t.TempDir, so no state carries over between runs of -count, with a
+// controllable clock and no jitter.
+func testOutbox(t *testing.T, opts ...OutboxOption) (*Outbox, *time.Time) {
+	t.Helper()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	o := NewOutbox("file://"+filepath.ToSlash(filepath.Join(t.TempDir(), "outbox")), &http.Client{Timeout: 5 * time.Second}, opts...)
+	o.now = func() time.Time { return now }
+	o.jitter = func() float64 { return 1 }
+	return o, &now
+}
+
+func TestOutboxDeliversAndRemoves(t *testing.T) {
+	var hits atomic.Int32
+	var key atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		key.Store(r.Header.Get("Idempotency-Key"))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	o, _ := testOutbox(t)
+	id, err := o.Enqueue(ctx, server.URL, nil, testPayload{Message: "a"})
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	pending, err := o.Pending(ctx)
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("Pending = %d, %v; want 1", len(pending), err)
+	}
+	if err := o.ProcessDue(ctx); err != nil {
+		t.Fatalf("ProcessDue: %v", err)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("hits = %d, want 1", hits.Load())
+	}
+	if key.Load() != id {
+		t.Errorf("Idempotency-Key = %v, want delivery ID %s", key.Load(), id)
+	}
+	if pending, _ := o.Pending(ctx); len(pending) != 0 {
+		t.Errorf("pending after success = %d, want 0", len(pending))
+	}
+}
+
+func TestOutboxRetriesWithBackoff(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	o, now := testOutbox(t, WithBackoff(time.Second, time.Minute))
+	if _, err := o.Enqueue(ctx, server.URL, nil, testPayload{}); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = o.ProcessDue(ctx)
+	pending, _ := o.Pending(ctx)
+	if len(pending) != 1 || pending[0].Attempts != 1 || pending[0].LastStatus != 503 {
+		t.Fatalf("after first failure: %+v", pending)
+	}
+	if want := now.Add(time.Second); !pending[0].NextAttempt.Equal(want) {
+		t.Errorf("NextAttempt = %v, want %v", pending[0].NextAttempt, want)
+	}
+
+	// Not yet due: nothing is sent.
+	_ = o.ProcessDue(ctx)
+	if hits.Load() != 1 {
+		t.Fatalf("hits = %d before due, want 1", hits.Load())
+	}
+
+	*now = now.Add(time.Second)
+	_ = o.ProcessDue(ctx)
+	pending, _ = o.Pending(ctx)
+	if want := now.Add(2 * time.Second); len(pending) != 1 || !pending[0].NextAttempt.Equal(want) {
+		t.Fatalf("second backoff: %+v, want next at %v", pending, want)
+	}
+
+	*now = now.Add(2 * time.Second)
+	_ = o.ProcessDue(ctx)
+	if pending, _ := o.Pending(ctx); len(pending) != 0 {
+		t.Errorf("pending after eventual success = %d", len(pending))
+	}
+	if hits.Load() != 3 {
+		t.Errorf("hits = %d, want 3", hits.Load())
+	}
+}
+
+func TestOutboxHonorsRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	o, now := testOutbox(t)
+	_, _ = o.Enqueue(ctx, server.URL, nil, testPayload{})
+	_ = o.ProcessDue(ctx)
+
+	pending, _ := o.Pending(ctx)
+	if len(pending) != 1 {
+		t.Fatalf("pending = %d, want 1", len(pending))
+	}
+	if want := now.Add(2 * time.Minute); !pending[0].NextAttempt.Equal(want) {
+		t.Errorf("NextAttempt = %v, want %v", pending[0].NextAttempt, want)
+	}
+}
+
+func TestOutboxPermanentFailureGoesToDeadLetter(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	o, _ := testOutbox(t)
+	id, _ := o.Enqueue(ctx, server.URL, nil, testPayload{})
+	_ = o.ProcessDue(ctx)
+
+	if pending, _ := o.Pending(ctx); len(pending) != 0 {
+		t.Fatalf("pending = %d, want 0", len(pending))
+	}
+	dead, err := o.DeadLetters(ctx)
+	if err != nil || len(dead) != 1 {
+		t.Fatalf("DeadLetters = %d, %v; want 1", len(dead), err)
+	}
+	if dead[0].ID != id || dead[0].LastStatus != 422 {
+		t.Errorf("dead letter = %+v", dead[0])
+	}
+
+	if err := o.Replay(ctx, id); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if dead, _ := o.DeadLetters(ctx); len(dead) != 0 {
+		t.Errorf("dead letters after replay = %d", len(dead))
+	}
+	_ = o.ProcessDue(ctx)
+	if pending, _ := o.Pending(ctx); len(pending) != 0 {
+		t.Errorf("pending after replayed success = %d", len(pending))
+	}
+}
+
+func TestOutboxExhaustsAttempts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	o, now := testOutbox(t, WithMaxAttempts(2), WithBackoff(time.Second, time.Second))
+	_, _ = o.Enqueue(ctx, server.URL, nil, testPayload{})
+	_ = o.ProcessDue(ctx)
+	*now = now.Add(time.Second)
+	_ = o.ProcessDue(ctx)
+
+	dead, _ := o.DeadLetters(ctx)
+	if len(dead) != 1 || dead[0].Attempts != 2 {
+		t.Fatalf("dead letters = %+v, want one with 2 attempts", dead)
+	}
+}
+
+func TestOutboxSignsEachAttempt(t *testing.T) {
+	signer := NewSigner(SchemeDefault, []byte("secret"))
+	server := httptest.NewServer(NewReceiver(signer, func(context.Context, testPayload) error { return nil }))
+	defer server.Close()
+
+	ctx := context.Background()
+	o, _ := testOutbox(t, WithOutboxSigner(signer))
+	_, _ = o.Enqueue(ctx, server.URL, nil, testPayload{ID: 1})
+	if err := o.ProcessDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if pending, _ := o.Pending(ctx); len(pending) != 0 {
+		t.Errorf("signed delivery was not accepted: %+v", pending)
+	}
+}
+
+func TestOutboxFileBackendSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	root := "file://" + t.TempDir()
+	first := NewOutbox(root, http.DefaultClient)
+	id, err := first.Enqueue(ctx, "http://127.0.0.1:0/unused", nil, testPayload{Message: "persisted"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second := NewOutbox(root, http.DefaultClient)
+	pending, err := second.Pending(ctx)
+	if err != nil || len(pending) != 1 || pending[0].ID != id {
+		t.Fatalf("Pending after restart = %+v, %v", pending, err)
+	}
+}
+
+func TestOutboxHangingEndpointBlocksNothing(t *testing.T) {
+	unblock := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer hanging.Close()
+	defer close(unblock)
+	var hits atomic.Int32
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer fast.Close()
+
+	ctx := context.Background()
+	o, _ := testOutbox(t)
+	if _, err := o.Enqueue(ctx, hanging.URL, nil, testPayload{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Enqueue(ctx, fast.URL, nil, testPayload{}); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- o.ProcessDue(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for hits.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the fast endpoint waited for the hanging one")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// A second pass skips the delivery still in flight, and Replay does
+	// not wait for the pass.
+	if err := o.ProcessDue(ctx); err != nil {
+		t.Errorf("second ProcessDue: %v", err)
+	}
+	if err := o.Replay(ctx, "missing"); err == nil {
+		t.Error("Replay of an unknown ID succeeded")
+	}
+	unblock <- struct{}{}
+	if err := <-done; err != nil {
+		t.Errorf("ProcessDue: %v", err)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("fast endpoint hits = %d, want 1", hits.Load())
+	}
+	if pending, _ := o.Pending(ctx); len(pending) != 0 {
+		t.Errorf("pending = %d, want 0", len(pending))
+	}
+}
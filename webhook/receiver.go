@@ -0,0 +1,169 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/heatxsink/x/exp/http/responses"
+)
+
+const (
+	defaultTolerance    = 5 * time.Minute
+	defaultMaxBodyBytes = 1 << 20
+)
+
+type receiverConfig struct {
+	tolerance    time.Duration
+	maxBodyBytes int64
+	now          func() time.Time
+}
+
+// ReceiverOption configures a Receiver.
+type ReceiverOption func(*receiverConfig)
+
+// WithTolerance sets how far a signed timestamp may drift from the
+// receiver's clock, in either direction, before the delivery is rejected
+// as stale. It also bounds how long signatures are remembered for replay
+// detection. Defaults to five minutes.
+func WithTolerance(d time.Duration) ReceiverOption {
+	return func(c *receiverConfig) { c.tolerance = d }
+}
+
+// WithMaxBodyBytes caps the request body size. Defaults to 1 MiB.
+func WithMaxBodyBytes(n int64) ReceiverOption {
+	return func(c *receiverConfig) { c.maxBodyBytes = n }
+}
+
+// Receiver is an http.Handler that authenticates incoming webhooks with a
+// Signer, rejects stale or replayed deliveries, decodes the JSON body
+// into T and hands it to a callback. It composes like any other handler,
+// e.g. handlers.Recover(handlers.AccessLog(receiver)).
+//
+// Responses: 405 for non-POST, 401 for signature, staleness and replay
+// failures, 400 for an undecodable body, 500 when the callback errors and
+// 204 on success. Only a 204 counts the delivery as seen, so the sender
+// may retry the same signed request after a 400 or 500.
+//
+// SchemeGitHub signs no timestamp, so a delivery is only remembered for
+// the tolerance window after it arrives: a captured request can be
+// replayed once that has passed, and two deliveries with identical bodies
+// within it are taken for a replay.
+type Receiver[T any] struct {
+	signer *Signer
+	handle func(ctx context.Context, payload T) error
+	cfg    receiverConfig
+	seen   *replayCache
+}
+
+// NewReceiver returns a Receiver that verifies with signer and delivers
+// decoded payloads to handle.
+func NewReceiver[T any](signer *Signer, handle func(ctx context.Context, payload T) error, opts ...ReceiverOption) *Receiver[T] {
+	cfg := receiverConfig{
+		tolerance:    defaultTolerance,
+		maxBodyBytes: defaultMaxBodyBytes,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Receiver[T]{
+		signer: signer,
+		handle: handle,
+		cfg:    cfg,
+		seen:   &replayCache{entries: map[string]time.Time{}},
+	}
+}
+
+func (rv *Receiver[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, rv.cfg.maxBodyBytes))
+	if err != nil {
+		responses.BadRequest(w, fmt.Errorf("webhook: read body: %w", err))
+		return
+	}
+	digest, err := rv.verify(r.Header, body)
+	if err != nil {
+		responses.Unauthorized(w, err)
+		return
+	}
+	var payload T
+	if err := json.Unmarshal(body, &payload); err != nil {
+		// Let the sender retry the same signed request.
+		rv.seen.remove(digest)
+		responses.BadRequest(w, fmt.Errorf("webhook: decode body: %w", err))
+		return
+	}
+	if err := rv.handle(r.Context(), payload); err != nil {
+		rv.seen.remove(digest)
+		responses.InternalServerError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// verify authenticates a delivery and claims its digest in the replay
+// cache, so a concurrent copy is rejected while it is handled. The caller
+// removes the digest again if the delivery fails.
+func (rv *Receiver[T]) verify(h http.Header, body []byte) (string, error) {
+	signed, err := rv.signer.Verify(h, body)
+	if err != nil {
+		return "", err
+	}
+	now := rv.cfg.now()
+	expires := now.Add(rv.cfg.tolerance)
+	if !signed.IsZero() {
+		skew := now.Sub(signed)
+		if skew > rv.cfg.tolerance || skew < -rv.cfg.tolerance {
+			return "", fmt.Errorf("%w: signed at %s", ErrStaleTimestamp, signed.UTC().Format(time.RFC3339))
+		}
+		expires = signed.Add(rv.cfg.tolerance)
+	}
+	digest := rv.signer.digest(signed, body)
+	if !rv.seen.add(digest, expires, now) {
+		return "", ErrReplayed
+	}
+	return digest, nil
+}
+
+// replayCache remembers verified deliveries, by Signer.digest, until the
+// point where the timestamp check alone would reject them.
+type replayCache struct {
+	mu        sync.Mutex
+	entries   map[string]time.Time
+	nextPrune time.Time
+}
+
+// add records sig and reports whether it was unseen.
+func (c *replayCache) add(sig string, expires, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if now.After(c.nextPrune) {
+		for k, exp := range c.entries {
+			if now.After(exp) {
+				delete(c.entries, k)
+			}
+		}
+		c.nextPrune = now.Add(time.Minute)
+	}
+	if exp, ok := c.entries[sig]; ok && !now.After(exp) {
+		return false
+	}
+	c.entries[sig] = expires
+	return true
+}
+
+// remove forgets sig.
+func (c *replayCache) remove(sig string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, sig)
+}
@@ -0,0 +1,223 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func signedRequest(t *testing.T, s *Signer, body []byte) *http.Request {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, "/hook", bytes.NewReader(body))
+	name, value := s.Sign(body)
+	r.Header.Set(name, value)
+	return r
+}
+
+func TestReceiverDeliversPayload(t *testing.T) {
+	s := NewSigner(SchemeDefault, []byte("secret"))
+	var got testPayload
+	rv := NewReceiver(s, func(_ context.Context, p testPayload) error {
+		got = p
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	rv.ServeHTTP(w, signedRequest(t, s, []byte(`{"message":"hi","id":7}`)))
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if got.Message != "hi" || got.ID != 7 {
+		t.Errorf("payload = %+v", got)
+	}
+}
+
+func TestReceiverRejectsBadSignature(t *testing.T) {
+	s := NewSigner(SchemeDefault, []byte("secret"))
+	rv := NewReceiver(s, func(context.Context, testPayload) error {
+		t.Error("handler should not run")
+		return nil
+	})
+	r := signedRequest(t, NewSigner(SchemeDefault, []byte("other")), []byte(`{}`))
+
+	w := httptest.NewRecorder()
+	rv.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", w.Code)
+	}
+}
+
+func TestReceiverRejectsStaleTimestamp(t *testing.T) {
+	s := NewSigner(SchemeDefault, []byte("secret"))
+	old := fixedSigner(SchemeDefault, time.Now().Add(-10*time.Minute), []byte("secret"))
+	rv := NewReceiver(s, func(context.Context, testPayload) error { return nil })
+
+	w := httptest.NewRecorder()
+	rv.ServeHTTP(w, signedRequest(t, old, []byte(`{}`)))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", w.Code)
+	}
+	if _, err := rv.verify(signedRequest(t, old, []byte(`{}`)).Header, []byte(`{}`)); !errors.Is(err, ErrStaleTimestamp) {
+		t.Errorf("err = %v, want ErrStaleTimestamp", err)
+	}
+}
+
+func TestReceiverRejectsReplay(t *testing.T) {
+	s := NewSigner(SchemeDefault, []byte("secret"))
+	calls := 0
+	rv := NewReceiver(s, func(context.Context, testPayload) error {
+		calls++
+		return nil
+	})
+	body := []byte(`{"id":1}`)
+	first := signedRequest(t, s, body)
+	replay := httptest.NewRequest(http.MethodPost, "/hook", bytes.NewReader(body))
+	replay.Header = first.Header.Clone()
+
+	w := httptest.NewRecorder()
+	rv.ServeHTTP(w, first)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("first delivery status = %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	rv.ServeHTTP(w, replay)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("replay status = %d, want 401", w.Code)
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+}
+
+func TestReceiverRejectsModifiedReplay(t *testing.T) {
+	rotating := NewSigner(SchemeDefault, []byte("new"), []byte("old"))
+	for _, tt := range []struct {
+		scheme Scheme
+		modify func(string) string
+	}{
+		{SchemeDefault, func(v string) string { return v + ",x" }},
+		{SchemeDefault, func(v string) string { return v + ", " }},
+		{SchemeDefault, func(v string) string { return " " + strings.ReplaceAll(v, ",", " , ") }},
+		{SchemeDefault, func(v string) string {
+			return strings.NewReplacer("T=", "t=", "V1=", "v1=").Replace(strings.ToUpper(v))
+		}},
+		// Dropping the new key's entry still verifies against the old key.
+		{SchemeDefault, func(v string) string {
+			parts := strings.Split(v, ",")
+			return parts[0] + "," + parts[2]
+		}},
+		{SchemeGitHub, func(v string) string { return "sha256=" + strings.ToUpper(strings.TrimPrefix(v, "sha256=")) }},
+	} {
+		s := NewSigner(tt.scheme, rotating.Keys...)
+		calls := 0
+		rv := NewReceiver(s, func(context.Context, testPayload) error {
+			calls++
+			return nil
+		})
+		body := []byte(`{"id":1}`)
+		first := signedRequest(t, s, body)
+		replay := httptest.NewRequest(http.MethodPost, "/hook", bytes.NewReader(body))
+		replay.Header = first.Header.Clone()
+		replay.Header.Set(s.HeaderName(), tt.modify(first.Header.Get(s.HeaderName())))
+
+		w := httptest.NewRecorder()
+		rv.ServeHTTP(w, first)
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("first delivery status = %d", w.Code)
+		}
+		w = httptest.NewRecorder()
+		rv.ServeHTTP(w, replay)
+		if w.Code != http.StatusUnauthorized || calls != 1 {
+			t.Errorf("replay with %q: status = %d, handler ran %d times", replay.Header.Get(s.HeaderName()), w.Code, calls)
+		}
+	}
+}
+
+func TestReceiverAllowsRetryAfterFailure(t *testing.T) {
+	s := NewSigner(SchemeDefault, []byte("secret"))
+	fail := true
+	calls := 0
+	rv := NewReceiver(s, func(context.Context, testPayload) error {
+		calls++
+		if fail {
+			return errors.New("database down")
+		}
+		return nil
+	})
+	first := signedRequest(t, s, []byte(`{"id":1}`))
+	retry := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(`{"id":1}`))
+	retry.Header = first.Header.Clone()
+	replay := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(`{"id":1}`))
+	replay.Header = first.Header.Clone()
+
+	w := httptest.NewRecorder()
+	rv.ServeHTTP(w, first)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("failing delivery status = %d, want 500", w.Code)
+	}
+	fail = false
+	w = httptest.NewRecorder()
+	rv.ServeHTTP(w, retry)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("retry status = %d, want 204", w.Code)
+	}
+	w = httptest.NewRecorder()
+	rv.ServeHTTP(w, replay)
+	if w.Code != http.StatusUnauthorized || calls != 2 {
+		t.Errorf("replay after success: status = %d, handler ran %d times", w.Code, calls)
+	}
+}
+
+func TestReceiverStatusCodes(t *testing.T) {
+	s := NewSigner(SchemeGitHub, []byte("secret"))
+	rv := NewReceiver(s, func(_ context.Context, p testPayload) error {
+		if p.ID < 0 {
+			return errors.New("negative id")
+		}
+		return nil
+	}, WithMaxBodyBytes(64))
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{"method", httptest.NewRequest(http.MethodGet, "/hook", nil), http.StatusMethodNotAllowed},
+		{"undecodable", signedRequest(t, s, []byte(`not json`)), http.StatusBadRequest},
+		{"too large", signedRequest(t, s, bytes.Repeat([]byte("a"), 128)), http.StatusBadRequest},
+		{"handler error", signedRequest(t, s, []byte(`{"id":-1}`)), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		rv.ServeHTTP(w, tt.req)
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestSendSignedWithContext(t *testing.T) {
+	s := NewSigner(SchemeDefault, []byte("secret"))
+	var got testPayload
+	rv := NewReceiver(s, func(_ context.Context, p testPayload) error {
+		got = p
+		return nil
+	})
+	server := httptest.NewServer(rv)
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	payload := testPayload{Message: "signed", ID: 42}
+	if err := SendSignedWithContext(context.Background(), client, s, server.URL, nil, payload); err != nil {
+		t.Fatalf("SendSignedWithContext: %v", err)
+	}
+	if got != payload {
+		t.Errorf("received %+v, want %+v", got, payload)
+	}
+}
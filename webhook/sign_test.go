@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fixedSigner(scheme Scheme, at time.Time, keys ...[]byte) *Signer {
+	s := NewSigner(scheme, keys...)
+	s.now = func() time.Time { return at }
+	return s
+}
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	body := []byte(`{"message":"hello"}`)
+	for _, scheme := range []Scheme{SchemeDefault, SchemeStripe, SchemeGitHub} {
+		s := fixedSigner(scheme, at, []byte("secret"))
+		name, value := s.Sign(body)
+		h := http.Header{}
+		h.Set(name, value)
+		ts, err := s.Verify(h, body)
+		if err != nil {
+			t.Fatalf("scheme %d: Verify: %v", scheme, err)
+		}
+		if scheme == SchemeGitHub {
+			if !ts.IsZero() {
+				t.Errorf("GitHub scheme returned timestamp %v, want zero", ts)
+			}
+			continue
+		}
+		if !ts.Equal(at) {
+			t.Errorf("scheme %d: timestamp = %v, want %v", scheme, ts, at)
+		}
+	}
+}
+
+func TestSignHeaderLayout(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	body := []byte("{}")
+
+	name, value := fixedSigner(SchemeDefault, at, []byte("k")).Sign(body)
+	if name != HeaderSignature || !strings.HasPrefix(value, "t=1700000000,v1=") {
+		t.Errorf("default: %s: %s", name, value)
+	}
+	name, _ = fixedSigner(SchemeStripe, at, []byte("k")).Sign(body)
+	if name != HeaderStripeSignature {
+		t.Errorf("stripe header = %s", name)
+	}
+	name, value = fixedSigner(SchemeGitHub, at, []byte("k")).Sign(body)
+	if name != HeaderGitHubSignature || !strings.HasPrefix(value, "sha256=") {
+		t.Errorf("github: %s: %s", name, value)
+	}
+}
+
+func TestVerifyRejectsTamperedBody(t *testing.T) {
+	s := NewSigner(SchemeDefault, []byte("secret"))
+	name, value := s.Sign([]byte(`{"amount":1}`))
+	h := http.Header{}
+	h.Set(name, value)
+	if _, err := s.Verify(h, []byte(`{"amount":100}`)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("err = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestVerifyRejectsWrongKey(t *testing.T) {
+	sender := NewSigner(SchemeGitHub, []byte("one"))
+	receiver := NewSigner(SchemeGitHub, []byte("two"))
+	name, value := sender.Sign([]byte("x"))
+	h := http.Header{}
+	h.Set(name, value)
+	if _, err := receiver.Verify(h, []byte("x")); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("err = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestVerifyMissingHeader(t *testing.T) {
+	s := NewSigner(SchemeDefault, []byte("secret"))
+	if _, err := s.Verify(http.Header{}, nil); !errors.Is(err, ErrMissingSignature) {
+		t.Errorf("err = %v, want ErrMissingSignature", err)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	body := []byte("payload")
+
+	// Sender mid-rotation signs with both keys; a receiver that only knows
+	// the old key still verifies.
+	sender := NewSigner(SchemeDefault, []byte("new"), []byte("old"))
+	name, value := sender.Sign(body)
+	if strings.Count(value, "v1=") != 2 {
+		t.Fatalf("expected two v1 entries, got %s", value)
+	}
+	h := http.Header{}
+	h.Set(name, value)
+	if _, err := NewSigner(SchemeDefault, []byte("old")).Verify(h, body); err != nil {
+		t.Errorf("old-key receiver: %v", err)
+	}
+
+	// Receiver mid-rotation accepts a sender that only has the new key.
+	name, value = NewSigner(SchemeGitHub, []byte("new")).Sign(body)
+	h = http.Header{}
+	h.Set(name, value)
+	if _, err := NewSigner(SchemeGitHub, []byte("old"), []byte("new")).Verify(h, body); err != nil {
+		t.Errorf("two-key receiver: %v", err)
+	}
+}
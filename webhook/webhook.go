@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"net/http"
 	"time"
@@ -26,14 +25,7 @@ func SendJSONWithClient(client *http.Client, url string, data interface{}) error
 	if err != nil {
 		return err
 	}
-	switch statusCode {
-	case 200:
-		return nil
-	case 204:
-		return nil
-	default:
-		return fmt.Errorf("HTTP status code: %d HTTP body: %s", statusCode, string(content))
-	}
+	return statusError(statusCode, nil, content)
 }
 
 func post(client *http.Client, url string, payload []byte) (int, []byte, error) {
@@ -55,19 +47,6 @@ func post(client *http.Client, url string, payload []byte) (int, []byte, error)
 	return response.StatusCode, content, nil
 }
 
-func postWithContext(ctx context.Context, client *http.Client, url string, headers map[string]string, payload []byte) (*http.Response, error) {
-	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(payload))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create new request: %w", err)
-	}
-	request.Header.Set("Content-Type", "application/json")
-	request.Header.Set("Accept", "application/json")
-	for k, v := range headers {
-		request.Header.Add(k, v)
-	}
-	return client.Do(request)
-}
-
 func SendWithContextAndRetry(ctx context.Context, retries int, delay time.Duration, client *http.Client, url string, headers map[string]string, data interface{}) error {
 	capHint := retries
 	if capHint < 0 {
@@ -101,26 +80,15 @@ func SendWithContextAndRetry(ctx context.Context, retries int, delay time.Durati
 	return errors.Join(errs...)
 }
 
+// SendWithContext posts data as JSON to url with headers added. Any 2xx
+// status is success; anything else is returned as an *HTTPError. An Event
+// is sent as a structured mode CloudEvent.
 func SendWithContext(ctx context.Context, client *http.Client, url string, headers map[string]string, data interface{}) error {
-	b, err := json.Marshal(data)
-	if err != nil {
-		return err
-	}
-	response, err := postWithContext(ctx, client, url, headers, b)
-	if err != nil {
-		return err
-	}
-	defer response.Body.Close()
-	content, err := io.ReadAll(response.Body)
-	if err != nil {
-		return err
-	}
-	switch response.StatusCode {
-	case 200:
-		return nil
-	case 204:
-		return nil
-	default:
-		return fmt.Errorf("HTTP status code: %d HTTP body: %s", response.StatusCode, string(content))
-	}
+	return Send(ctx, client, url, data, WithHeaders(headers))
+}
+
+// SendSignedWithContext is SendWithContext with the JSON body signed by
+// signer. The signature header is added alongside headers.
+func SendSignedWithContext(ctx context.Context, client *http.Client, signer *Signer, url string, headers map[string]string, data interface{}) error {
+	return Send(ctx, client, url, data, WithHeaders(headers), WithSigner(signer))
 }
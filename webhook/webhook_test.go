@@ -294,26 +294,6 @@ func TestPostWithInvalidURL(t *testing.T) {
 	}
 }
 
-func TestPostWithContextInvalidURL(t *testing.T) {
-	ctx := context.Background()
-	client := &http.Client{}
-
-	response, err := postWithContext(ctx, client, "invalid-url", nil, []byte("test"))
-
-	if err == nil {
-		t.Error("Expected error for invalid URL, got nil")
-	}
-
-	if response != nil {
-		_ = response.Body.Close()
-		t.Errorf("Expected nil response, got %v", response)
-	}
-
-	if !strings.Contains(err.Error(), "unsupported protocol scheme") {
-		t.Errorf("Expected protocol scheme error, got: %v", err)
-	}
-}
-
 func TestSendJSONWithMalformedResponse(t *testing.T) {
 	payload := testPayload{Message: "malformed response test", ID: 107}
 